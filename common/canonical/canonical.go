@@ -0,0 +1,129 @@
+package canonical
+
+import (
+	"fmt"
+	netUrl "net/url"
+	"strings"
+)
+
+// 광고, 유입 경로 추적용으로 붙는 query parameter
+var trackingParams = map[string]bool{
+	"fbclid":               true,
+	"gclid":                true,
+	"gclsrc":               true,
+	"dclid":                true,
+	"msclkid":              true,
+	"yclid":                true,
+	"igshid":               true,
+	"mc_cid":               true,
+	"mc_eid":               true,
+	"_hsenc":               true,
+	"_hsmi":                true,
+	"mkt_tok":              true,
+	"ref_src":              true,
+	"ref_url":              true,
+	"__twitter_impression": true,
+	"wt_mc":                true,
+	"_ga":                  true,
+	"vero_id":              true,
+	"oly_anon_id":          true,
+	"oly_enc_id":           true,
+	"rb_clickid":           true,
+}
+
+var trackingParamPrefixes = []string{"utm_", "pk_", "hmb_"}
+
+// AMP 페이지임을 나타내기만 하는 query parameter
+var ampParams = map[string]string{
+	"amp":        "",
+	"outputType": "amp",
+}
+
+// Normalize 는 AMP cache, 추적용 query parameter 처럼 같은 문서를 가리키는 url 들의 차이를 지운다.
+func Normalize(rawURL string) (string, error) {
+	u, err := netUrl.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("not an absolute url: %s", rawURL)
+	}
+
+	if unwrapped, ok := unwrapAMPCache(u); ok {
+		u = unwrapped
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+
+	query := u.Query()
+	for key, values := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		} else if ampValue, ok := ampParams[key]; ok && (len(values) == 0 || ampValue == "" || values[0] == ampValue) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+
+	return u.String(), nil
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if trackingParams[key] {
+		return true
+	}
+	for _, prefix := range trackingParamPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrapAMPCache 는 `https://www.google.com/amp/s/example.com/a`, `https://example-com.cdn.ampproject.org/c/s/example.com/a`
+// 형태의 AMP cache url 에서 원래 url 을 꺼낸다.
+func unwrapAMPCache(u *netUrl.URL) (*netUrl.URL, bool) {
+	host := strings.ToLower(u.Hostname())
+
+	var rest string
+	switch {
+	case (host == "google.com" || strings.HasSuffix(host, ".google.com")) && strings.HasPrefix(u.Path, "/amp/"):
+		rest = strings.TrimPrefix(u.Path, "/amp/")
+	case strings.HasSuffix(host, ".cdn.ampproject.org"):
+		// `/c/`, `/v/`, `/i/` 다음에 오는 부분이 원래 url 이다
+		segments := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+		if len(segments) != 2 {
+			return nil, false
+		}
+		rest = segments[1]
+	default:
+		return nil, false
+	}
+
+	scheme := "http"
+	if strings.HasPrefix(rest, "s/") {
+		scheme, rest = "https", strings.TrimPrefix(rest, "s/")
+	}
+	if rest == "" {
+		return nil, false
+	}
+
+	original, err := netUrl.Parse(scheme + "://" + rest)
+	if err != nil || original.Host == "" {
+		return nil, false
+	}
+	original.RawQuery = u.RawQuery
+	return original, true
+}
@@ -0,0 +1,30 @@
+package canonical
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com", "https://example.com/"},
+		{"HTTPS://Example.COM:443/Post?b=2&a=1#section", "https://example.com/Post?a=1&b=2"},
+		{"https://example.com/post?utm_source=twitter&utm_medium=social&fbclid=abc&id=3", "https://example.com/post?id=3"},
+		{"http://example.com:8080/post?", "http://example.com:8080/post"},
+		{"https://example.com/post?amp=1", "https://example.com/post"},
+		{"https://example.com/post?outputType=amp&page=2", "https://example.com/post?page=2"},
+		{"https://www.google.com/amp/s/example.com/2021/post.amp.html", "https://example.com/2021/post.amp.html"},
+		{"https://example-com.cdn.ampproject.org/c/s/example.com/post?utm_campaign=x", "https://example.com/post"},
+	}
+	for _, c := range cases {
+		normalized, err := Normalize(c.url)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, normalized, c.url)
+	}
+
+	_, err := Normalize("/relative/path")
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	opEqual  = ' '
+	opDelete = '-'
+	opInsert = '+'
+)
+
+type edit struct {
+	op   byte
+	text string
+}
+
+// Unified 는 from 에서 to 로의 변경을 줄 단위 unified diff 로 돌려준다. 변경이 없으면 빈 문자열이다.
+func Unified(fromName, toName, from, to string, context int) string {
+	edits := lineEdits(splitLines(from), splitLines(to))
+
+	// 각 edit 앞까지 소비한 from, to 의 줄 수
+	fromPos := make([]int, len(edits)+1)
+	toPos := make([]int, len(edits)+1)
+	for i, e := range edits {
+		fromPos[i+1], toPos[i+1] = fromPos[i], toPos[i]
+		if e.op != opInsert {
+			fromPos[i+1]++
+		}
+		if e.op != opDelete {
+			toPos[i+1]++
+		}
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].op == opEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := maxInt(i-context, 0)
+		end := i
+		for {
+			for end < len(edits) && edits[end].op != opEqual {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].op == opEqual {
+				next++
+			}
+			// 다음 변경과의 사이가 가까우면 한 hunk 로 묶는다
+			if next < len(edits) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = minInt(end+context, len(edits))
+			break
+		}
+
+		if buf.Len() == 0 {
+			buf.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+		}
+		buf.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(fromPos[start], fromPos[end]-fromPos[start]),
+			hunkRange(toPos[start], toPos[end]-toPos[start])))
+		for _, e := range edits[start:end] {
+			buf.WriteByte(e.op)
+			buf.WriteString(e.text)
+			buf.WriteByte('\n')
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineEdits 는 Myers 알고리즘으로 a 를 b 로 바꾸는 가장 짧은 edit 목록을 구한다.
+func lineEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] 는 d 번째 단계를 시작할 때의 v[-d..d]
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int) []edit {
+	var edits []edit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int {
+			return snapshot[k+d]
+		}
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		var prevX int
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{op: opEqual, text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: opInsert, text: b[y-1]})
+			} else {
+				edits = append(edits, edit{op: opDelete, text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
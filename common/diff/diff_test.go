@@ -0,0 +1,22 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nk\nl\n"
+
+	expected := "--- from\n+++ to\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -7,5 +7,5 @@\n g\n h\n i\n-j\n k\n+l\n"
+	require.Equal(t, expected, Unified("from", "to", from, to, 3))
+}
+
+func TestUnifiedEdgeCases(t *testing.T) {
+	require.Equal(t, "", Unified("from", "to", "same\n", "same\n", 3))
+	require.Equal(t, "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+new\n+lines\n", Unified("from", "to", "", "new\nlines", 3))
+	require.Equal(t, "--- from\n+++ to\n@@ -1,1 +0,0 @@\n-gone\n", Unified("from", "to", "gone\n", "", 3))
+}
@@ -0,0 +1,158 @@
+package email
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// 본문을 여러 단계로 감싼 메일이라도 이 깊이까지만 읽는다
+const maxMultipartDepth = 10
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Message 는 article 로 옮길 부분만 읽어낸 메일이다.
+type Message struct {
+	MessageID string
+	From      *mail.Address
+	Subject   string
+	Date      *time.Time
+	HTML      string
+	Text      string
+	// 본문에서 `cid:` 로 참조하는 inline 첨부
+	Inline []*Part
+}
+
+type Part struct {
+	ContentID   string
+	ContentType string
+	Data        []byte
+}
+
+// Parse 는 mime 메일을 읽는다. 본문은 처음 나오는 text/html, text/plain 을 utf-8 로 바꿔 담는다.
+func Parse(data []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read message")
+	}
+
+	message := &Message{
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		Subject:   decodeHeader(msg.Header.Get("Subject")),
+	}
+	if message.MessageID == "" {
+		// Message-ID 가 없으면 같은 메일을 알아볼 수 있도록 내용으로 만든다
+		sum := sha256.Sum256(data)
+		message.MessageID = hex.EncodeToString(sum[:16]) + "@personal-archive"
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	if from, err := parser.Parse(msg.Header.Get("From")); err == nil {
+		message.From = from
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		message.Date = &date
+	}
+
+	if err := message.readPart(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// URL 은 메일을 가리키는 `mid:` url (RFC 2392) 이다.
+func (m *Message) URL() string {
+	return "mid:" + m.MessageID
+}
+
+func (m *Message) readPart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMultipartDepth {
+			return nil
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return errors.Wrap(err, "failed to read multipart")
+			}
+			if err := m.readPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := ioutil.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode %s part", mediaType)
+	}
+
+	disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	isAttachment := disposition == "attachment"
+	switch {
+	case mediaType == "text/html" && !isAttachment && m.HTML == "":
+		m.HTML, err = decodeCharset(params["charset"], data)
+	case mediaType == "text/plain" && !isAttachment && m.Text == "":
+		m.Text, err = decodeCharset(params["charset"], data)
+	case header.Get("Content-Id") != "":
+		m.Inline = append(m.Inline, &Part{
+			ContentID:   strings.Trim(strings.TrimSpace(header.Get("Content-Id")), "<>"),
+			ContentType: mediaType,
+			Data:        data,
+		})
+	}
+	return err
+}
+
+// decodeTransferEncoding 은 base64, quoted-printable 로 감싼 본문을 푼다.
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+func decodeCharset(label string, data []byte) (string, error) {
+	if label == "" || strings.EqualFold(label, "utf-8") || strings.EqualFold(label, "us-ascii") {
+		return string(data), nil
+	}
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(data))
+	if err != nil {
+		// 모르는 charset 이면 그대로 둔다
+		return string(data), nil
+	}
+	decoded, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode %s", label)
+	}
+	return string(decoded), nil
+}
+
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
@@ -0,0 +1,56 @@
+package email
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := strings.Join([]string{
+		"From: =?UTF-8?B?64m07Iqk66CI7YSw?= <news@example.com>",
+		"Subject: =?ISO-8859-1?Q?Caf=E9?= weekly",
+		"Message-ID: <abc@example.com>",
+		"Date: Mon, 1 Mar 2021 10:00:00 +0000",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/related; boundary="rel"`,
+		"",
+		"--rel",
+		`Content-Type: multipart/alternative; boundary="alt"`,
+		"",
+		"--alt",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"hello",
+		"--alt",
+		"Content-Type: text/html; charset=iso-8859-1",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		`<p>Caf=E9</p><img src=3D"cid:logo@example.com">`,
+		"--alt--",
+		"--rel",
+		"Content-Type: image/png",
+		"Content-Transfer-Encoding: base64",
+		"Content-ID: <logo@example.com>",
+		"",
+		"iVBORw0K",
+		"GgoAAAAN",
+		"--rel--",
+		"",
+	}, "\n")
+
+	message, err := Parse([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, "abc@example.com", message.MessageID)
+	require.Equal(t, "mid:abc@example.com", message.URL())
+	require.Equal(t, "뉴스레터", message.From.Name)
+	require.Equal(t, "news@example.com", message.From.Address)
+	require.Equal(t, "Café weekly", message.Subject)
+	require.Equal(t, int64(1614592800), message.Date.Unix())
+	require.Equal(t, "hello", message.Text)
+	require.Equal(t, `<p>Café</p><img src="cid:logo@example.com">`, message.HTML)
+	require.Len(t, message.Inline, 1)
+	require.Equal(t, "logo@example.com", message.Inline[0].ContentID)
+	require.Equal(t, "image/png", message.Inline[0].ContentType)
+	require.Equal(t, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), message.Inline[0].Data)
+}
@@ -1,6 +1,9 @@
 package common
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 func getEnv() string {
 	return os.Getenv("ENV")
@@ -9,3 +12,75 @@ func getEnv() string {
 func IsLocal() bool {
 	return getEnv() == "local"
 }
+
+func DataDir() string {
+	if IsLocal() {
+		return "./test_data"
+	}
+	return "/data"
+}
+
+// SnapshotFormat 은 원본 페이지 snapshot 의 저장 형식으로, `html` (기본값) 또는 `warc` 이다.
+func SnapshotFormat() string {
+	if format := os.Getenv("SNAPSHOT_FORMAT"); format != "" {
+		return format
+	}
+	return "html"
+}
+
+// TweetSyndicationURL 은 tweet 을 조회할 syndication 서버의 base url 이다.
+func TweetSyndicationURL() string {
+	if url := os.Getenv("TWEET_SYNDICATION_URL"); url != "" {
+		return url
+	}
+	return "https://cdn.syndication.twimg.com"
+}
+
+// TweetOEmbedURL 은 syndication 으로 조회하지 못했을 때 사용할 oEmbed 서버의 base url 이다.
+func TweetOEmbedURL() string {
+	if url := os.Getenv("TWEET_OEMBED_URL"); url != "" {
+		return url
+	}
+	return "https://publish.twitter.com"
+}
+
+// SMTPAddr 는 뉴스레터를 받을 SMTP 서버의 listen 주소 (e.g. `:2525`) 로, 비어있으면 SMTP 서버를 띄우지 않는다.
+func SMTPAddr() string {
+	return os.Getenv("SMTP_ADDR")
+}
+
+// SMTPRecipients 는 SMTP 서버가 받을 메일 주소들로, SMTP_RECIPIENTS 에 콤마로 구분해 적는다.
+func SMTPRecipients() []string {
+	return listFromEnv("SMTP_RECIPIENTS")
+}
+
+// SMTPAllowedSenders 는 메일을 받을 발신자의 주소 혹은 도메인으로, 비어있으면 SMTP 서버를 띄우지 않는다.
+func SMTPAllowedSenders() []string {
+	return listFromEnv("SMTP_ALLOWED_SENDERS")
+}
+
+// PocketTagPrefix 는 pocket 의 tag 를 가져올 때 앞에 붙일 문자열 (e.g. `pocket/`) 이다.
+func PocketTagPrefix() string {
+	return os.Getenv("POCKET_TAG_PREFIX")
+}
+
+// PocketTagMapping 은 POCKET_TAG_MAPPING 의 `from=to` 목록으로, to 가 비어있으면 그 tag 는 가져오지 않는다.
+func PocketTagMapping() map[string]string {
+	mapping := map[string]string{}
+	for _, value := range listFromEnv("POCKET_TAG_MAPPING") {
+		if i := strings.Index(value, "="); i > 0 {
+			mapping[strings.TrimSpace(value[:i])] = strings.TrimSpace(value[i+1:])
+		}
+	}
+	return mapping
+}
+
+func listFromEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
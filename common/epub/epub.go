@@ -0,0 +1,266 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+	"io/ioutil"
+	"mime"
+	netUrl "net/url"
+	"path"
+	"strings"
+)
+
+const containerPath = "META-INF/container.xml"
+
+// Book 은 epub 파일 하나로, 본문은 spine 순서의 chapter 들이다.
+type Book struct {
+	Title    string
+	Author   string
+	Language string
+	Chapters []*Chapter
+
+	files map[string]*zip.File
+}
+
+type Chapter struct {
+	// zip 안에서의 경로
+	Path  string
+	Title string
+	// chapter 의 xhtml 문서 전체
+	HTML string
+}
+
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type packageDocument struct {
+	Metadata struct {
+		Titles    []string `xml:"title"`
+		Creators  []string `xml:"creator"`
+		Languages []string `xml:"language"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+type ncxNavPoint struct {
+	Label    string        `xml:"navLabel>text"`
+	Content  ncxContent    `xml:"content"`
+	Children []ncxNavPoint `xml:"navPoint"`
+}
+
+type ncxContent struct {
+	Src string `xml:"src,attr"`
+}
+
+type ncxDocument struct {
+	NavPoints []ncxNavPoint `xml:"navMap>navPoint"`
+}
+
+// IsEPUB 은 data 가 epub 파일인지, 즉 zip 의 첫 파일이 `mimetype` 이고 그 내용이 application/epub+zip 인지 확인한다.
+func IsEPUB(data []byte) bool {
+	return len(data) > 58 && bytes.HasPrefix(data, []byte("PK\x03\x04")) &&
+		string(data[30:38]) == "mimetype" && string(data[38:58]) == "application/epub+zip"
+}
+
+// Parse 는 epub 파일을 읽는다. chapter 제목은 목차(nav 혹은 ncx)에서, 없으면 문서의 첫 heading 이나 title 에서 가져온다.
+func Parse(data []byte) (*Book, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open zip")
+	}
+
+	book := &Book{files: map[string]*zip.File{}}
+	for _, file := range archive.File {
+		book.files[file.Name] = file
+	}
+
+	var c container
+	if err := book.readXML(containerPath, &c); err != nil {
+		return nil, err
+	} else if len(c.Rootfiles) == 0 {
+		return nil, errors.New("no rootfile in container.xml")
+	}
+
+	opfPath := c.Rootfiles[0].FullPath
+	var pkg packageDocument
+	if err := book.readXML(opfPath, &pkg); err != nil {
+		return nil, err
+	}
+	if len(pkg.Metadata.Titles) > 0 {
+		book.Title = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+	if len(pkg.Metadata.Creators) > 0 {
+		book.Author = strings.TrimSpace(pkg.Metadata.Creators[0])
+	}
+	if len(pkg.Metadata.Languages) > 0 {
+		book.Language = strings.TrimSpace(pkg.Metadata.Languages[0])
+	}
+
+	base := path.Dir(opfPath)
+	hrefs := map[string]string{}
+	var navPath, ncxPath string
+	for _, item := range pkg.Manifest {
+		href := Resolve(base, item.Href)
+		hrefs[item.ID] = href
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			navPath = href
+		}
+		if item.ID == pkg.Spine.Toc || item.MediaType == "application/x-dtbncx+xml" {
+			ncxPath = href
+		}
+	}
+
+	titles := map[string]string{}
+	if navPath != "" {
+		book.readNavTitles(navPath, titles)
+	}
+	if len(titles) == 0 && ncxPath != "" {
+		book.readNCXTitles(ncxPath, titles)
+	}
+
+	for _, ref := range pkg.Spine.ItemRefs {
+		href, ok := hrefs[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		b, err := book.Read(href)
+		if err != nil {
+			return nil, err
+		}
+
+		chapter := &Chapter{Path: href, Title: titles[href], HTML: string(b)}
+		if chapter.Title == "" {
+			chapter.Title = titleOfDocument(b)
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+
+	if len(book.Chapters) == 0 {
+		return nil, errors.New("no chapter in epub")
+	}
+	return book, nil
+}
+
+// Read 는 zip 안의 name 파일을 읽는다.
+func (b *Book) Read(name string) ([]byte, error) {
+	file, ok := b.files[name]
+	if !ok {
+		return nil, errors.Errorf("%s not found in epub", name)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", name)
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", name)
+	}
+	return data, nil
+}
+
+// ContentTypeOf 는 확장자로 zip 안의 파일의 Content-Type 을 짐작한다.
+func ContentTypeOf(name string) string {
+	if contentType := mime.TypeByExtension(strings.ToLower(path.Ext(name))); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// Resolve 는 base 폴더의 문서가 참조한 상대 경로 href 를 zip 안의 경로로 바꾼다. 외부 url 이면 빈 문자열이다.
+func Resolve(base, href string) string {
+	u, err := netUrl.Parse(href)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+	return strings.TrimPrefix(path.Join(base, u.Path), "./")
+}
+
+func (b *Book) readXML(name string, v interface{}) error {
+	data, err := b.Read(name)
+	if err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(v); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", name)
+	}
+	return nil
+}
+
+// readNavTitles 는 epub3 목차 문서의 링크 이름을 chapter 경로별로 모은다.
+func (b *Book) readNavTitles(navPath string, titles map[string]string) {
+	data, err := b.Read(navPath)
+	if err != nil {
+		return
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+
+	toc := doc.Find("nav[epub\\:type='toc'], nav#toc").First()
+	if toc.Length() == 0 {
+		toc = doc.Find("nav").First()
+	}
+	toc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href := Resolve(path.Dir(navPath), s.AttrOr("href", ""))
+		if _, ok := titles[href]; !ok && href != "" {
+			titles[href] = strings.Join(strings.Fields(s.Text()), " ")
+		}
+	})
+}
+
+// readNCXTitles 는 epub2 목차(ncx) 의 항목 이름을 chapter 경로별로 모은다.
+func (b *Book) readNCXTitles(ncxPath string, titles map[string]string) {
+	var ncx ncxDocument
+	if err := b.readXML(ncxPath, &ncx); err != nil {
+		return
+	}
+
+	var walk func(points []ncxNavPoint)
+	walk = func(points []ncxNavPoint) {
+		for _, point := range points {
+			href := Resolve(path.Dir(ncxPath), point.Content.Src)
+			if _, ok := titles[href]; !ok && href != "" {
+				titles[href] = strings.Join(strings.Fields(point.Label), " ")
+			}
+			walk(point.Children)
+		}
+	}
+	walk(ncx.NavPoints)
+}
+
+func titleOfDocument(data []byte) string {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	for _, selector := range []string{"h1", "h2", "h3", "title"} {
+		if title := strings.Join(strings.Fields(doc.Find(selector).First().Text()), " "); title != "" {
+			return title
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newEPUB(t *testing.T, files [][2]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	require.NoError(t, err)
+	_, _ = f.Write([]byte("application/epub+zip"))
+	for _, file := range files {
+		f, err := w.Create(file[0])
+		require.NoError(t, err)
+		_, _ = f.Write([]byte(file[1]))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	data := newEPUB(t, [][2]string{
+		{"META-INF/container.xml", `<?xml version="1.0"?><container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container"><rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`},
+		{"OEBPS/content.opf", `<?xml version="1.0"?><package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/" version="3.0">
+<metadata><dc:title>Go 책</dc:title><dc:creator>Gopher</dc:creator><dc:language>ko</dc:language></metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="cover" href="text/cover.xhtml" media-type="application/xhtml+xml"/>
+<item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+<item id="c2" href="text/c2.xhtml" media-type="application/xhtml+xml"/>
+</manifest>
+<spine><itemref idref="cover" linear="no"/><itemref idref="c1"/><itemref idref="c2"/></spine>
+</package>`},
+		{"OEBPS/nav.xhtml", `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol><li><a href="text/chapter%201.xhtml">  1장.
+ 시작 </a></li></ol></nav></body></html>`},
+		{"OEBPS/text/cover.xhtml", `<html><body><img src="../images/cover.png"/></body></html>`},
+		{"OEBPS/text/chapter 1.xhtml", `<html><body><h1>Chapter 1</h1><p>hello</p></body></html>`},
+		{"OEBPS/text/c2.xhtml", `<html><head><title>두번째</title></head><body><p>world</p></body></html>`},
+	})
+	require.True(t, IsEPUB(data))
+
+	book, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, "Go 책", book.Title)
+	require.Equal(t, "Gopher", book.Author)
+	require.Equal(t, "ko", book.Language)
+
+	// linear="no" 인 표지는 건너뛰고, 제목은 목차, 문서의 title 순으로 찾는다
+	require.Len(t, book.Chapters, 2)
+	require.Equal(t, "OEBPS/text/chapter 1.xhtml", book.Chapters[0].Path)
+	require.Equal(t, "1장. 시작", book.Chapters[0].Title)
+	require.Contains(t, book.Chapters[0].HTML, "<p>hello</p>")
+	require.Equal(t, "두번째", book.Chapters[1].Title)
+
+	require.Equal(t, "OEBPS/images/cover.png", Resolve("OEBPS/text", "../images/cover.png"))
+	require.Equal(t, "", Resolve("OEBPS/text", "https://example.com/a.png"))
+
+	require.False(t, IsEPUB([]byte("PK\x03\x04 not an epub")))
+}
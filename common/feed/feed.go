@@ -0,0 +1,178 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Feed struct {
+	Title string
+	Link  string
+	Items []*Item
+}
+
+type Item struct {
+	GUID      string
+	Link      string
+	Title     string
+	Published *time.Time
+}
+
+// Parse 는 RSS 2.0, RSS 1.0 (RDF), Atom 피드를 읽는다. 발행 시각이 모두 있으면 최신순으로 정렬한다.
+func Parse(data []byte) (*Feed, error) {
+	var doc document
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "failed to decode feed")
+	}
+
+	var feed *Feed
+	switch doc.XMLName.Local {
+	case "rss":
+		feed = doc.Channel.toFeed(doc.Channel.Items)
+	case "RDF":
+		feed = doc.Channel.toFeed(doc.Items)
+	case "feed":
+		feed = doc.toAtomFeed()
+	default:
+		return nil, fmt.Errorf("unsupported feed type: %s", doc.XMLName.Local)
+	}
+
+	sortByPublished(feed.Items)
+	return feed, nil
+}
+
+type document struct {
+	XMLName xml.Name
+	// rss, rdf
+	Channel rssChannel `xml:"channel"`
+	Items   []rssItem  `xml:"item"`
+	// atom
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssChannel struct {
+	Title string `xml:"title"`
+	// <atom:link rel="self"> 처럼 내용이 없는 link 가 섞여 있을 수 있다
+	Links []string  `xml:"link"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	GUID    string `xml:"guid"`
+	About   string `xml:"about,attr"`
+	Link    string `xml:"link"`
+	Title   string `xml:"title"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"date"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+func (c rssChannel) toFeed(rssItems []rssItem) *Feed {
+	feed := &Feed{
+		Title: strings.TrimSpace(c.Title),
+		Link:  firstNonEmpty(c.Links...),
+	}
+	for _, rssItem := range rssItems {
+		item := &Item{
+			GUID:      firstNonEmpty(rssItem.GUID, rssItem.About, rssItem.Link),
+			Link:      strings.TrimSpace(rssItem.Link),
+			Title:     strings.TrimSpace(rssItem.Title),
+			Published: parseTime(firstNonEmpty(rssItem.PubDate, rssItem.Date)),
+		}
+		if item.Link == "" && strings.HasPrefix(item.GUID, "http") {
+			item.Link = item.GUID
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+func (d document) toAtomFeed() *Feed {
+	feed := &Feed{
+		Title: strings.TrimSpace(d.Title),
+		Link:  alternateLink(d.Links),
+	}
+	for _, entry := range d.Entries {
+		link := alternateLink(entry.Links)
+		feed.Items = append(feed.Items, &Item{
+			GUID:      firstNonEmpty(entry.ID, link),
+			Link:      link,
+			Title:     strings.TrimSpace(entry.Title),
+			Published: parseTime(firstNonEmpty(entry.Published, entry.Updated)),
+		})
+	}
+	return feed
+}
+
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseTime(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if tm, err := time.Parse(layout, value); err == nil {
+			return &tm
+		}
+	}
+	return nil
+}
+
+func sortByPublished(items []*Item) {
+	for _, item := range items {
+		if item.Published == nil {
+			return
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.After(*items[j].Published)
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
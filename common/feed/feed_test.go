@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseRSS(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+  <title>Engineering Blog</title>
+  <link>https://blog.example.com</link>
+  <atom:link href="https://blog.example.com/rss" rel="self"/>
+  <item>
+    <title>Older</title>
+    <link>https://blog.example.com/older</link>
+    <guid isPermaLink="false">older-1</guid>
+    <pubDate>Mon, 04 Jan 2021 10:00:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>Newer</title>
+    <link>https://blog.example.com/newer</link>
+    <pubDate>Tue, 05 Jan 2021 10:00:00 +0000</pubDate>
+  </item>
+</channel></rss>`
+
+	feed, err := Parse([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, "Engineering Blog", feed.Title)
+	require.Equal(t, "https://blog.example.com", feed.Link)
+	require.Len(t, feed.Items, 2)
+	require.Equal(t, "Newer", feed.Items[0].Title)
+	require.Equal(t, "https://blog.example.com/newer", feed.Items[0].GUID)
+	require.Equal(t, "older-1", feed.Items[1].GUID)
+	require.Equal(t, "https://blog.example.com/older", feed.Items[1].Link)
+}
+
+func TestParseAtom(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Blog</title>
+  <link href="https://atom.example.com/feed.xml" rel="self"/>
+  <link href="https://atom.example.com/"/>
+  <entry>
+    <title>First post</title>
+    <link rel="alternate" href="https://atom.example.com/first"/>
+    <id>tag:atom.example.com,2021:first</id>
+    <updated>2021-01-02T03:04:05Z</updated>
+  </entry>
+</feed>`
+
+	feed, err := Parse([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, "Atom Blog", feed.Title)
+	require.Equal(t, "https://atom.example.com/", feed.Link)
+	require.Len(t, feed.Items, 1)
+	require.Equal(t, "tag:atom.example.com,2021:first", feed.Items[0].GUID)
+	require.Equal(t, "https://atom.example.com/first", feed.Items[0].Link)
+	require.Equal(t, 2021, feed.Items[0].Published.Year())
+}
+
+func TestParseUnsupported(t *testing.T) {
+	_, err := Parse([]byte(`<html><body></body></html>`))
+	require.EqualError(t, err, "unsupported feed type: html")
+}
@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultUserAgent    = "Mozilla/5.0 (compatible; personal-archive; +https://github.com/jaeyo/personal-archive)"
+	defaultMaxBodySize  = 50 * 1024 * 1024
+	defaultRetries      = 2
+	defaultRetryBackoff = time.Second
+)
+
+type Config struct {
+	// 요청 하나 (재시도 각각) 의 timeout
+	Timeout   time.Duration
+	UserAgent string
+	// 응답 body 최대 크기, 0 이하면 제한하지 않는다
+	MaxBodySize int64
+	// 첫 요청 이후 재시도 횟수
+	Retries int
+	// 첫 재시도 전 대기 시간, 재시도마다 두 배가 된다
+	RetryBackoff time.Duration
+}
+
+// ConfigFromEnv 는 환경 변수로부터 설정을 읽는다. proxy 는 HTTP_PROXY, HTTPS_PROXY, NO_PROXY 를 따른다.
+//   - FETCH_TIMEOUT: e.g. `30s`
+//   - FETCH_USER_AGENT
+//   - FETCH_MAX_BODY_SIZE: bytes
+//   - FETCH_RETRIES
+//   - FETCH_RETRY_BACKOFF: e.g. `1s`
+func ConfigFromEnv() Config {
+	return Config{
+		Timeout:      durationFromEnv("FETCH_TIMEOUT", defaultTimeout),
+		UserAgent:    stringFromEnv("FETCH_USER_AGENT", defaultUserAgent),
+		MaxBodySize:  int64(intFromEnv("FETCH_MAX_BODY_SIZE", defaultMaxBodySize)),
+		Retries:      intFromEnv("FETCH_RETRIES", defaultRetries),
+		RetryBackoff: durationFromEnv("FETCH_RETRY_BACKOFF", defaultRetryBackoff),
+	}
+}
+
+func stringFromEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func intFromEnv(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func durationFromEnv(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,196 @@
+package fetch
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// Response 는 body 를 모두 읽어둔 응답이다.
+type Response struct {
+	// redirect 를 따라간 최종 url
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Client 는 외부로 나가는 모든 http 요청이 사용하는 client 로, timeout, User-Agent, proxy, 응답 크기 제한, 재시도를 일괄 적용한다.
+type Client interface {
+	Get(url string) (*Response, error)
+	Head(url string) (*Response, error)
+	Post(url, contentType string, body []byte) (*Response, error)
+	Do(req *http.Request) (*Response, error)
+}
+
+type client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+func NewClient(config Config) Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	return &client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: transport,
+		},
+	}
+}
+
+var GetClient = func() func() Client {
+	var once sync.Once
+	var instance Client
+	return func() Client {
+		once.Do(func() {
+			instance = NewClient(ConfigFromEnv())
+		})
+		return instance
+	}
+}()
+
+func Get(url string) (*Response, error) {
+	return GetClient().Get(url)
+}
+
+func Head(url string) (*Response, error) {
+	return GetClient().Head(url)
+}
+
+func Post(url, contentType string, body []byte) (*Response, error) {
+	return GetClient().Post(url, contentType, body)
+}
+
+func Do(req *http.Request) (*Response, error) {
+	return GetClient().Do(req)
+}
+
+func (c *client) Get(url string) (*Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	return c.Do(req)
+}
+
+func (c *client) Head(url string) (*Response, error) {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	return c.Do(req)
+}
+
+func (c *client) Post(url, contentType string, body []byte) (*Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(req)
+}
+
+// Do 는 요청을 보내고 body 를 읽어 돌려준다. 네트워크 에러, 429, 5xx 응답은 backoff 를 두고 재시도하며,
+// 재시도가 모두 실패하면 마지막 에러 혹은 마지막 응답을 돌려준다.
+func (c *client) Do(req *http.Request) (*Response, error) {
+	if req.Header.Get("User-Agent") == "" && c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	var resp *Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, errors.Wrap(bodyErr, "failed to rewind request body")
+			}
+			req.Body = body
+		}
+
+		resp, err = c.do(req)
+		if attempt >= c.config.Retries || !isRetryable(resp, err) {
+			break
+		}
+		if req.Body != nil && req.GetBody == nil {
+			// 다시 보낼 수 없는 body
+			break
+		}
+
+		time.Sleep(c.backoff(attempt, resp))
+	}
+	return resp, err
+}
+
+func (c *client) do(req *http.Request) (*Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, c.config.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+func (c *client) backoff(attempt int, resp *Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 && seconds <= 60 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return c.config.RetryBackoff * time.Duration(1<<uint(attempt))
+}
+
+func readBody(r io.Reader, maxBodySize int64) ([]byte, error) {
+	if maxBodySize <= 0 {
+		body, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read body")
+		}
+		return body, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r, maxBodySize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read body")
+	} else if int64(len(body)) > maxBodySize {
+		return nil, errors.Wrapf(ErrBodyTooLarge, "larger than %d bytes", maxBodySize)
+	}
+	return body, nil
+}
+
+func isRetryable(resp *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrBodyTooLarge)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// CheckStatus 는 응답 코드가 200 이 아니면 에러를 돌려준다.
+func CheckStatus(resp *Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package fetch
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var attempts int
+	var userAgent, requestBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		userAgent = r.Header.Get("User-Agent")
+		body, _ := ioutil.ReadAll(r.Body)
+		requestBody = string(body)
+
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		Timeout:      time.Second,
+		UserAgent:    "test-agent",
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+	})
+
+	resp, err := client.Post(server.URL, "text/plain", []byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "hello", string(resp.Body))
+	require.Equal(t, 3, attempts)
+	require.Equal(t, "test-agent", userAgent)
+	require.Equal(t, "payload", requestBody)
+
+	// 재시도를 모두 소진하면 마지막 응답을 돌려준다
+	attempts = 0
+	client = NewClient(Config{Timeout: time.Second, Retries: 1, RetryBackoff: time.Millisecond})
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Equal(t, 2, attempts)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Timeout: time.Second, MaxBodySize: 10, Retries: 2, RetryBackoff: time.Millisecond})
+	_, err := client.Get(server.URL)
+	require.True(t, errors.Is(err, ErrBodyTooLarge))
+	require.Equal(t, 1, attempts)
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Timeout: 50 * time.Millisecond})
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+}
@@ -92,6 +92,28 @@ func (c ContextExtended) NotFoundf(format string, a ...interface{}) *echo.HTTPEr
 	return c.NotFound(fmt.Sprintf(format, a...))
 }
 
+func (c ContextExtended) Conflict(message string) *echo.HTTPError {
+	return &echo.HTTPError{
+		Code:    http.StatusConflict,
+		Message: message,
+	}
+}
+
+func (c ContextExtended) Conflictf(format string, a ...interface{}) *echo.HTTPError {
+	return c.Conflict(fmt.Sprintf(format, a...))
+}
+
+func (c ContextExtended) RequestEntityTooLarge(message string) *echo.HTTPError {
+	return &echo.HTTPError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: message,
+	}
+}
+
+func (c ContextExtended) RequestEntityTooLargef(format string, a ...interface{}) *echo.HTTPError {
+	return c.RequestEntityTooLarge(fmt.Sprintf(format, a...))
+}
+
 func Provide(fn func(ContextExtended) error) func(ctx echo.Context) error {
 	return func(ctx echo.Context) error {
 		return fn(ContextExtended{ctx})
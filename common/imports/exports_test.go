@@ -0,0 +1,120 @@
+package imports
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParsePocketExport(t *testing.T) {
+	data := `<!DOCTYPE html><html><head><title>Pocket Export</title></head><body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/a" time_added="1600000000" tags="go,web">A</a></li>
+</ul>
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/b" time_added="1600000001" tags="">B</a></li>
+</ul>
+</body></html>`
+
+	items, err := ParsePocketExport([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, "A", items[0].Title)
+	require.Equal(t, []string{"go", "web"}, items[0].Tags)
+	require.Equal(t, int64(1600000000), items[0].Created.Unix())
+	require.False(t, items[0].Archived)
+	require.Equal(t, "https://example.com/b", items[1].URL)
+	require.True(t, items[1].Archived)
+}
+
+func TestParseInstapaperExport(t *testing.T) {
+	data := "URL,Title,Selection,Folder,Timestamp,Tags\n" +
+		"https://example.com/a,A,,Unread,1600000000,\"[\"\"go\"\"]\"\n" +
+		"https://example.com/b,B,,Archive,1600000001,[]\n" +
+		"https://example.com/c,C,,Starred,1600000002,\n" +
+		"https://example.com/d,D,,Papers,1600000003,\n"
+
+	items, err := ParseInstapaperExport([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+	require.Equal(t, []string{"go"}, items[0].Tags)
+	require.True(t, items[1].Archived)
+	require.True(t, items[2].Favorite)
+	require.Equal(t, []string{"Papers"}, items[3].Tags)
+	require.False(t, items[3].Archived)
+}
+
+func TestParseWallabagExport(t *testing.T) {
+	data := `[{"url": "https://example.com/a", "title": "A", "is_archived": 1, "is_starred": false,
+		"tags": ["go"], "created_at": "2020-01-02T03:04:05+0900", "content": "<p>hello <b>world</b></p><script>x()</script>"}]`
+
+	items, err := ParseWallabagExport([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.True(t, items[0].Archived)
+	require.False(t, items[0].Favorite)
+	require.Equal(t, []string{"go"}, items[0].Tags)
+	require.Equal(t, "hello **world**", items[0].Content)
+	require.True(t, items[0].Created.Equal(time.Date(2020, 1, 1, 18, 4, 5, 0, time.UTC)))
+}
+
+func TestParseRaindropExport(t *testing.T) {
+	data := "id,title,note,excerpt,url,folder,tags,created,cover,highlights,favorite\n" +
+		"1,A,,,https://example.com/a,Dev,\"go, web\",2021-05-01T10:00:00.000Z,,,true\n" +
+		"2,B,,,https://example.com/b,Unsorted,,2021-05-02T10:00:00.000Z,,,false\n"
+
+	items, err := ParseRaindropExport([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, []string{"Dev", "go", "web"}, items[0].Tags)
+	require.True(t, items[0].Favorite)
+	require.Equal(t, int64(1619863200), items[0].Created.Unix())
+	require.Empty(t, items[1].Tags)
+	require.False(t, items[1].Favorite)
+}
+
+func TestParseOmnivoreExport(t *testing.T) {
+	metadata := `[{"slug": "a", "title": "A", "url": "https://example.com/a", "state": "Archived",
+		"labels": ["go", {"name": "web"}], "savedAt": "2023-01-02T03:04:05.000Z"}]`
+
+	// case 1: metadata json
+	items, err := ParseOmnivoreExport([]byte(metadata))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, []string{"go", "web"}, items[0].Tags)
+	require.True(t, items[0].Archived)
+	require.Empty(t, items[0].Content)
+
+	// case 2: zip
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, _ := w.Create("metadata_0_to_0.json")
+	_, _ = f.Write([]byte(metadata))
+	f, _ = w.Create("content/a.md")
+	_, _ = f.Write([]byte("# A\n\nhello\n"))
+	require.NoError(t, w.Close())
+
+	items, err = ParseOmnivoreExport(buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "# A\n\nhello", items[0].Content)
+}
+
+func TestParseReadwiseExport(t *testing.T) {
+	data := "Title,URL,ID,Document tags,Saved date,Reading progress,Location,Seen\n" +
+		"A,https://example.com/a,1,\"['go', 'web']\",2023-05-01 12:34:56+00:00,0.5,archive,True\n" +
+		"B,https://example.com/b,2,[],2023-05-02 12:34:56+00:00,0,shortlist,False\n"
+
+	items, err := ParseReadwiseExport([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, []string{"go", "web"}, items[0].Tags)
+	require.True(t, items[0].Archived)
+	require.Equal(t, int64(1682944496), items[0].Created.Unix())
+	require.Empty(t, items[1].Tags)
+	require.True(t, items[1].Favorite)
+}
@@ -0,0 +1,184 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/jaeyo/personal-archive/common/sanitize"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxTagLength = 36
+
+// Item 은 가져오기 파일의 항목 하나로, 형식마다 채워지는 필드가 다르다.
+type Item struct {
+	URL   string
+	Title string
+	Tags  []string
+	// 원래 서비스에 저장한 시각
+	Created *time.Time
+	// 원래 서비스가 추출해둔 본문 (markdown), 있으면 내려받지 않고 그대로 쓴다
+	Content  string
+	Archived bool
+	Favorite bool
+}
+
+// ParseExport 가 읽을 수 있는 다른 서비스의 내보내기 파일
+const (
+	SourcePocket     = "pocket"
+	SourceInstapaper = "instapaper"
+	SourceWallabag   = "wallabag"
+	SourceRaindrop   = "raindrop"
+	SourceOmnivore   = "omnivore"
+	SourceReadwise   = "readwise"
+)
+
+var exportParsers = map[string]func(data []byte) ([]*Item, error){
+	SourcePocket:     ParsePocketExport,
+	SourceInstapaper: ParseInstapaperExport,
+	SourceWallabag:   ParseWallabagExport,
+	SourceRaindrop:   ParseRaindropExport,
+	SourceOmnivore:   ParseOmnivoreExport,
+	SourceReadwise:   ParseReadwiseExport,
+}
+
+func IsValidSource(source string) bool {
+	_, ok := exportParsers[source]
+	return ok
+}
+
+// ParseExport 는 source 서비스의 내보내기 파일을 읽는다.
+func ParseExport(source string, data []byte) ([]*Item, error) {
+	parse, ok := exportParsers[source]
+	if !ok {
+		return nil, fmt.Errorf("unsupported source: %s", source)
+	}
+	return parse(data)
+}
+
+// splitTags 는 `go, web;news|blog` 처럼 ',', ';', '|' 로 이어진 tag 들을 나눈다.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	}) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// unixTime 은 초 단위 unix time 을 읽는다. 밀리초, 마이크로초 단위로 적힌 값도 받는다.
+func unixTime(value string) *time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	for n > 1e11 {
+		n /= 1000
+	}
+	t := time.Unix(n, 0)
+	return &t
+}
+
+// dedupeTags 는 tag 로 쓸 수 없을 만큼 긴 이름을 자르고 겹치는 tag 를 지운다.
+func dedupeTags(tags []string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = truncateTag(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+func truncateTag(tag string) string {
+	if len(tag) <= maxTagLength {
+		return tag
+	}
+	// 글자 중간에서 자르지 않는다
+	cut := maxTagLength
+	for cut > 0 && !utf8.RuneStart(tag[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(tag[:cut])
+}
+
+// parseTime 은 layouts 중 맞는 형식으로 시각을 읽는다. 읽을 수 없으면 nil 이다.
+func parseTime(value string, layouts ...string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// readCSVRecords 는 header 가 있는 csv 를 읽어, 소문자로 바꾼 컬럼 이름별 값으로 돌려준다.
+func readCSVRecords(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read csv header")
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	var records []map[string]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to read csv")
+		}
+
+		record := map[string]string{}
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// 가져온 본문에 남길 element, 나머지는 벗겨내고 내용만 남긴다
+var contentPolicy = sanitize.NewPolicy().
+	AllowElements(nil, "p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "code",
+		"ul", "ol", "li", "dl", "dt", "dd", "strong", "b", "em", "i", "u", "s", "del", "sub", "sup",
+		"table", "thead", "tbody", "tr", "th", "td", "figure", "figcaption").
+	AllowElements([]string{"href", "title"}, "a").
+	AllowElements([]string{"src", "alt", "title"}, "img")
+
+// htmlToMarkdown 은 다른 서비스가 추출해둔 html 본문을 sanitize 하고 markdown 으로 바꾼다.
+func htmlToMarkdown(content string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", nil
+	}
+	converted, err := markdown.ConvertFromHtml(contentPolicy.Sanitize(content))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to convert html to markdown")
+	}
+	return strings.TrimSpace(converted), nil
+}
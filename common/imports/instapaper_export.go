@@ -0,0 +1,47 @@
+package imports
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ParseInstapaperExport 는 Instapaper 의 csv 내보내기 파일을 읽는다.
+// `URL,Title,Selection,Folder,Timestamp[,Tags]` 컬럼으로, Folder 는 Unread, Archive, Starred 혹은 사용자가 만든 폴더다.
+func ParseInstapaperExport(data []byte) ([]*Item, error) {
+	records, err := readCSVRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	for _, record := range records {
+		if record["url"] == "" {
+			continue
+		}
+
+		item := &Item{
+			URL:     record["url"],
+			Title:   record["title"],
+			Created: unixTime(record["timestamp"]),
+		}
+
+		switch folder := record["folder"]; strings.ToLower(folder) {
+		case "", "unread":
+		case "archive":
+			item.Archived = true
+		case "starred":
+			item.Favorite = true
+		default:
+			item.Tags = append(item.Tags, folder)
+		}
+
+		// 최근 내보내기 파일은 tag 들을 json 배열로 담는다
+		var tags []string
+		if err := json.Unmarshal([]byte(record["tags"]), &tags); err == nil {
+			item.Tags = append(item.Tags, tags...)
+		}
+		item.Tags = dedupeTags(item.Tags)
+		items = append(items, item)
+	}
+	return items, nil
+}
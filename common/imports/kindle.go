@@ -0,0 +1,233 @@
+package imports
+
+import (
+	"bytes"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Book 은 Kindle 에서 읽은 책 하나와 그 책의 highlight 들이다.
+type Book struct {
+	Title      string
+	Author     string
+	Highlights []*Highlight
+}
+
+type Highlight struct {
+	Content string
+	// highlight 가 아니라 직접 적은 메모
+	IsNote   bool
+	Section  string
+	Page     string
+	Location string
+	Created  *time.Time
+}
+
+const kindleClippingSeparator = "=========="
+
+// ToNote 가 paragraph 의 본문과 위치, 시각 사이에 넣는 구분
+const kindleMetaPrefix = "\n\n— "
+
+var (
+	kindlePageRegexp     = regexp.MustCompile(`(?i)\bpage\s+([\w-]+)`)
+	kindleLocationRegexp = regexp.MustCompile(`(?i)\bloc(?:ation|\.)?\s+([\d-]+)`)
+	kindleAuthorRegexp   = regexp.MustCompile(`^(.*)\s+\(([^()]*)\)$`)
+)
+
+// My Clippings.txt 의 `Added on` 뒤에 적힌 시각의 형식들, 기기의 언어 설정에 따라 다르다
+var kindleClippingTimeLayouts = []string{
+	"Monday, January 2, 2006 3:04:05 PM",
+	"Monday, 2 January 2006 15:04:05",
+	"Monday, January 2, 2006, 3:04 PM",
+	"Monday, 2 January 2006, 15:04",
+}
+
+// ParseKindleClippings 는 Kindle 기기의 `My Clippings.txt` 를 읽는다. bookmark 와 겹치는 highlight 는 건너뛴다.
+//
+//	Title (Author)
+//	- Your Highlight on page 12 | Location 180-182 | Added on Monday, March 1, 2021 10:00:00 PM
+//
+//	highlight
+//	==========
+func ParseKindleClippings(data []byte) ([]*Book, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	var books []*Book
+	byTitle := map[string]*Book{}
+	seen := map[string]bool{}
+	for _, clipping := range strings.Split(content, kindleClippingSeparator) {
+		lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(clipping, "\ufeff", "")), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+
+		title, author := splitKindleTitle(strings.TrimSpace(lines[0]))
+		meta := strings.TrimSpace(lines[1])
+		text := strings.TrimSpace(strings.Join(lines[2:], "\n"))
+		if title == "" || text == "" || strings.Contains(strings.ToLower(meta), "bookmark") {
+			continue
+		}
+
+		key := title + "\n" + text
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		book, ok := byTitle[title]
+		if !ok {
+			book = &Book{Title: title, Author: author}
+			byTitle[title] = book
+			books = append(books, book)
+		}
+
+		highlight := &Highlight{
+			Content: text,
+			IsNote:  strings.Contains(strings.ToLower(meta), "note"),
+		}
+		for _, part := range strings.Split(strings.TrimPrefix(meta, "-"), "|") {
+			part = strings.TrimSpace(part)
+			if i := strings.Index(strings.ToLower(part), "added on"); i >= 0 {
+				highlight.Created = parseTime(part[i+len("added on"):], kindleClippingTimeLayouts...)
+				continue
+			}
+			if matches := kindlePageRegexp.FindStringSubmatch(part); matches != nil {
+				highlight.Page = matches[1]
+			}
+			if matches := kindleLocationRegexp.FindStringSubmatch(part); matches != nil {
+				highlight.Location = matches[1]
+			}
+		}
+		book.Highlights = append(book.Highlights, highlight)
+	}
+
+	if len(books) == 0 && !strings.Contains(content, kindleClippingSeparator) {
+		return nil, errors.New("not a kindle clippings file")
+	}
+	return books, nil
+}
+
+// splitKindleTitle 은 `Title (Author)` 를 제목과 저자로 나눈다.
+func splitKindleTitle(line string) (string, string) {
+	if matches := kindleAuthorRegexp.FindStringSubmatch(line); matches != nil {
+		return strings.TrimSpace(matches[1]), strings.TrimSpace(matches[2])
+	}
+	return line, ""
+}
+
+// ParseKindleNotebook 은 Kindle 앱의 notebook 을 html 로 내보낸 파일을 읽는다. 이 파일에는 highlight 한 시각이 없다.
+func ParseKindleNotebook(data []byte) ([]*Book, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse html")
+	}
+
+	book := &Book{
+		Title:  strings.TrimSpace(doc.Find(".bookTitle").First().Text()),
+		Author: strings.TrimSpace(doc.Find(".authors").First().Text()),
+	}
+	if book.Title == "" {
+		return nil, errors.New("not a kindle notebook file")
+	}
+
+	var section string
+	var current *Highlight
+	doc.Find(".sectionHeading, .noteHeading, .noteText").Each(func(_ int, s *goquery.Selection) {
+		switch {
+		case s.HasClass("sectionHeading"):
+			section = ownText(s)
+		case s.HasClass("noteHeading"):
+			heading := strings.TrimSpace(s.Text())
+			current = &Highlight{
+				Section: section,
+				IsNote:  strings.HasPrefix(strings.ToLower(heading), "note"),
+			}
+			if matches := kindlePageRegexp.FindStringSubmatch(heading); matches != nil {
+				current.Page = matches[1]
+			}
+			if matches := kindleLocationRegexp.FindStringSubmatch(heading); matches != nil {
+				current.Location = matches[1]
+			}
+		case current != nil:
+			if current.Content = ownText(s); current.Content != "" {
+				book.Highlights = append(book.Highlights, current)
+			}
+			current = nil
+		}
+	})
+	return []*Book{book}, nil
+}
+
+// ownText 는 s 에 바로 들어있는 글자만 모은다.
+// Kindle 이 내보내는 파일은 noteText 를 닫지 않아 뒤따르는 heading 들이 그 안에 들어가 있기도 하다.
+func ownText(s *goquery.Selection) string {
+	var text strings.Builder
+	s.Contents().Each(func(_ int, c *goquery.Selection) {
+		if c.Nodes[0].Type == html.TextNode {
+			text.WriteString(c.Nodes[0].Data)
+		} else if goquery.NodeName(c) == "br" {
+			text.WriteString("\n")
+		}
+	})
+	return strings.TrimSpace(text.String())
+}
+
+// ToNote 는 책 하나를 highlight 마다 paragraph 가 있는 노트로 바꾼다.
+// paragraph 는 highlight 를 인용하고, 그 아래에 위치와 highlight 한 시각을 적는다.
+func (b *Book) ToNote() *Note {
+	note := &Note{Path: b.Title, Title: b.Title, Tags: []string{"kindle"}}
+	for _, highlight := range b.Highlights {
+		var content strings.Builder
+		if highlight.IsNote {
+			content.WriteString(highlight.Content)
+		} else {
+			content.WriteString("> " + strings.ReplaceAll(highlight.Content, "\n", "\n> "))
+		}
+
+		var meta []string
+		if highlight.Section != "" {
+			meta = append(meta, highlight.Section)
+		}
+		if highlight.Page != "" {
+			meta = append(meta, "Page "+highlight.Page)
+		}
+		if highlight.Location != "" {
+			meta = append(meta, "Location "+highlight.Location)
+		}
+		if highlight.Created != nil {
+			meta = append(meta, highlight.Created.Format("2006-01-02 15:04"))
+		}
+		if len(meta) > 0 {
+			content.WriteString(kindleMetaPrefix + strings.Join(meta, " · "))
+		}
+
+		note.Paragraphs = append(note.Paragraphs, &NoteParagraph{
+			Content: content.String(),
+			Links:   findLinks(highlight.Content),
+			Created: highlight.Created,
+		})
+		if note.Created == nil || (highlight.Created != nil && highlight.Created.Before(*note.Created)) {
+			note.Created = highlight.Created
+		}
+	}
+	return note
+}
+
+// HighlightKey 는 ToNote 가 만든 paragraph 의 본문에서 highlight 의 내용과 위치만 남긴 값으로, 같은 highlight 인지 비교할 때 쓴다.
+// 시각은 빼므로 My Clippings.txt 와 notebook 에서 가져온 같은 highlight 도 같은 값이 된다.
+func HighlightKey(content string) string {
+	body, meta := content, ""
+	if i := strings.LastIndex(content, kindleMetaPrefix); i >= 0 {
+		body, meta = content[:i], content[i+len(kindleMetaPrefix):]
+	}
+
+	location := ""
+	if matched := kindleLocationRegexp.FindStringSubmatch(meta); matched != nil {
+		location = matched[1]
+	}
+	return location + "\n" + strings.TrimSpace(body)
+}
@@ -0,0 +1,69 @@
+package imports
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseKindleClippings(t *testing.T) {
+	data := "\ufeffThe Go Programming Language (Donovan, Alan A. A.; Kernighan, Brian W.)\r\n" +
+		"- Your Highlight on page 12 | Location 180-182 | Added on Monday, March 1, 2021 10:00:00 PM\r\n\r\n" +
+		"Go is an open source programming language.\r\n==========\r\n" +
+		"The Go Programming Language (Donovan, Alan A. A.; Kernighan, Brian W.)\r\n" +
+		"- Your Bookmark on page 13 | Location 190 | Added on Monday, March 1, 2021 10:01:00 PM\r\n\r\n\r\n==========\r\n" +
+		"The Go Programming Language (Donovan, Alan A. A.; Kernighan, Brian W.)\r\n" +
+		"- Your Highlight on page 12 | Location 180-182 | Added on Monday, March 1, 2021 10:00:00 PM\r\n\r\n" +
+		"Go is an open source programming language.\r\n==========\r\n" +
+		"Another Book\r\n" +
+		"- Your Note at location 20 | Added on Tuesday, 2 March 2021 08:00:00\r\n\r\n" +
+		"remember this\r\n==========\r\n"
+
+	books, err := ParseKindleClippings([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, books, 2)
+
+	require.Equal(t, "The Go Programming Language", books[0].Title)
+	require.Equal(t, "Donovan, Alan A. A.; Kernighan, Brian W.", books[0].Author)
+	require.Len(t, books[0].Highlights, 1)
+	require.Equal(t, "12", books[0].Highlights[0].Page)
+	require.Equal(t, "180-182", books[0].Highlights[0].Location)
+	require.Equal(t, time.Date(2021, 3, 1, 22, 0, 0, 0, time.UTC), *books[0].Highlights[0].Created)
+
+	require.Equal(t, "Another Book", books[1].Title)
+	require.True(t, books[1].Highlights[0].IsNote)
+	require.Equal(t, "20", books[1].Highlights[0].Location)
+	require.NotNil(t, books[1].Highlights[0].Created)
+
+	note := books[0].ToNote()
+	require.Equal(t, "The Go Programming Language", note.Title)
+	require.Equal(t, "> Go is an open source programming language.\n\n— Page 12 · Location 180-182 · 2021-03-01 22:00", note.Paragraphs[0].Content)
+	require.Equal(t, books[0].Highlights[0].Created, note.Created)
+
+	_, err = ParseKindleClippings([]byte("hello"))
+	require.Error(t, err)
+}
+
+func TestParseKindleNotebook(t *testing.T) {
+	data := `<html><body><div class="bodyContainer">
+<div class="notebookFor">Notebook for</div>
+<div class="bookTitle">The Go Programming Language</div>
+<div class="authors">Alan A. A. Donovan</div>
+<h2 class="sectionHeading">1. Tutorial</h2>
+<h3 class="noteHeading">Highlight(<span class="highlight_yellow">yellow</span>) - Page 12 · Location 180</h3>
+<div class="noteText">Go is an open source programming language.
+<h3 class="noteHeading">Note - Page 12 · Location 181</h3>
+<div class="noteText">remember this</div>
+</div></body></html>`
+
+	books, err := ParseKindleNotebook([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	require.Equal(t, "Alan A. A. Donovan", books[0].Author)
+	require.Len(t, books[0].Highlights, 2)
+	require.Equal(t, "Go is an open source programming language.", books[0].Highlights[0].Content)
+	require.Equal(t, "1. Tutorial", books[0].Highlights[0].Section)
+	require.Equal(t, "180", books[0].Highlights[0].Location)
+	require.True(t, books[0].Highlights[1].IsNote)
+	require.Equal(t, "remember this", books[0].Highlights[1].Content)
+}
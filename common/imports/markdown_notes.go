@@ -0,0 +1,271 @@
+package imports
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/pkg/errors"
+	netUrl "net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Note 는 가져오기 파일의 노트 하나다.
+type Note struct {
+	// zip 안에서의 경로
+	Path  string
+	Title string
+	Tags  []string
+	// front matter 의 url, source 처럼 노트 전체가 참고한 url
+	URLs       []string
+	Paragraphs []*NoteParagraph
+	Created    *time.Time
+}
+
+type NoteParagraph struct {
+	Content string
+	// 본문에 적힌 url 들
+	Links   []string
+	Created *time.Time
+}
+
+// front matter 에서 노트가 참고한 url 로 읽는 key
+var noteURLKeys = []string{"url", "urls", "source", "link", "links"}
+
+var (
+	logseqPropertyRegexp = regexp.MustCompile(`^([A-Za-z][\w-]*)::\s*(.*)$`)
+	headingRegexp        = regexp.MustCompile(`^#{1,6}\s`)
+	linkRegexp           = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+)
+
+// ParseMarkdownNotes 는 Obsidian, Logseq vault 혹은 markdown 파일을 담은 폴더를 압축한 zip 파일을 읽는다.
+// 파일 하나가 노트 하나가 되며, 제목은 front matter 의 title 이 없으면 파일 이름이다.
+// 본문에 heading 이 있으면 heading 마다, 없으면 빈 줄로 나뉜 덩어리마다 paragraph 로 나눈다.
+func ParseMarkdownNotes(data []byte) ([]*Note, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open zip")
+	}
+
+	var notes []*Note
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !isNoteFile(file.Name) {
+			continue
+		}
+
+		b, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		note := parseMarkdownNote(file.Name, string(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))))
+		if note.Created == nil && !file.Modified.IsZero() {
+			modified := file.Modified
+			note.Created = &modified
+		}
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+// isNoteFile 은 markdown 파일이면서 vault 설정, 휴지통 같은 숨김 폴더에 있지 않은지 확인한다.
+func isNoteFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	if ext != ".md" && ext != ".markdown" {
+		return false
+	}
+
+	segments := strings.Split(name, "/")
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ".") || segment == "__MACOSX" {
+			return false
+		}
+	}
+	// Logseq 의 설정, 백업 폴더
+	return !(len(segments) > 1 && segments[0] == "logseq") && !(len(segments) > 2 && segments[1] == "logseq")
+}
+
+func parseMarkdownNote(name, content string) *Note {
+	properties, body := parseFrontMatter(strings.ReplaceAll(content, "\r\n", "\n"))
+
+	note := &Note{
+		Path:       name,
+		Title:      noteTitleOf(name),
+		Paragraphs: splitNoteParagraphs(body),
+	}
+	if title := properties["title"]; len(title) > 0 && title[0] != "" {
+		note.Title = title[0]
+	}
+
+	var tags []string
+	for _, key := range []string{"tags", "tag"} {
+		for _, value := range properties[key] {
+			for _, tag := range splitTags(value) {
+				tags = append(tags, strings.Trim(tag, "#[] "))
+			}
+		}
+	}
+	note.Tags = dedupeTags(tags)
+
+	for _, key := range noteURLKeys {
+		for _, value := range properties[key] {
+			if linkRegexp.FindString(value) == value {
+				note.URLs = append(note.URLs, value)
+			}
+		}
+	}
+
+	for _, key := range []string{"created", "date"} {
+		if values := properties[key]; len(values) > 0 && note.Created == nil {
+			note.Created = parseTime(values[0], time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02")
+		}
+	}
+	return note
+}
+
+// noteTitleOf 는 경로에서 확장자를 뺀 파일 이름이다. Logseq 은 제목의 '/' 를 %2F 로 적는다.
+func noteTitleOf(name string) string {
+	title := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if unescaped, err := netUrl.PathUnescape(title); err == nil {
+		title = unescaped
+	}
+	return strings.TrimSpace(title)
+}
+
+// parseFrontMatter 는 `---` 로 감싼 yaml front matter 혹은 Logseq 의 `key:: value` 속성을 읽고 나머지 본문을 돌려준다.
+// 노트의 front matter 에서 쓰이는 `key: value`, `key: [a, b]`, `- item` 목록 정도만 읽는다.
+func parseFrontMatter(content string) (map[string][]string, string) {
+	properties := map[string][]string{}
+	lines := strings.Split(content, "\n")
+
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if line := strings.TrimSpace(lines[i]); line == "---" || line == "..." {
+				parseYAMLProperties(lines[1:i], properties)
+				return properties, strings.Join(lines[i+1:], "\n")
+			}
+		}
+		return properties, content
+	}
+
+	i := 0
+	for ; i < len(lines); i++ {
+		matches := logseqPropertyRegexp.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if matches == nil {
+			break
+		}
+		key := strings.ToLower(matches[1])
+		properties[key] = append(properties[key], splitTags(matches[2])...)
+	}
+	return properties, strings.Join(lines[i:], "\n")
+}
+
+func parseYAMLProperties(lines []string, properties map[string][]string) {
+	key := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// 앞 key 의 목록 항목
+		if strings.HasPrefix(trimmed, "- ") && key != "" {
+			properties[key] = append(properties[key], unquote(strings.TrimPrefix(trimmed, "- ")))
+			continue
+		}
+
+		i := strings.Index(trimmed, ":")
+		if i <= 0 || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(trimmed[:i]))
+		value := strings.TrimSpace(trimmed[i+1:])
+
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			for _, v := range strings.Split(value[1:len(value)-1], ",") {
+				if v = unquote(v); v != "" {
+					properties[key] = append(properties[key], v)
+				}
+			}
+		} else if value = unquote(value); value != "" {
+			properties[key] = append(properties[key], value)
+		}
+	}
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// splitNoteParagraphs 는 본문을 heading 마다, heading 이 없으면 빈 줄마다 나눈다. code block 안은 나누지 않는다.
+func splitNoteParagraphs(body string) []*NoteParagraph {
+	lines := strings.Split(body, "\n")
+
+	hasHeading := false
+	forEachLine(lines, func(line string, inFence bool) {
+		if !inFence && headingRegexp.MatchString(line) {
+			hasHeading = true
+		}
+	})
+
+	var paragraphs []*NoteParagraph
+	var block []string
+	flush := func() {
+		if content := strings.TrimSpace(strings.Join(block, "\n")); content != "" {
+			paragraphs = append(paragraphs, &NoteParagraph{
+				Content: content,
+				Links:   findLinks(content),
+			})
+		}
+		block = nil
+	}
+
+	forEachLine(lines, func(line string, inFence bool) {
+		switch {
+		case inFence:
+		case hasHeading && headingRegexp.MatchString(line):
+			flush()
+		case !hasHeading && strings.TrimSpace(line) == "":
+			flush()
+			return
+		}
+		block = append(block, line)
+	})
+	flush()
+	return paragraphs
+}
+
+// forEachLine 은 각 줄이 fenced code block 안에 있는지와 함께 fn 을 부른다. 여닫는 ``` 줄도 안에 있는 것으로 본다.
+func forEachLine(lines []string, fn func(line string, inFence bool)) {
+	fence := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+			fn(line, true)
+			continue
+		}
+		fn(line, fence != "")
+		if fence != "" && strings.HasPrefix(trimmed, fence) {
+			fence = ""
+		}
+	}
+}
+
+func findLinks(content string) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, link := range linkRegexp.FindAllString(content, -1) {
+		link = strings.TrimRight(link, ".,;:!?*_")
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
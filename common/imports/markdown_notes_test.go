@@ -0,0 +1,53 @@
+package imports
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseMarkdownNotes(t *testing.T) {
+	files := map[string]string{
+		"vault/Go memo.md": "---\ntitle: Go 메모\ntags: [go, \"#web\"]\nsource: https://example.com/go\n---\n" +
+			"# Goroutine\n\nsee https://example.com/a.\n\n```\n# not a heading\n```\n## Channel\n[link](https://example.com/b)\n",
+		"vault/plain.md":            "first block\n\nsecond\nblock\n",
+		"vault/pages/logseq%2Fa.md": "tags:: logseq, note\nurl:: https://example.com/c\n\n- block\n",
+		"vault/.obsidian/app.md":    "ignored",
+		"vault/logseq/bak/old.md":   "ignored",
+		"vault/image.png":           "ignored",
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range []string{"vault/Go memo.md", "vault/plain.md", "vault/pages/logseq%2Fa.md", "vault/.obsidian/app.md", "vault/logseq/bak/old.md", "vault/image.png"} {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, _ = f.Write([]byte(files[name]))
+	}
+	require.NoError(t, w.Close())
+
+	notes, err := ParseMarkdownNotes(buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+
+	// case 1: yaml front matter, heading 으로 나누기
+	require.Equal(t, "Go 메모", notes[0].Title)
+	require.Equal(t, []string{"go", "web"}, notes[0].Tags)
+	require.Equal(t, []string{"https://example.com/go"}, notes[0].URLs)
+	require.Len(t, notes[0].Paragraphs, 2)
+	require.Equal(t, "# Goroutine\n\nsee https://example.com/a.\n\n```\n# not a heading\n```", notes[0].Paragraphs[0].Content)
+	require.Equal(t, []string{"https://example.com/a"}, notes[0].Paragraphs[0].Links)
+	require.Equal(t, []string{"https://example.com/b"}, notes[0].Paragraphs[1].Links)
+
+	// case 2: 빈 줄로 나누기
+	require.Equal(t, "plain", notes[1].Title)
+	require.Len(t, notes[1].Paragraphs, 2)
+	require.Equal(t, "second\nblock", notes[1].Paragraphs[1].Content)
+
+	// case 3: logseq 속성
+	require.Equal(t, "logseq/a", notes[2].Title)
+	require.Equal(t, []string{"logseq", "note"}, notes[2].Tags)
+	require.Equal(t, []string{"https://example.com/c"}, notes[2].URLs)
+	require.Equal(t, "- block", notes[2].Paragraphs[0].Content)
+}
@@ -0,0 +1,128 @@
+package imports
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"strings"
+)
+
+// 브라우저가 만드는 최상위 폴더, tag 로 쓰지 않는다
+var netscapeRootFolderAttrs = []string{"personal_toolbar_folder", "unfiled_bookmarks_folder"}
+
+// ParseNetscapeBookmarks 는 Chrome, Firefox, Safari 가 내보내는 Netscape bookmark 파일을 읽는다.
+// 북마크가 들어있는 폴더 경로의 각 폴더 이름과 Firefox 의 TAGS 속성이 tag 가 되고, ADD_DATE 가 Created 가 된다.
+func ParseNetscapeBookmarks(data []byte) ([]*Item, error) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+
+	var (
+		items   []*Item
+		folders []string
+		// 바로 다음 <DL> 이 여는 폴더의 이름, 최상위 폴더이면 빈 문자열
+		folder     string
+		hasFolder  bool
+		rootFolder bool
+		// <DL> 마다 folders 에 폴더를 넣었는지
+		opened  []bool
+		current *Item
+		text    strings.Builder
+		inTitle bool
+		inLink  bool
+	)
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, errors.Wrap(err, "failed to read bookmarks")
+			}
+			if len(items) == 0 && !bytes.Contains(bytes.ToUpper(data), []byte("NETSCAPE-BOOKMARK-FILE")) {
+				return nil, errors.New("not a netscape bookmark file")
+			}
+			return items, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				inTitle = true
+				text.Reset()
+				rootFolder = hasAnyAttr(token, netscapeRootFolderAttrs)
+			case atom.Dl:
+				push := hasFolder && folder != ""
+				if push {
+					folders = append(folders, folder)
+				}
+				opened = append(opened, push)
+				hasFolder = false
+			case atom.A:
+				href := attr(token, "href")
+				if href == "" || strings.HasPrefix(strings.ToLower(href), "javascript:") || strings.HasPrefix(strings.ToLower(href), "place:") {
+					continue
+				}
+				inLink = true
+				text.Reset()
+				current = &Item{
+					URL:     href,
+					Tags:    append(append([]string{}, folders...), splitTags(attr(token, "tags"))...),
+					Created: unixTime(attr(token, "add_date")),
+				}
+			}
+
+		case html.TextToken:
+			if inTitle || inLink {
+				text.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				inTitle = false
+				hasFolder = true
+				folder = strings.TrimSpace(text.String())
+				if rootFolder {
+					folder = ""
+				}
+			case atom.Dl:
+				if len(opened) > 0 {
+					if opened[len(opened)-1] && len(folders) > 0 {
+						folders = folders[:len(folders)-1]
+					}
+					opened = opened[:len(opened)-1]
+				}
+			case atom.A:
+				if inLink && current != nil {
+					current.Title = strings.TrimSpace(text.String())
+					current.Tags = dedupeTags(current.Tags)
+					items = append(items, current)
+				}
+				inLink = false
+				current = nil
+			}
+		}
+	}
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAnyAttr(token html.Token, names []string) bool {
+	for _, name := range names {
+		for _, a := range token.Attr {
+			if strings.EqualFold(a.Key, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package imports
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseNetscapeBookmarks(t *testing.T) {
+	data := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1600000000" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/blog/" ADD_DATE="1610000000">The Go Blog</A>
+        <DT><H3>Programming</H3>
+        <DL><p>
+            <DT><H3>Go &amp; Rust</H3>
+            <DL><p>
+                <DT><A HREF="https://example.com/generics" ADD_DATE="1620000000000" TAGS="generics,go">Generics</A>
+            </DL><p>
+            <DT><A HREF="https://example.com/sql">SQL</A>
+            <DT><A HREF="javascript:alert(1)">bookmarklet</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://example.com/root">Root</A>
+</DL><p>`
+
+	items, err := ParseNetscapeBookmarks([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+
+	created := time.Unix(1610000000, 0)
+	require.Equal(t, &Item{URL: "https://go.dev/blog/", Title: "The Go Blog", Created: &created}, items[0])
+
+	created = time.Unix(1620000000, 0)
+	require.Equal(t, &Item{URL: "https://example.com/generics", Title: "Generics", Tags: []string{"Programming", "Go & Rust", "generics", "go"}, Created: &created}, items[1])
+	require.Equal(t, &Item{URL: "https://example.com/sql", Title: "SQL", Tags: []string{"Programming"}}, items[2])
+	require.Equal(t, &Item{URL: "https://example.com/root", Title: "Root"}, items[3])
+
+	_, err = ParseNetscapeBookmarks([]byte("just text"))
+	require.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package imports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+)
+
+// zip 안의 파일 하나를 읽을 최대 크기
+const maxZipFileSize = 50 * 1024 * 1024
+
+type omnivoreEntry struct {
+	Slug    string            `json:"slug"`
+	Title   string            `json:"title"`
+	URL     string            `json:"url"`
+	State   string            `json:"state"`
+	Labels  []json.RawMessage `json:"labels"`
+	SavedAt string            `json:"savedAt"`
+}
+
+// ParseOmnivoreExport 는 Omnivore 의 내보내기 파일을 읽는다.
+// metadata json 배열 하나이거나, `metadata_*.json` 과 `content/<slug>.md` 가 들어있는 zip 파일이다.
+func ParseOmnivoreExport(data []byte) ([]*Item, error) {
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		return parseOmnivoreMetadata(data, nil)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open zip")
+	}
+
+	var metadata [][]byte
+	contents := map[string]string{}
+	for _, file := range archive.File {
+		name := path.Base(file.Name)
+		isMetadata := strings.HasPrefix(name, "metadata") && strings.HasSuffix(name, ".json")
+		isContent := path.Base(path.Dir(file.Name)) == "content" && (strings.HasSuffix(name, ".md") || strings.HasSuffix(name, ".html"))
+		if !isMetadata && !isContent {
+			continue
+		}
+
+		b, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if isMetadata {
+			metadata = append(metadata, b)
+			continue
+		}
+
+		content := string(b)
+		if strings.HasSuffix(name, ".html") {
+			if content, err = htmlToMarkdown(content); err != nil {
+				return nil, errors.Wrapf(err, "invalid content %s", file.Name)
+			}
+		}
+		contents[strings.TrimSuffix(name, path.Ext(name))] = strings.TrimSpace(content)
+	}
+
+	if len(metadata) == 0 {
+		return nil, errors.New("no metadata file in omnivore export")
+	}
+
+	var items []*Item
+	for _, m := range metadata {
+		parsed, err := parseOmnivoreMetadata(m, contents)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, parsed...)
+	}
+	return items, nil
+}
+
+// parseOmnivoreMetadata 는 metadata json 배열을 읽고, contents 에 slug 의 본문이 있으면 함께 담는다.
+func parseOmnivoreMetadata(data []byte, contents map[string]string) ([]*Item, error) {
+	var entries []*omnivoreEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json")
+	}
+
+	var items []*Item
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.URL) == "" {
+			continue
+		}
+
+		items = append(items, &Item{
+			URL:      strings.TrimSpace(entry.URL),
+			Title:    strings.TrimSpace(entry.Title),
+			Tags:     dedupeTags(omnivoreLabels(entry.Labels)),
+			Created:  parseTime(entry.SavedAt, time.RFC3339),
+			Content:  contents[entry.Slug],
+			Archived: strings.EqualFold(entry.State, "archived"),
+		})
+	}
+	return items, nil
+}
+
+// omnivoreLabels 는 내보낸 버전에 따라 문자열이거나 `{"name"}` 객체인 label 들을 읽는다.
+func omnivoreLabels(labels []json.RawMessage) []string {
+	var names []string
+	for _, label := range labels {
+		var name string
+		var object struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(label, &name); err == nil {
+			names = append(names, name)
+		} else if err := json.Unmarshal(label, &object); err == nil {
+			names = append(names, object.Name)
+		}
+	}
+	return names
+}
+
+// readZipFile 은 zip 안의 파일을 maxZipFileSize 까지 읽는다. 압축을 풀면 더 큰 파일은 읽지 않는다.
+func readZipFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxZipFileSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", file.Name, maxZipFileSize)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", file.Name)
+	}
+	defer reader.Close()
+
+	// 헤더의 크기는 믿을 수 없으므로 읽으면서도 제한한다
+	b, err := ioutil.ReadAll(io.LimitReader(reader, maxZipFileSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", file.Name)
+	} else if len(b) > maxZipFileSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", file.Name, maxZipFileSize)
+	}
+	return b, nil
+}
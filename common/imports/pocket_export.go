@@ -0,0 +1,39 @@
+package imports
+
+import (
+	"bytes"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ParsePocketExport 는 Pocket 의 html 내보내기 파일을 읽는다.
+// `<h1>Unread</h1>`, `<h1>Read Archive</h1>` 아래에 `<a href time_added tags>` 목록이 있다.
+func ParsePocketExport(data []byte) ([]*Item, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse html")
+	}
+
+	var items []*Item
+	archived := false
+	doc.Find("h1, a").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "h1" {
+			archived = strings.Contains(strings.ToLower(s.Text()), "archive")
+			return
+		}
+
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if href == "" {
+			return
+		}
+		items = append(items, &Item{
+			URL:      href,
+			Title:    strings.TrimSpace(s.Text()),
+			Tags:     dedupeTags(splitTags(s.AttrOr("tags", ""))),
+			Created:  unixTime(s.AttrOr("time_added", "")),
+			Archived: archived,
+		})
+	})
+	return items, nil
+}
@@ -0,0 +1,37 @@
+package imports
+
+import (
+	"strings"
+	"time"
+)
+
+// ParseRaindropExport 는 Raindrop.io 의 csv 내보내기 파일을 읽는다.
+// `id,title,note,excerpt,url,folder,tags,created,cover,highlights,favorite` 컬럼으로, 폴더도 tag 로 가져온다.
+func ParseRaindropExport(data []byte) ([]*Item, error) {
+	records, err := readCSVRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	for _, record := range records {
+		if record["url"] == "" {
+			continue
+		}
+
+		var tags []string
+		if folder := record["folder"]; folder != "" && !strings.EqualFold(folder, "unsorted") {
+			tags = append(tags, folder)
+		}
+		tags = append(tags, splitTags(record["tags"])...)
+
+		items = append(items, &Item{
+			URL:      record["url"],
+			Title:    record["title"],
+			Tags:     dedupeTags(tags),
+			Created:  parseTime(record["created"], time.RFC3339),
+			Favorite: strings.EqualFold(record["favorite"], "true"),
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,47 @@
+package imports
+
+import (
+	"strings"
+	"time"
+)
+
+// ParseReadwiseExport 는 Readwise Reader 의 csv 내보내기 파일을 읽는다.
+// `Title,URL,ID,Document tags,Saved date,Reading progress,Location,Seen` 컬럼으로,
+// Location 이 archive 면 보관한 것이고 shortlist 면 즐겨찾기로 본다.
+func ParseReadwiseExport(data []byte) ([]*Item, error) {
+	records, err := readCSVRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	for _, record := range records {
+		if record["url"] == "" {
+			continue
+		}
+
+		location := strings.ToLower(record["location"])
+		items = append(items, &Item{
+			URL:      record["url"],
+			Title:    record["title"],
+			Tags:     dedupeTags(parseListLiteral(record["document tags"])),
+			Created:  parseTime(record["saved date"], "2006-01-02 15:04:05-07:00", "2006-01-02 15:04:05", time.RFC3339),
+			Archived: location == "archive",
+			Favorite: location == "shortlist",
+		})
+	}
+	return items, nil
+}
+
+// parseListLiteral 은 `['a', 'b']` 처럼 적힌 목록을 읽는다.
+func parseListLiteral(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.Trim(strings.TrimSpace(v), `'"`); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
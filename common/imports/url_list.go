@@ -0,0 +1,159 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+)
+
+// ParseURLList 가 읽을 수 있는 형식
+const (
+	// 한 줄에 url 하나, 빈 줄과 `#` 으로 시작하는 줄은 건너뛴다
+	FormatText = "text"
+	// url, tags, title 컬럼, header 가 없으면 이 순서로 읽는다. 형식을 정하지 않으면 header 가 있어야 csv 로 본다
+	FormatCSV = "csv"
+	// url 문자열 혹은 `{"url", "tags", "title"}` 객체의 배열
+	FormatJSON = "json"
+)
+
+// ParseURLList 는 url 목록을 읽는다. format 이 비어있으면 내용을 보고 형식을 정한다.
+func ParseURLList(data []byte, format string) ([]*Item, error) {
+	if format == "" {
+		format = detectURLListFormat(data)
+	}
+
+	switch format {
+	case FormatText:
+		return parseTextURLList(data), nil
+	case FormatCSV:
+		return parseCSVURLList(data)
+	case FormatJSON:
+		return parseJSONURLList(data)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+func detectURLListFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		return FormatJSON
+	}
+
+	// url 에도 ',' 가 들어갈 수 있으므로 header 가 있을 때만 csv 로 본다
+	record, err := csv.NewReader(bytes.NewReader(trimmed)).Read()
+	if err == nil && len(record) > 1 {
+		if _, ok := csvHeader(record); ok {
+			return FormatCSV
+		}
+	}
+	return FormatText
+}
+
+func parseTextURLList(data []byte) []*Item {
+	var items []*Item
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, &Item{URL: line})
+	}
+	return items
+}
+
+func parseCSVURLList(data []byte) ([]*Item, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := map[string]int{"url": 0, "tags": 1, "title": 2}
+	var items []*Item
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to read csv")
+		}
+
+		if line == 0 {
+			if header, ok := csvHeader(record); ok {
+				columns = header
+				continue
+			}
+		}
+
+		item := &Item{
+			URL:   strings.TrimSpace(csvField(record, columns, "url")),
+			Title: strings.TrimSpace(csvField(record, columns, "title")),
+			Tags:  splitTags(csvField(record, columns, "tags")),
+		}
+		if item.URL == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// csvHeader 는 record 가 url 컬럼을 가진 header 이면 컬럼 이름별 위치를 돌려준다.
+func csvHeader(record []string) (map[string]int, bool) {
+	columns := map[string]int{}
+	for i, name := range record {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, ok := columns["url"]
+	return columns, ok
+}
+
+func csvField(record []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+func parseJSONURLList(data []byte) ([]*Item, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json")
+	}
+
+	var items []*Item
+	for i, entry := range entries {
+		var url string
+		if err := json.Unmarshal(entry, &url); err == nil {
+			items = append(items, &Item{URL: strings.TrimSpace(url)})
+			continue
+		}
+
+		var object struct {
+			URL   string          `json:"url"`
+			Title string          `json:"title"`
+			Tags  json.RawMessage `json:"tags"`
+		}
+		if err := json.Unmarshal(entry, &object); err != nil {
+			return nil, errors.Wrapf(err, "invalid entry %d", i)
+		}
+
+		item := &Item{
+			URL:   strings.TrimSpace(object.URL),
+			Title: strings.TrimSpace(object.Title),
+		}
+		// tags 는 배열이나 `go,web` 같은 문자열 모두 받는다
+		var tags []string
+		var tagsString string
+		if err := json.Unmarshal(object.Tags, &tags); err == nil {
+			item.Tags = splitTags(strings.Join(tags, ","))
+		} else if err := json.Unmarshal(object.Tags, &tagsString); err == nil {
+			item.Tags = splitTags(tagsString)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
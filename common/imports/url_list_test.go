@@ -0,0 +1,44 @@
+package imports
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseURLList(t *testing.T) {
+	// case 1: text
+	items, err := ParseURLList([]byte("# reading list\nhttps://example.com/a\n\n  https://example.com/b  \n"), "")
+	require.NoError(t, err)
+	require.Equal(t, []*Item{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}}, items)
+
+	// case 2: csv with header
+	items, err = ParseURLList([]byte("Title,URL,Tags\nHello,https://example.com/a,\"go, web\"\n,https://example.com/b,\n"), "")
+	require.NoError(t, err)
+	require.Equal(t, []*Item{
+		{URL: "https://example.com/a", Title: "Hello", Tags: []string{"go", "web"}},
+		{URL: "https://example.com/b"},
+	}, items)
+
+	// case 3: csv without header
+	items, err = ParseURLList([]byte("https://example.com/a,go|web,Hello\n"), FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, []*Item{{URL: "https://example.com/a", Title: "Hello", Tags: []string{"go", "web"}}}, items)
+
+	// case 4: json
+	items, err = ParseURLList([]byte(`["https://example.com/a", {"url": "https://example.com/b", "tags": ["go"], "title": "B"}, {"url": "https://example.com/c", "tags": "go,web"}]`), "")
+	require.NoError(t, err)
+	require.Equal(t, []*Item{
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/b", Title: "B", Tags: []string{"go"}},
+		{URL: "https://example.com/c", Tags: []string{"go", "web"}},
+	}, items)
+
+	// case 5: ',' 가 들어간 url
+	items, err = ParseURLList([]byte("https://example.com/a?ids=1,2\n"), "")
+	require.NoError(t, err)
+	require.Equal(t, []*Item{{URL: "https://example.com/a?ids=1,2"}}, items)
+
+	// case 6: unknown format
+	_, err = ParseURLList([]byte("https://example.com/a"), "xml")
+	require.Error(t, err)
+}
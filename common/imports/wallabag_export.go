@@ -0,0 +1,62 @@
+package imports
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+type wallabagEntry struct {
+	URL        string    `json:"url"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	Tags       []string  `json:"tags"`
+	IsArchived looseBool `json:"is_archived"`
+	IsStarred  looseBool `json:"is_starred"`
+	CreatedAt  string    `json:"created_at"`
+}
+
+// ParseWallabagExport 는 wallabag 의 json 내보내기 파일을 읽는다. wallabag 이 추출해둔 html 본문도 함께 가져온다.
+func ParseWallabagExport(data []byte) ([]*Item, error) {
+	var entries []*wallabagEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json")
+	}
+
+	var items []*Item
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.URL) == "" {
+			continue
+		}
+
+		content, err := htmlToMarkdown(entry.Content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid content of %s", entry.URL)
+		}
+
+		items = append(items, &Item{
+			URL:      strings.TrimSpace(entry.URL),
+			Title:    strings.TrimSpace(entry.Title),
+			Tags:     dedupeTags(entry.Tags),
+			Created:  parseTime(entry.CreatedAt, "2006-01-02T15:04:05-0700", time.RFC3339),
+			Content:  content,
+			Archived: bool(entry.IsArchived),
+			Favorite: bool(entry.IsStarred),
+		})
+	}
+	return items, nil
+}
+
+// looseBool 은 true, false 외에 1, 0 으로 적힌 값도 받는다.
+type looseBool bool
+
+func (b *looseBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true", "1":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
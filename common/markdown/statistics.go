@@ -0,0 +1,46 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 분당 읽는 단어 수
+const wordsPerMinute = 200
+
+var (
+	imageRegex = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	linkRegex  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// WordCount 는 markdown 본문의 단어 수다. 이미지와 링크 주소는 세지 않으며, 띄어쓰기를 하지 않는 한자, 가나는 글자 하나를 단어 하나로 센다.
+func WordCount(content string) int {
+	content = imageRegex.ReplaceAllString(content, " ")
+	content = linkRegex.ReplaceAllString(content, "$1")
+
+	count := 0
+	for _, field := range strings.Fields(content) {
+		inWord := false
+		for _, r := range field {
+			if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+				count++
+				inWord = false
+			} else if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				if !inWord {
+					count++
+				}
+				inWord = true
+			}
+		}
+	}
+	return count
+}
+
+// ReadingMinutes 는 wordCount 만큼의 글을 읽는 데 걸리는 시간 (분) 이다.
+func ReadingMinutes(wordCount int) int {
+	if wordCount <= 0 {
+		return 0
+	}
+	return (wordCount + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -0,0 +1,21 @@
+package markdown
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWordCount(t *testing.T) {
+	require.Equal(t, 0, WordCount(""))
+	require.Equal(t, 3, WordCount("# Hello, world!\n\nfoo-bar"))
+	require.Equal(t, 3, WordCount("see [the docs](https://example.com/a/b) ![logo](/apis/assets/abc)"))
+	require.Equal(t, 2, WordCount("안녕하세요 세계"))
+	require.Equal(t, 4, WordCount("日本語 go"))
+}
+
+func TestReadingMinutes(t *testing.T) {
+	require.Equal(t, 0, ReadingMinutes(0))
+	require.Equal(t, 1, ReadingMinutes(1))
+	require.Equal(t, 1, ReadingMinutes(200))
+	require.Equal(t, 2, ReadingMinutes(201))
+}
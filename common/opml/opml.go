@@ -0,0 +1,102 @@
+package opml
+
+import (
+	"encoding/xml"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+type Subscription struct {
+	Title  string
+	XMLURL string
+	// 상위 outline 의 이름들, 보통 폴더/카테고리로 쓰인다
+	Categories []string
+}
+
+type document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    head     `xml:"head"`
+	Body    body     `xml:"body"`
+}
+
+type head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type body struct {
+	Outlines []outline `xml:"outline"`
+}
+
+type outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	Category string    `xml:"category,attr,omitempty"`
+	Outlines []outline `xml:"outline"`
+}
+
+func Parse(data []byte) ([]*Subscription, error) {
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to decode opml")
+	}
+	return collect(doc.Body.Outlines, nil), nil
+}
+
+func collect(outlines []outline, parents []string) []*Subscription {
+	var subscriptions []*Subscription
+	for _, o := range outlines {
+		title := o.Title
+		if title == "" {
+			title = o.Text
+		}
+
+		if o.XMLURL != "" {
+			categories := append([]string{}, parents...)
+			for _, category := range strings.Split(o.Category, ",") {
+				if category = strings.Trim(strings.TrimSpace(category), "/"); category != "" {
+					categories = append(categories, category)
+				}
+			}
+			subscriptions = append(subscriptions, &Subscription{
+				Title:      title,
+				XMLURL:     o.XMLURL,
+				Categories: categories,
+			})
+		}
+
+		if len(o.Outlines) > 0 {
+			subscriptions = append(subscriptions, collect(o.Outlines, append(parents, title))...)
+		}
+	}
+	return subscriptions
+}
+
+func Marshal(title string, subscriptions []*Subscription) ([]byte, error) {
+	doc := document{
+		Version: "2.0",
+		Head: head{
+			Title:       title,
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+	}
+	for _, subscription := range subscriptions {
+		doc.Body.Outlines = append(doc.Body.Outlines, outline{
+			Text:     subscription.Title,
+			Title:    subscription.Title,
+			Type:     "rss",
+			XMLURL:   subscription.XMLURL,
+			Category: strings.Join(subscription.Categories, ","),
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode opml")
+	}
+	return append([]byte(xml.Header), data...), nil
+}
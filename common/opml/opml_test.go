@@ -0,0 +1,38 @@
+package opml
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<opml version="1.0">
+  <head><title>subscriptions</title></head>
+  <body>
+    <outline text="Go" title="Go">
+      <outline text="The Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+    </outline>
+    <outline text="Standalone" type="rss" xmlUrl="https://example.com/rss" category="/tech,news"/>
+  </body>
+</opml>`
+
+	subscriptions, err := Parse([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	require.Equal(t, &Subscription{Title: "The Go Blog", XMLURL: "https://go.dev/blog/feed.atom", Categories: []string{"Go"}}, subscriptions[0])
+	require.Equal(t, &Subscription{Title: "Standalone", XMLURL: "https://example.com/rss", Categories: []string{"tech", "news"}}, subscriptions[1])
+}
+
+func TestMarshal(t *testing.T) {
+	subscriptions := []*Subscription{
+		{Title: "The Go Blog", XMLURL: "https://go.dev/blog/feed.atom", Categories: []string{"go", "blog"}},
+	}
+
+	data, err := Marshal("personal-archive", subscriptions)
+	require.NoError(t, err)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, subscriptions, parsed)
+}
@@ -1,51 +1,46 @@
 package pocket
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/jaeyo/personal-archive/common/fetch"
 	"github.com/pasztorpisti/qs"
 	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
-	"io/ioutil"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func ObtainRequestToken(consumerKey, redirectURI string) (string, error) {
 	params := fmt.Sprintf("consumer_key=%s&redirect_uri=%s", consumerKey, redirectURI)
-	reqBody := bytes.NewBufferString(params)
 	url := "https://getpocket.com/v3/oauth/request"
-	resp, err := http.Post(url, "application/x-www-form-urlencoded; charset=UTF-8", reqBody)
+	resp, err := fetch.Post(url, "application/x-www-form-urlencoded; charset=UTF-8", []byte(params))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to request http post")
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("invalid status: %d", resp.StatusCode)
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to read body")
-	}
-
 	var m map[string]string
-	if err := qs.Unmarshal(&m, string(respBody)); err != nil {
+	if err := qs.Unmarshal(&m, string(resp.Body)); err != nil {
 		return "", errors.Wrap(err, "failed to unmarshal response body")
 	}
 	return m["code"], nil
 }
 
 func ObtainAccessTokenAndUsername(consumerKey, requestToken string) (bool, string, string, error) {
-	reqBody := bytes.NewBufferString(fmt.Sprintf("consumer_key=%s&code=%s", consumerKey, requestToken))
+	reqBody := []byte(fmt.Sprintf("consumer_key=%s&code=%s", consumerKey, requestToken))
 	url := "https://getpocket.com/v3/oauth/authorize"
-	resp, err := http.Post(url, "application/x-www-form-urlencoded; charset=UTF-8", reqBody)
+	resp, err := fetch.Post(url, "application/x-www-form-urlencoded; charset=UTF-8", reqBody)
 	if err != nil {
 		return false, "", "", errors.Wrap(err, "failed to request http post")
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusForbidden {
 		return false, "", "", nil
@@ -53,28 +48,81 @@ func ObtainAccessTokenAndUsername(consumerKey, requestToken string) (bool, strin
 		return false, "", "", fmt.Errorf("invalid status: %d", resp.StatusCode)
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return false, "", "", errors.Wrap(err, "failed to read body")
-	}
-
 	var m map[string]string
-	if err := qs.Unmarshal(&m, string(respBody)); err != nil {
+	if err := qs.Unmarshal(&m, string(resp.Body)); err != nil {
 		return false, "", "", errors.Wrap(err, "failed to unmarshal response body")
 	}
 
 	return true, m["access_token"], m["username"], nil
 }
 
-func Retrieve(consumerKey, accessToken string, offset, count int) ([]string, error){
+// Item.Status 의 값들
+const (
+	StatusUnread   = "0"
+	StatusArchived = "1"
+	StatusDeleted  = "2"
+)
+
+// Item 은 pocket 에 저장된 항목 하나다. 지워진 항목은 ItemID 와 Status 만 채워진다.
+type Item struct {
+	ItemID      string
+	GivenURL    string
+	ResolvedURL string
+	Status      string
+	// 저장할 때 적힌 제목과 pocket 이 문서에서 찾은 제목
+	GivenTitle    string
+	ResolvedTitle string
+	Tags          []string
+	Favorite      bool
+	TimeAdded     *time.Time
+	Excerpt       string
+}
+
+// URL 은 pocket 이 redirect 를 따라가 찾은 url, 없으면 저장할 때의 url 이다.
+func (i *Item) URL() string {
+	if i.ResolvedURL != "" {
+		return i.ResolvedURL
+	}
+	return i.GivenURL
+}
+
+// Title 은 pocket 이 찾은 제목, 없으면 저장할 때 적힌 제목이다.
+func (i *Item) Title() string {
+	if i.ResolvedTitle != "" {
+		return i.ResolvedTitle
+	}
+	return i.GivenTitle
+}
+
+type RetrieveOptions struct {
+	// 0 이 아니면 이 시각 이후에 추가, 변경, 삭제된 항목만 가져온다
+	Since int64
+	// Count 가 0 이면 모두 가져온다
+	Offset int
+	Count  int
+}
+
+type RetrieveResult struct {
+	// 오래된 항목부터
+	Items []*Item
+	// 다음에 바뀐 항목만 가져올 때 RetrieveOptions.Since 로 넘길 값
+	Since int64
+}
+
+func Retrieve(consumerKey, accessToken string, options RetrieveOptions) (*RetrieveResult, error) {
 	params := map[string]string{
 		"consumer_key": consumerKey,
 		"access_token": accessToken,
-		"state": "all",
-		"detailType": "simple",
-		"sort": "oldest",
-		"offset": strconv.Itoa(offset),
-		"count": strconv.Itoa(count),
+		"state":        "all",
+		"detailType":   "complete",
+		"sort":         "oldest",
+	}
+	if options.Since > 0 {
+		params["since"] = strconv.FormatInt(options.Since, 10)
+	}
+	if options.Count > 0 {
+		params["offset"] = strconv.Itoa(options.Offset)
+		params["count"] = strconv.Itoa(options.Count)
 	}
 	paramsBytes, err := json.Marshal(params)
 	if err != nil {
@@ -82,27 +130,61 @@ func Retrieve(consumerKey, accessToken string, offset, count int) ([]string, err
 	}
 
 	url := "https://getpocket.com/v3/get"
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(paramsBytes))
+	resp, err := fetch.Post(url, "application/json", paramsBytes)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to request http post")
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("invalid status: %d", resp.StatusCode)
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read body")
-	}
+	return parseRetrieveResponse(resp.Body), nil
+}
 
-	items := gjson.Get(string(respBody), "list")
-	urls := []string{}
-	items.ForEach(func(_, value gjson.Result) bool {
-		urls = append(urls, value.Get("resolved_url").String())
+// parseRetrieveResponse 는 `/v3/get` 의 응답을 읽고, 순서가 없는 list 를 sort_id 로 정렬한다.
+func parseRetrieveResponse(body []byte) *RetrieveResult {
+	parsed := gjson.ParseBytes(body)
+	result := &RetrieveResult{Since: parsed.Get("since").Int()}
+	sortIDs := map[*Item]int64{}
+	parsed.Get("list").ForEach(func(_, value gjson.Result) bool {
+		item := &Item{
+			ItemID:      value.Get("item_id").String(),
+			GivenURL:    value.Get("given_url").String(),
+			ResolvedURL: value.Get("resolved_url").String(),
+			Status:      value.Get("status").String(),
+			// 제목이 없으면 url 이 들어있기도 하다
+			GivenTitle:    titleOf(value.Get("given_title").String(), value.Get("given_url").String()),
+			ResolvedTitle: titleOf(value.Get("resolved_title").String(), value.Get("resolved_url").String()),
+			Favorite:      value.Get("favorite").String() == "1",
+			Excerpt:       strings.TrimSpace(value.Get("excerpt").String()),
+		}
+		// tags 는 tag 이름을 key 로 하는 object 다
+		value.Get("tags").ForEach(func(key, _ gjson.Result) bool {
+			item.Tags = append(item.Tags, key.String())
+			return true
+		})
+		if added := value.Get("time_added").Int(); added > 0 {
+			timeAdded := time.Unix(added, 0)
+			item.TimeAdded = &timeAdded
+		}
+		// 지워진 항목에는 sort_id 가 없으므로 뒤로 보낸다
+		sortIDs[item] = math.MaxInt64
+		if sortID := value.Get("sort_id"); sortID.Exists() {
+			sortIDs[item] = sortID.Int()
+		}
+		result.Items = append(result.Items, item)
 		return true
 	})
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		return sortIDs[result.Items[i]] < sortIDs[result.Items[j]]
+	})
+	return result
+}
 
-	return urls, nil
-}
\ No newline at end of file
+func titleOf(title, url string) string {
+	if title = strings.TrimSpace(title); title == url {
+		return ""
+	}
+	return title
+}
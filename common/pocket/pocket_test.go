@@ -0,0 +1,40 @@
+package pocket
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseRetrieveResponse(t *testing.T) {
+	result := parseRetrieveResponse([]byte(`{"status":1,"complete":1,"since":1617000000,"list":{
+		"2":{"item_id":"2","given_url":"https://example.com/b","resolved_url":"","status":"1","sort_id":1},
+		"1":{"item_id":"1","given_url":"https://example.com/a?utm_source=x","resolved_url":"https://example.com/a","status":"0","sort_id":0,
+			"given_title":"https://example.com/a?utm_source=x","resolved_title":"Article A","favorite":"1","time_added":"1616000000","excerpt":" summary ",
+			"tags":{"go":{"item_id":"1","tag":"go"},"web dev":{"item_id":"1","tag":"web dev"}}},
+		"3":{"item_id":"3","status":"2"}
+	}}`))
+	require.Equal(t, int64(1617000000), result.Since)
+	require.Len(t, result.Items, 3)
+
+	// sort_id 순으로, 지워진 항목은 url 이 없다
+	require.Equal(t, "1", result.Items[0].ItemID)
+	require.Equal(t, "https://example.com/a", result.Items[0].URL())
+	require.Equal(t, StatusUnread, result.Items[0].Status)
+	require.Equal(t, "", result.Items[0].GivenTitle)
+	require.Equal(t, "Article A", result.Items[0].Title())
+	require.Equal(t, []string{"go", "web dev"}, result.Items[0].Tags)
+	require.True(t, result.Items[0].Favorite)
+	require.Equal(t, int64(1616000000), result.Items[0].TimeAdded.Unix())
+	require.Equal(t, "summary", result.Items[0].Excerpt)
+	require.False(t, result.Items[1].Favorite)
+	require.Nil(t, result.Items[1].TimeAdded)
+	require.Equal(t, "https://example.com/b", result.Items[1].URL())
+	require.Equal(t, StatusArchived, result.Items[1].Status)
+	require.Equal(t, StatusDeleted, result.Items[2].Status)
+	require.Equal(t, "", result.Items[2].URL())
+
+	// 바뀐 항목이 없으면 list 가 빈 배열이다
+	result = parseRetrieveResponse([]byte(`{"status":2,"since":1617000100,"list":[]}`))
+	require.Equal(t, int64(1617000100), result.Since)
+	require.Empty(t, result.Items)
+}
@@ -0,0 +1,220 @@
+package sanitize
+
+import (
+	"bytes"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	netUrl "net/url"
+	"strings"
+)
+
+// 내용까지 통째로 버리는 element
+var droppedElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"object":   true,
+	"embed":    true,
+	"applet":   true,
+	"form":     true,
+	"template": true,
+	"noscript": true,
+	"svg":      true,
+	"math":     true,
+}
+
+// url 값을 가지는 attribute
+var urlAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"poster": true,
+	"cite":   true,
+}
+
+// Policy 는 허용할 element 와 attribute 목록이다. 허용되지 않은 element 는 벗겨내고 내용만 남긴다.
+type Policy struct {
+	elements map[string]map[string]bool
+	// iframe src 로 허용할 host, 하위 도메인도 허용된다. 비어있으면 iframe 을 허용하지 않는다.
+	iframeHosts []string
+}
+
+func NewPolicy() *Policy {
+	return &Policy{elements: map[string]map[string]bool{}}
+}
+
+// AllowElements 는 elements 를 attributes 와 함께 허용한다.
+func (p *Policy) AllowElements(attributes []string, elements ...string) *Policy {
+	for _, element := range elements {
+		if p.elements[element] == nil {
+			p.elements[element] = map[string]bool{}
+		}
+		for _, attribute := range attributes {
+			p.elements[element][attribute] = true
+		}
+	}
+	return p
+}
+
+// AllowIframes 는 src 가 hosts 중 하나인 iframe 을 허용한다.
+func (p *Policy) AllowIframes(hosts ...string) *Policy {
+	p.iframeHosts = append(p.iframeHosts, hosts...)
+	return p.AllowElements([]string{"src", "width", "height", "title", "allow", "allowfullscreen", "frameborder", "scrolling"}, "iframe")
+}
+
+// Sanitize 는 fragment 에서 허용되지 않은 element, attribute, url 을 모두 지운 html 을 돌려준다.
+func (p *Policy) Sanitize(fragment string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		for _, sanitized := range p.sanitizeNode(node) {
+			_ = html.Render(&buf, sanitized)
+		}
+	}
+	return buf.String()
+}
+
+func (p *Policy) sanitizeNode(node *html.Node) []*html.Node {
+	switch node.Type {
+	case html.TextNode:
+		return []*html.Node{{Type: html.TextNode, Data: node.Data}}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	tag := strings.ToLower(node.Data)
+	if droppedElements[tag] {
+		return nil
+	}
+
+	var children []*html.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		children = append(children, p.sanitizeNode(child)...)
+	}
+
+	allowed, ok := p.elements[tag]
+	if !ok || (tag == "iframe" && !p.isAllowedIframe(node)) {
+		// 벗겨내고 내용만 남긴다
+		return children
+	}
+
+	sanitized := &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag))}
+	for _, attr := range node.Attr {
+		key := strings.ToLower(attr.Key)
+		if attr.Namespace != "" || !allowed[key] {
+			continue
+		}
+		value := attr.Val
+		if urlAttributes[key] {
+			var ok bool
+			if value, ok = safeURL(value); !ok {
+				continue
+			}
+		}
+		sanitized.Attr = append(sanitized.Attr, html.Attribute{Key: key, Val: value})
+	}
+	for _, child := range children {
+		sanitized.AppendChild(child)
+	}
+	return []*html.Node{sanitized}
+}
+
+func (p *Policy) isAllowedIframe(node *html.Node) bool {
+	for _, attr := range node.Attr {
+		if strings.ToLower(attr.Key) != "src" {
+			continue
+		}
+		src, ok := safeURL(attr.Val)
+		if !ok {
+			return false
+		}
+		u, err := netUrl.Parse(src)
+		if err != nil {
+			return false
+		}
+		return hostMatches(u.Hostname(), p.iframeHosts)
+	}
+	return false
+}
+
+// safeURL 은 http(s) 절대 주소만 허용한다. `//host/path` 형태는 https 로 바꾼다.
+func safeURL(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "//") {
+		value = "https:" + value
+	}
+
+	u, err := netUrl.Parse(value)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return "", false
+	}
+	return u.String(), true
+}
+
+func hostMatches(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, h := range allowed {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// Text 는 fragment 의 텍스트만 남긴다. 블럭 element 사이는 줄바꿈으로 구분한다.
+func Text(fragment string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return ""
+	}
+
+	var buf strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			buf.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if droppedElements[node.Data] {
+				return
+			}
+			if node.Data == "br" {
+				buf.WriteString("\n")
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if node.Type == html.ElementNode && isBlock(node.Data) {
+			buf.WriteString("\n\n")
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func isBlock(tag string) bool {
+	switch tag {
+	case "p", "div", "blockquote", "li", "ul", "ol", "h1", "h2", "h3", "h4", "h5", "h6", "pre", "table", "tr":
+		return true
+	}
+	return false
+}
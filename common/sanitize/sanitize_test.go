@@ -0,0 +1,37 @@
+package sanitize
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	policy := NewPolicy().
+		AllowElements([]string{"href"}, "a").
+		AllowElements(nil, "p", "strong").
+		AllowIframes("slideshare.net")
+
+	cases := []struct {
+		fragment string
+		expected string
+	}{
+		{
+			`<iframe src="//www.slideshare.net/slideshow/embed_code/key/abc" width="800" height="600" onload="alert(1)"></iframe>`,
+			`<iframe src="https://www.slideshare.net/slideshow/embed_code/key/abc" width="800" height="600"></iframe>`,
+		},
+		{`<iframe src="https://evil.example.com/slideshare.net"></iframe>`, ``},
+		{`<iframe src="https://slideshare.net.evil.com/"></iframe>`, ``},
+		{`<p>hello<script>alert(1)</script> <strong style="x">world</strong></p>`, `<p>hello <strong>world</strong></p>`},
+		{`<a href="javascript:alert(1)">click</a>`, `<a>click</a>`},
+		{`<a href="https://example.com/?a=1&b=2" onclick="x()">link</a>`, `<a href="https://example.com/?a=1&amp;b=2">link</a>`},
+		{`<div><span>1 &lt; 2</span></div>`, `1 &lt; 2`},
+		{`<svg><script>alert(1)</script></svg><img src=x onerror=alert(1)>`, ``},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expected, policy.Sanitize(c.fragment), c.fragment)
+	}
+}
+
+func TestText(t *testing.T) {
+	require.Equal(t, "first\n\nsecond\nline", Text(`<p>first</p><p>second<br>line<script>x</script></p>`))
+}
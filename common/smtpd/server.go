@@ -0,0 +1,263 @@
+package smtpd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSize     = 25 * 1024 * 1024
+	defaultReadTimeout = 5 * time.Minute
+)
+
+var (
+	mailFromRegexp = regexp.MustCompile(`(?i)^FROM:\s*<([^>]*)>`)
+	rcptToRegexp   = regexp.MustCompile(`(?i)^TO:\s*<([^>]+)>`)
+)
+
+// Handler 는 받은 메일 하나를 처리한다. 에러를 돌려주면 클라이언트에게 메일을 거절한다고 응답한다.
+type Handler func(from string, to []string, data []byte) error
+
+// Server 는 메일을 받기만 하는 작은 SMTP 서버다. 인증과 TLS 는 지원하지 않으므로 믿을 수 있는 네트워크에서만 쓴다.
+type Server struct {
+	Addr string
+	// 인사말과 EHLO 응답에 쓰는 호스트 이름
+	Domain string
+	// RCPT 로 받은 주소가 받을 주소인지, nil 이면 모두 받는다
+	AcceptRecipient func(address string) bool
+	Handler         Handler
+	// 메일 하나의 최대 크기, 0 이면 25MB
+	MaxSize int64
+	// 명령 하나를 기다리는 시간, 0 이면 5분
+	ReadTimeout time.Duration
+
+	mutex    sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen %s", s.Addr)
+	}
+	return s.Serve(listener)
+}
+
+// Serve 는 Close 될 때까지 listener 로 들어오는 연결을 처리한다.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	s.listener = listener
+	s.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return nil
+			}
+			return errors.Wrap(err, "failed to accept")
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// session 은 연결 하나에서 진행 중인 메일 transaction 의 상태다.
+type session struct {
+	server *Server
+	conn   net.Conn
+	text   *textproto.Conn
+	from   *string
+	to     []string
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	ss := &session{server: s, conn: conn, text: textproto.NewConn(conn)}
+	if err := ss.serve(); err != nil && err != io.EOF {
+		logrus.Warnf("smtp session from %s closed: %s", conn.RemoteAddr(), err.Error())
+	}
+}
+
+func (ss *session) serve() error {
+	if err := ss.reply(220, "%s ESMTP personal-archive", ss.server.domain()); err != nil {
+		return err
+	}
+
+	for {
+		_ = ss.conn.SetReadDeadline(time.Now().Add(ss.server.readTimeout()))
+		line, err := ss.text.ReadLine()
+		if err != nil {
+			return err
+		}
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			ss.reset()
+			err = ss.reply(250, "%s", ss.server.domain())
+		case "EHLO":
+			ss.reset()
+			err = ss.replyLines(250, ss.server.domain(), "8BITMIME", fmt.Sprintf("SIZE %d", ss.server.maxSize()))
+		case "MAIL":
+			err = ss.mail(arg)
+		case "RCPT":
+			err = ss.rcpt(arg)
+		case "DATA":
+			err = ss.data()
+		case "RSET":
+			ss.reset()
+			err = ss.reply(250, "OK")
+		case "NOOP":
+			err = ss.reply(250, "OK")
+		case "VRFY":
+			err = ss.reply(252, "cannot verify user")
+		case "QUIT":
+			_ = ss.reply(221, "bye")
+			return nil
+		default:
+			err = ss.reply(502, "command not implemented")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (ss *session) mail(arg string) error {
+	if ss.from != nil {
+		return ss.reply(503, "nested MAIL command")
+	}
+	matches := mailFromRegexp.FindStringSubmatch(arg)
+	if matches == nil {
+		return ss.reply(501, "syntax: MAIL FROM:<address>")
+	}
+
+	from := matches[1]
+	ss.from = &from
+	return ss.reply(250, "OK")
+}
+
+func (ss *session) rcpt(arg string) error {
+	if ss.from == nil {
+		return ss.reply(503, "need MAIL before RCPT")
+	}
+	matches := rcptToRegexp.FindStringSubmatch(arg)
+	if matches == nil {
+		return ss.reply(501, "syntax: RCPT TO:<address>")
+	}
+
+	to := matches[1]
+	if accept := ss.server.AcceptRecipient; accept != nil && !accept(to) {
+		return ss.reply(550, "no such user: %s", to)
+	}
+	ss.to = append(ss.to, to)
+	return ss.reply(250, "OK")
+}
+
+func (ss *session) data() error {
+	if ss.from == nil || len(ss.to) == 0 {
+		return ss.reply(503, "need MAIL and RCPT before DATA")
+	}
+	if err := ss.reply(354, "end data with <CR><LF>.<CR><LF>"); err != nil {
+		return err
+	}
+
+	// DotReader 가 줄바꿈을 \n 으로 바꿔준다. 최대 크기를 넘으면 끝까지 읽어 버린 뒤 거절한다
+	maxSize := ss.server.maxSize()
+	_ = ss.conn.SetReadDeadline(time.Now().Add(ss.server.readTimeout()))
+	reader := ss.text.DotReader()
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > maxSize {
+		if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+			return err
+		}
+		ss.reset()
+		return ss.reply(552, "message exceeds %d bytes", maxSize)
+	}
+
+	from, to := *ss.from, ss.to
+	ss.reset()
+	if err := ss.server.Handler(from, to, data); err != nil {
+		logrus.Warnf("rejected mail from %s: %s", from, err.Error())
+		return ss.reply(554, "rejected: %s", err.Error())
+	}
+	return ss.reply(250, "OK")
+}
+
+func (ss *session) reset() {
+	ss.from = nil
+	ss.to = nil
+}
+
+func (ss *session) reply(code int, format string, args ...interface{}) error {
+	// 에러 메시지가 응답을 여러 줄로 나누지 않도록 한다
+	message := strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf(format, args...))
+	return ss.text.PrintfLine("%d %s", code, message)
+}
+
+// replyLines 는 여러 줄 응답을 보낸다.
+func (ss *session) replyLines(code int, lines ...string) error {
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		if err := ss.text.PrintfLine("%d%s%s", code, separator, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) domain() string {
+	if s.Domain != "" {
+		return s.Domain
+	}
+	return "localhost"
+}
+
+func (s *Server) maxSize() int64 {
+	if s.MaxSize > 0 {
+		return s.MaxSize
+	}
+	return defaultMaxSize
+}
+
+func (s *Server) readTimeout() time.Duration {
+	if s.ReadTimeout > 0 {
+		return s.ReadTimeout
+	}
+	return defaultReadTimeout
+}
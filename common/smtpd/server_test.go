@@ -0,0 +1,48 @@
+package smtpd
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	var received []string
+	server := &Server{
+		AcceptRecipient: func(address string) bool { return address == "archive@localhost" },
+		Handler: func(from string, to []string, data []byte) error {
+			if strings.Contains(string(data), "reject me") {
+				return fmt.Errorf("rejected")
+			}
+			received = append(received, from+" "+strings.Join(to, ",")+" "+string(data))
+			return nil
+		},
+		MaxSize: 1024,
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+	addr := listener.Addr().String()
+
+	// case 1: 받는 메일
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"archive@localhost"}, []byte("Subject: hi\r\n\r\nhello\r\n.dot\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"news@example.com archive@localhost Subject: hi\n\nhello\n.dot\n"}, received)
+
+	// case 2: 받지 않는 주소
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"other@localhost"}, []byte("Subject: hi\r\n\r\nhello\r\n"))
+	require.Error(t, err)
+
+	// case 3: handler 가 거절한 메일
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"archive@localhost"}, []byte("Subject: hi\r\n\r\nreject me\r\n"))
+	require.Error(t, err)
+
+	// case 4: 너무 큰 메일
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"archive@localhost"}, []byte("Subject: hi\r\n\r\n"+strings.Repeat("a", 2048)+"\r\n"))
+	require.Error(t, err)
+	require.Len(t, received, 1)
+}
@@ -0,0 +1,140 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+const (
+	ContentType = "application/warc"
+	version     = "WARC/1.0"
+)
+
+type Record struct {
+	TargetURI string
+	Date      time.Time
+	Response  *http.Response
+	Body      []byte
+}
+
+// WriteResponse 는 http 응답 하나를 WARC `response` 레코드로 기록한다.
+func WriteResponse(w io.Writer, targetURI string, date time.Time, statusCode int, header http.Header, body []byte) error {
+	// body 는 이미 디코딩된 상태이므로 전송 관련 헤더는 실제 body 에 맞춘다
+	header = header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Del("Content-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+	if err := header.Write(&block); err != nil {
+		return errors.Wrap(err, "failed to write http header")
+	}
+	block.WriteString("\r\n")
+	block.Write(body)
+
+	recordID, err := newRecordID()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate record id")
+	}
+
+	warcHeader := []string{
+		version,
+		"WARC-Type: response",
+		"WARC-Record-ID: " + recordID,
+		"WARC-Date: " + date.UTC().Format(time.RFC3339),
+		"WARC-Target-URI: " + targetURI,
+		"WARC-Payload-Digest: " + digest(body),
+		"WARC-Block-Digest: " + digest(block.Bytes()),
+		"Content-Type: application/http; msgtype=response",
+		"Content-Length: " + strconv.Itoa(block.Len()),
+	}
+	for _, line := range warcHeader {
+		if _, err := io.WriteString(w, line+"\r\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "\r\n"); err != nil {
+		return err
+	}
+	if _, err := w.Write(block.Bytes()); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\r\n\r\n")
+	return err
+}
+
+// ReadResponse 는 WriteResponse 로 기록한 첫번째 `response` 레코드를 읽는다.
+func ReadResponse(r io.Reader) (*Record, error) {
+	reader := textproto.NewReader(bufio.NewReader(r))
+
+	line, err := reader.ReadLine()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read version line")
+	} else if line != version {
+		return nil, fmt.Errorf("unsupported warc version: %s", line)
+	}
+
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read warc header")
+	}
+	if recordType := header.Get("WARC-Type"); recordType != "response" {
+		return nil, fmt.Errorf("unsupported warc record type: %s", recordType)
+	}
+
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid content length")
+	}
+
+	block := make([]byte, length)
+	if _, err := io.ReadFull(reader.R, block); err != nil {
+		return nil, errors.Wrap(err, "failed to read warc block")
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse http response")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read http body")
+	}
+
+	date, _ := time.Parse(time.RFC3339, header.Get("WARC-Date"))
+	return &Record{
+		TargetURI: header.Get("WARC-Target-URI"),
+		Date:      date,
+		Response:  resp,
+		Body:      body,
+	}, nil
+}
+
+func digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+func newRecordID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
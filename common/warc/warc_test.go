@@ -0,0 +1,30 @@
+package warc
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAndReadResponse(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	body := []byte("<html><body>hello</body></html>")
+	date := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+	err := WriteResponse(&buf, "https://example.com/a", date, http.StatusOK, header, body)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(buf.String(), "WARC/1.0\r\nWARC-Type: response\r\n"))
+
+	record, err := ReadResponse(&buf)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/a", record.TargetURI)
+	require.Equal(t, date, record.Date)
+	require.Equal(t, http.StatusOK, record.Response.StatusCode)
+	require.Equal(t, "text/html; charset=utf-8", record.Response.Header.Get("Content-Type"))
+	require.Equal(t, body, record.Body)
+}
@@ -1,34 +1,50 @@
 package controllers
 
 import (
+	"fmt"
 	"github.com/jaeyo/personal-archive/common/http"
 	"github.com/jaeyo/personal-archive/controllers/reqres"
 	"github.com/jaeyo/personal-archive/models"
 	"github.com/jaeyo/personal-archive/repositories"
 	"github.com/jaeyo/personal-archive/services"
+	"github.com/jaeyo/personal-archive/services/generators"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	netHttp "net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ArticleController struct {
 	articleService    services.ArticleService
+	ingestionService  services.IngestionService
 	articleRepository repositories.ArticleRepository
 }
 
 func NewArticleController() *ArticleController {
 	return &ArticleController{
 		articleService:    services.GetArticleService(),
+		ingestionService:  services.GetIngestionService(),
 		articleRepository: repositories.GetArticleRepository(),
 	}
 }
 
 func (c *ArticleController) Route(e *echo.Echo) {
 	e.POST("/apis/articles", http.Provide(c.CreateArticleByURL))
+	e.POST("/apis/articles/files", http.Provide(c.CreateArticlesByFile))
 	e.GET("/apis/articles/:id", http.Provide(c.GetArticle))
 	e.PUT("/apis/articles/:id/title", http.Provide(c.UpdateTitle))
 	e.PUT("/apis/articles/:id/tags", http.Provide(c.UpdateTags))
 	e.PUT("/apis/articles/:id/content", http.Provide(c.UpdateContent))
+	e.GET("/apis/articles/:id/snapshot", http.Provide(c.GetSnapshot))
+	e.POST("/apis/articles/:id/snapshot/extract", http.Provide(c.ReExtract))
+	e.POST("/apis/articles/:id/refetch", http.Provide(c.Refetch))
+	e.GET("/apis/articles/:id/revisions", http.Provide(c.FindRevisions))
+	e.GET("/apis/articles/:id/revisions/diff", http.Provide(c.DiffRevisions))
+	e.GET("/apis/articles/:id/revisions/:revisionID", http.Provide(c.GetRevision))
+	e.POST("/apis/articles/:id/revisions/:revisionID/restore", http.Provide(c.RestoreRevision))
 	e.GET("/apis/articles/tags/:tag", http.Provide(c.FindArticlesByTag))
 	e.GET("/apis/articles/search", http.Provide(c.SearchArticle))
 	e.DELETE("/apis/articles/:id", http.Provide(c.DeleteArticle))
@@ -43,14 +59,54 @@ func (c *ArticleController) CreateArticleByURL(ctx http.ContextExtended) error {
 		return ctx.BadRequestf("invalid request body: %s", err.Error())
 	}
 
-	article, err := c.articleService.CreateByURL(req.URL, req.Tags)
+	// 내려받기는 오래 걸리므로 queue 에 넣고 바로 응답한다. 진행 상황은 `/apis/ingestion-jobs/:id` 로 확인한다
+	job, err := c.ingestionService.Enqueue(req.URL, req.Tags, models.IngestionSourceAPI)
 	if err != nil {
-		return ctx.InternalServerError(err, "failed to create article by url")
+		return ctx.InternalServerError(err, "failed to enqueue article url")
 	}
 
-	return ctx.Success(reqres.ArticleResponse{
-		OK:      true,
-		Article: article,
+	return ctx.Success(reqres.IngestionJobResponse{
+		OK:  true,
+		Job: job,
+	})
+}
+
+// CreateArticlesByFile 은 file 필드로 올린 파일들을 article 로 만들며, `?split=chapter` 이면 epub 을 chapter 마다 나눈다.
+func (c *ArticleController) CreateArticlesByFile(ctx http.ContextExtended) error {
+	tags, err := importTags(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid tags: %s", err.Error())
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	} else if len(form.File["file"]) == 0 {
+		return ctx.BadRequestf("file required")
+	}
+
+	splitChapters := ctx.QueryParam("split") == "chapter"
+	var articles []*models.Article
+	for _, fileHeader := range form.File["file"] {
+		data, err := readFormFile(fileHeader)
+		if err != nil {
+			return readFileError(ctx, fmt.Sprintf("failed to read %s", fileHeader.Filename), err)
+		}
+
+		if _, err := generators.DetectFileType(fileHeader.Filename, data); err != nil {
+			return ctx.BadRequestf("invalid file %s: %s", fileHeader.Filename, err.Error())
+		}
+
+		created, err := c.articleService.CreateByFile(fileHeader.Filename, data, tags, splitChapters)
+		if err != nil {
+			return ctx.InternalServerError(err, fmt.Sprintf("failed to create article from %s", fileHeader.Filename))
+		}
+		articles = append(articles, created...)
+	}
+
+	return ctx.Success(reqres.ArticlesResponse{
+		OK:       true,
+		Articles: articles,
 	})
 }
 
@@ -130,28 +186,193 @@ func (c *ArticleController) UpdateContent(ctx http.ContextExtended) error {
 	return ctx.Success(http.SuccessResponse{OK: true})
 }
 
+func (c *ArticleController) GetSnapshot(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	raw, err := c.articleService.GetSnapshot(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get snapshot: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to get snapshot")
+	}
+
+	// 원본 페이지의 스크립트가 archive 의 origin 에서 실행되지 않도록 sandbox 로 격리한다
+	ctx.Response().Header().Set("Content-Security-Policy", "sandbox")
+	return ctx.Blob(netHttp.StatusOK, raw.ContentType(), raw.Body)
+}
+
+func (c *ArticleController) ReExtract(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	article, err := c.articleService.ReExtract(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to re-extract article: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to re-extract article")
+	}
+
+	return ctx.Success(reqres.ArticleResponse{
+		OK:      true,
+		Article: article,
+	})
+}
+
+func (c *ArticleController) Refetch(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	article, err := c.articleService.Refetch(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to refetch article: %s", err.Error())
+		} else if errors.Is(err, services.ErrNotFetchable) {
+			return ctx.BadRequestf("failed to refetch article: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to refetch article")
+	}
+
+	return ctx.Success(reqres.ArticleResponse{
+		OK:      true,
+		Article: article,
+	})
+}
+
+func (c *ArticleController) FindRevisions(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	revisions, err := c.articleService.FindRevisions(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to find revisions: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to find revisions")
+	}
+
+	return ctx.Success(reqres.ArticleRevisionsResponse{
+		OK:        true,
+		Revisions: revisions,
+	})
+}
+
+func (c *ArticleController) GetRevision(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+	revisionID, err := ctx.ParamInt64("revisionID")
+	if err != nil {
+		return ctx.BadRequest("invalid revision id")
+	}
+
+	revision, err := c.articleService.GetRevision(id, revisionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get revision: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to get revision")
+	}
+
+	return ctx.Success(reqres.ArticleRevisionResponse{
+		OK:       true,
+		Revision: revision,
+	})
+}
+
+// DiffRevisions 는 `?from=1&to=2` 두 revision 사이의 unified diff 를 돌려준다
+func (c *ArticleController) DiffRevisions(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+	from, err := strconv.ParseInt(ctx.QueryParam("from"), 10, 64)
+	if err != nil {
+		return ctx.BadRequest("invalid from")
+	}
+	to, err := strconv.ParseInt(ctx.QueryParam("to"), 10, 64)
+	if err != nil {
+		return ctx.BadRequest("invalid to")
+	}
+
+	diff, err := c.articleService.DiffRevisions(id, from, to)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to diff revisions: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to diff revisions")
+	}
+
+	return ctx.Success(reqres.ArticleRevisionDiffResponse{
+		OK:   true,
+		From: from,
+		To:   to,
+		Diff: diff,
+	})
+}
+
+func (c *ArticleController) RestoreRevision(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+	revisionID, err := ctx.ParamInt64("revisionID")
+	if err != nil {
+		return ctx.BadRequest("invalid revision id")
+	}
+
+	article, err := c.articleService.RestoreRevision(id, revisionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to restore revision: %s", err.Error())
+		} else if errors.Is(err, services.ErrTitleExists) {
+			return ctx.Conflictf("failed to restore revision: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to restore revision")
+	}
+
+	return ctx.Success(reqres.ArticleResponse{
+		OK:      true,
+		Article: article,
+	})
+}
+
 func (c *ArticleController) FindArticlesByTag(ctx http.ContextExtended) error {
 	tag := ctx.ParamStr("tag")
 	page, offset, limit := ctx.PageOffsetLimit()
+	query, err := parseArticleQuery(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid query: %s", err.Error())
+	}
 
 	var (
 		articles []*models.Article
 		cnt int64
-		err error
 	)
 
 	if tag == "untagged" {
-		articles, cnt, err = c.articleRepository.FindUntaggedWithPage(offset, limit)
+		articles, cnt, err = c.articleRepository.FindUntaggedWithPage(query, offset, limit)
 		if err != nil {
 			return ctx.InternalServerError(err, "failed to find untagged articles")
 		}
 	} else if tag == "all" {
-		articles, cnt, err = c.articleRepository.FindAllWithPage(offset, limit)
+		articles, cnt, err = c.articleRepository.FindAllWithPage(query, offset, limit)
 		if err != nil {
 			return ctx.InternalServerError(err, "failed to find all articles")
 		}
 	} else {
-		articles, cnt, err = c.articleRepository.FindByTagWithPage(tag, offset, limit)
+		articles, cnt, err = c.articleRepository.FindByTagWithPage(tag, query, offset, limit)
 		if err != nil {
 			return ctx.InternalServerError(err, "failed to find articles by tag")
 		}
@@ -170,8 +391,12 @@ func (c *ArticleController) SearchArticle(ctx http.ContextExtended) error {
 		return ctx.BadRequest("keyword should be more than 2 characters")
 	}
 	page, offset, limit := ctx.PageOffsetLimit()
+	query, err := parseArticleQuery(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid query: %s", err.Error())
+	}
 
-	articles, cnt, err := c.articleService.Search(keyword, offset, limit)
+	articles, cnt, err := c.articleService.Search(keyword, query, offset, limit)
 	if err != nil {
 		return ctx.InternalServerError(err, "failed to search")
 	}
@@ -207,4 +432,79 @@ func (c *ArticleController) DeleteArticles(ctx http.ContextExtended) error {
 	}
 
 	return ctx.Success(http.SuccessResponse{OK: true})
-}
\ No newline at end of file
+}
+
+// parseArticleQuery 는 목록 조회의 정렬, 필터 조건을 query param 으로부터 읽는다.
+// e.g. `?sort=published&order=asc&site=example.com&publishedFrom=2021-01-01&maxReadingTime=10&linkStatus=gone,changed`
+func parseArticleQuery(ctx http.ContextExtended) (*repositories.ArticleQuery, error) {
+	query := &repositories.ArticleQuery{
+		Sort:     ctx.QueryParam("sort"),
+		Author:   ctx.QueryParam("author"),
+		SiteName: ctx.QueryParam("site"),
+		Language: ctx.QueryParam("language"),
+	}
+
+	if !repositories.IsValidArticleSort(query.Sort) {
+		return nil, fmt.Errorf("invalid sort: %s", query.Sort)
+	}
+
+	switch order := ctx.QueryParam("order"); order {
+	case "", "desc":
+	case "asc":
+		query.Asc = true
+	default:
+		return nil, fmt.Errorf("invalid order: %s", order)
+	}
+
+	for name, dst := range map[string]**time.Time{"publishedFrom": &query.PublishedFrom, "publishedTo": &query.PublishedTo} {
+		value := ctx.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", name, value)
+		}
+		*dst = &date
+	}
+	if query.PublishedTo != nil {
+		// publishedTo 일자를 포함한다
+		to := query.PublishedTo.AddDate(0, 0, 1)
+		query.PublishedTo = &to
+	}
+
+	for name, dst := range map[string]*int{"minReadingTime": &query.MinReadingTime, "maxReadingTime": &query.MaxReadingTime} {
+		value := ctx.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 0 {
+			return nil, fmt.Errorf("invalid %s: %s", name, value)
+		}
+		*dst = minutes
+	}
+
+	if linkStatus := ctx.QueryParam("linkStatus"); linkStatus != "" {
+		for _, status := range strings.Split(linkStatus, ",") {
+			if !models.IsValidLinkStatus(status) {
+				return nil, fmt.Errorf("invalid linkStatus: %s", status)
+			}
+			query.LinkStatuses = append(query.LinkStatuses, status)
+		}
+	}
+
+	for name, dst := range map[string]**bool{"favorite": &query.Favorite, "archived": &query.Archived} {
+		value := ctx.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", name, value)
+		}
+		*dst = &flag
+	}
+
+	return query, nil
+}
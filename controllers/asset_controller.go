@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/services"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	netHttp "net/http"
+)
+
+type AssetController struct {
+	assetService services.AssetService
+}
+
+func NewAssetController() *AssetController {
+	return &AssetController{
+		assetService: services.GetAssetService(),
+	}
+}
+
+func (c *AssetController) Route(e *echo.Echo) {
+	e.GET("/apis/assets/:hash", http.Provide(c.GetAsset))
+}
+
+func (c *AssetController) GetAsset(ctx http.ContextExtended) error {
+	hash := ctx.Param("hash")
+
+	asset, f, err := c.assetService.Open(hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get asset: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to get asset")
+	}
+	defer f.Close()
+
+	// 내용 기반 이름이므로 같은 주소의 내용은 바뀌지 않는다
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	// snapshot 처럼 html 인 asset 이 archive 의 origin 에서 스크립트를 실행하지 못하도록 한다
+	ctx.Response().Header().Set("Content-Security-Policy", "sandbox")
+	return ctx.Stream(netHttp.StatusOK, asset.ContentType, f)
+}
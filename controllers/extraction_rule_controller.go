@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/controllers/reqres"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type ExtractionRuleController struct {
+	extractionRuleService    services.ExtractionRuleService
+	extractionRuleRepository repositories.ExtractionRuleRepository
+}
+
+func NewExtractionRuleController() *ExtractionRuleController {
+	return &ExtractionRuleController{
+		extractionRuleService:    services.GetExtractionRuleService(),
+		extractionRuleRepository: repositories.GetExtractionRuleRepository(),
+	}
+}
+
+func (c *ExtractionRuleController) Route(e *echo.Echo) {
+	e.GET("/apis/settings/extraction-rules", http.Provide(c.FindExtractionRules))
+	e.POST("/apis/settings/extraction-rules", http.Provide(c.CreateExtractionRule))
+	e.POST("/apis/settings/extraction-rules/dry-run", http.Provide(c.DryRun))
+	e.GET("/apis/settings/extraction-rules/:id", http.Provide(c.GetExtractionRule))
+	e.PUT("/apis/settings/extraction-rules/:id", http.Provide(c.UpdateExtractionRule))
+	e.DELETE("/apis/settings/extraction-rules/:id", http.Provide(c.DeleteExtractionRule))
+}
+
+func (c *ExtractionRuleController) FindExtractionRules(ctx http.ContextExtended) error {
+	rules, err := c.extractionRuleRepository.FindAll()
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to find extraction rules")
+	}
+
+	return ctx.Success(reqres.ExtractionRulesResponse{
+		OK:              true,
+		ExtractionRules: rules,
+	})
+}
+
+func (c *ExtractionRuleController) GetExtractionRule(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	rule, err := c.extractionRuleRepository.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get extraction rule: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to get extraction rule")
+	}
+
+	return ctx.Success(reqres.ExtractionRuleResponse{
+		OK:             true,
+		ExtractionRule: rule,
+	})
+}
+
+func (c *ExtractionRuleController) CreateExtractionRule(ctx http.ContextExtended) error {
+	var req reqres.ExtractionRuleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	rule := req.ToModel()
+	if err := c.extractionRuleService.Create(rule); err != nil {
+		return extractionRuleError(ctx, err, "failed to create extraction rule")
+	}
+
+	return ctx.Success(reqres.ExtractionRuleResponse{
+		OK:             true,
+		ExtractionRule: rule,
+	})
+}
+
+func (c *ExtractionRuleController) UpdateExtractionRule(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	var req reqres.ExtractionRuleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	if err := c.extractionRuleService.Update(id, req.ToModel()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get extraction rule: %s", err.Error())
+		}
+		return extractionRuleError(ctx, err, "failed to update extraction rule")
+	}
+
+	return ctx.Success(http.SuccessResponse{OK: true})
+}
+
+func (c *ExtractionRuleController) DeleteExtractionRule(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	if err := c.extractionRuleService.DeleteByIDs([]int64{id}); err != nil {
+		return ctx.InternalServerError(err, "failed to delete extraction rule")
+	}
+
+	return ctx.Success(http.SuccessResponse{OK: true})
+}
+
+func (c *ExtractionRuleController) DryRun(ctx http.ContextExtended) error {
+	var req reqres.DryRunExtractionRuleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	var rule *models.ExtractionRule
+	if req.Rule != nil {
+		rule = req.Rule.ToModel()
+	}
+
+	fetched, err := c.extractionRuleService.DryRun(req.URL, rule)
+	if err != nil {
+		return extractionRuleError(ctx, err, "failed to dry run extraction rule")
+	}
+
+	return ctx.Success(reqres.DryRunExtractionRuleResponse{
+		OK:        true,
+		Title:     fetched.Title,
+		Author:    fetched.Author,
+		Content:   fetched.Content,
+		Extractor: fetched.Extractor,
+	})
+}
+
+// extractionRuleError 는 잘못된 rule 은 400, 이미 있는 domain 의 rule 은 409 로 돌려준다.
+func extractionRuleError(ctx http.ContextExtended, err error, message string) error {
+	if errors.Is(err, services.ErrInvalidExtractionRule) {
+		return ctx.BadRequestf("%s: %s", message, err.Error())
+	} else if errors.Is(err, services.ErrExtractionRuleExists) {
+		return ctx.Conflictf("%s: %s", message, err.Error())
+	}
+	return ctx.InternalServerError(err, message)
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/controllers/reqres"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"io"
+	"io/ioutil"
+	netHttp "net/http"
+)
+
+type FeedController struct {
+	feedService    services.FeedService
+	feedRepository repositories.FeedRepository
+}
+
+func NewFeedController() *FeedController {
+	return &FeedController{
+		feedService:    services.GetFeedService(),
+		feedRepository: repositories.GetFeedRepository(),
+	}
+}
+
+func (c *FeedController) Route(e *echo.Echo) {
+	e.GET("/apis/feeds", http.Provide(c.FindFeeds))
+	e.POST("/apis/feeds", http.Provide(c.CreateFeed))
+	e.PUT("/apis/feeds/:id", http.Provide(c.UpdateFeed))
+	e.DELETE("/apis/feeds/:id", http.Provide(c.DeleteFeed))
+	e.POST("/apis/feeds/opml", http.Provide(c.ImportOPML))
+	e.GET("/apis/feeds/opml", http.Provide(c.ExportOPML))
+}
+
+func (c *FeedController) FindFeeds(ctx http.ContextExtended) error {
+	feeds, err := c.feedRepository.FindAll()
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to find feeds")
+	}
+
+	return ctx.Success(reqres.FeedsResponse{
+		OK:    true,
+		Feeds: feeds,
+	})
+}
+
+func (c *FeedController) CreateFeed(ctx http.ContextExtended) error {
+	var req reqres.CreateFeedRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	} else if err = req.Validate(); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	feed, err := c.feedService.Create(req.URL, req.Tags)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to create feed")
+	}
+
+	return ctx.Success(reqres.FeedResponse{
+		OK:   true,
+		Feed: feed,
+	})
+}
+
+func (c *FeedController) UpdateFeed(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	var req reqres.UpdateFeedRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	} else if err = req.Validate(); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	if err := c.feedService.Update(id, req.Title, req.Tags); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get feed: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to update feed")
+	}
+
+	return ctx.Success(http.SuccessResponse{OK: true})
+}
+
+func (c *FeedController) DeleteFeed(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	if err := c.feedService.DeleteByIDs([]int64{id}); err != nil {
+		return ctx.InternalServerError(err, "failed to delete feed")
+	}
+
+	return ctx.Success(http.SuccessResponse{OK: true})
+}
+
+func (c *FeedController) ImportOPML(ctx http.ContextExtended) error {
+	// multipart 의 file 필드 또는 요청 body 자체를 OPML 로 받는다
+	var r io.Reader = ctx.Request().Body
+	if fileHeader, err := ctx.FormFile("file"); err == nil {
+		f, err := fileHeader.Open()
+		if err != nil {
+			return ctx.BadRequestf("invalid file: %s", err.Error())
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	imported, err := c.feedService.ImportOPML(data)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to import opml")
+	}
+
+	return ctx.Success(reqres.ImportOPMLResponse{
+		OK:       true,
+		Imported: imported,
+	})
+}
+
+func (c *FeedController) ExportOPML(ctx http.ContextExtended) error {
+	data, err := c.feedService.ExportOPML()
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to export opml")
+	}
+
+	ctx.Response().Header().Set("Content-Disposition", `attachment; filename="feeds.opml"`)
+	return ctx.Blob(netHttp.StatusOK, "text/x-opml; charset=utf-8", data)
+}
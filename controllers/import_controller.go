@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"bytes"
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/common/imports"
+	"github.com/jaeyo/personal-archive/controllers/reqres"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/services"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+// 올리는 파일 하나의 최대 크기
+const uploadMaxSize = 50 * 1024 * 1024
+
+var errFileTooLarge = errors.New("file too large")
+
+type ImportController struct {
+	importService services.ImportService
+}
+
+func NewImportController() *ImportController {
+	return &ImportController{
+		importService: services.GetImportService(),
+	}
+}
+
+func (c *ImportController) Route(e *echo.Echo) {
+	e.POST("/apis/imports/urls", http.Provide(c.ImportURLs))
+	e.POST("/apis/imports/bookmarks", http.Provide(c.ImportBookmarks))
+	e.POST("/apis/imports/notes", http.Provide(c.ImportNotes))
+	e.POST("/apis/imports/kindle", http.Provide(c.ImportKindle))
+	e.POST("/apis/imports/:source", http.Provide(c.ImportExport))
+}
+
+// ImportURLs 는 url 목록을 queue 에 넣는다.
+// 형식은 `?format=text|csv|json`, 파일 확장자, Content-Type, 내용 순으로 정한다. `?tags=a,b` 는 모든 url 에 붙는다.
+func (c *ImportController) ImportURLs(ctx http.ContextExtended) error {
+	tags, err := importTags(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid tags: %s", err.Error())
+	}
+
+	file, err := readImportFile(ctx)
+	if err != nil {
+		return readFileError(ctx, "invalid request body", err)
+	}
+
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = urlListFormatOf(file.ext, file.contentType)
+	}
+
+	items, err := imports.ParseURLList(file.data, format)
+	if err != nil {
+		return ctx.BadRequestf("invalid url list: %s", err.Error())
+	}
+
+	report, err := c.importService.ImportURLs(items, tags, true)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to import urls")
+	}
+
+	return ctx.Success(reqres.ImportReportResponse{
+		OK:     true,
+		Report: report,
+	})
+}
+
+// ImportBookmarks 는 브라우저에서 내보낸 bookmark 파일을 가져온다. 폴더 이름이 tag 가 된다.
+// `?fetch=true` 이면 본문을 내려받고, 아니면 제목과 url 만 가진 article 을 만든다.
+func (c *ImportController) ImportBookmarks(ctx http.ContextExtended) error {
+	tags, err := importTags(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid tags: %s", err.Error())
+	}
+
+	file, err := readImportFile(ctx)
+	if err != nil {
+		return readFileError(ctx, "invalid request body", err)
+	}
+
+	items, err := imports.ParseNetscapeBookmarks(file.data)
+	if err != nil {
+		return ctx.BadRequestf("invalid bookmark file: %s", err.Error())
+	}
+
+	report, err := c.importService.ImportURLs(items, tags, ctx.QueryParam("fetch") == "true")
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to import bookmarks")
+	}
+
+	return ctx.Success(reqres.ImportReportResponse{
+		OK:     true,
+		Report: report,
+	})
+}
+
+// ImportNotes 는 Obsidian, Logseq vault 혹은 markdown 파일 폴더를 압축한 zip 파일의 markdown 파일마다 노트를 만든다.
+// `?tags=a,b` 는 모든 노트에 붙는다.
+func (c *ImportController) ImportNotes(ctx http.ContextExtended) error {
+	tags, err := importTags(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid tags: %s", err.Error())
+	}
+
+	file, err := readImportFile(ctx)
+	if err != nil {
+		return readFileError(ctx, "invalid request body", err)
+	}
+
+	notes, err := imports.ParseMarkdownNotes(file.data)
+	if err != nil {
+		return ctx.BadRequestf("invalid markdown zip file: %s", err.Error())
+	}
+
+	report, err := c.importService.ImportNotes(notes, tags)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to import notes")
+	}
+
+	return ctx.Success(reqres.ImportReportResponse{
+		OK:     true,
+		Report: report,
+	})
+}
+
+// ImportKindle 은 Kindle 기기의 `My Clippings.txt` 혹은 Kindle 앱에서 html 로 내보낸 notebook 을 책마다 노트로 가져온다.
+// `?article=true` 이면 책마다 본문 없는 article 을 만들어 노트가 참고하게 한다.
+func (c *ImportController) ImportKindle(ctx http.ContextExtended) error {
+	tags, err := importTags(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid tags: %s", err.Error())
+	}
+
+	file, err := readImportFile(ctx)
+	if err != nil {
+		return readFileError(ctx, "invalid request body", err)
+	}
+
+	var books []*imports.Book
+	mediaType, _, _ := mime.ParseMediaType(file.contentType)
+	if file.ext == ".html" || file.ext == ".htm" || mediaType == "text/html" || bytes.HasPrefix(bytes.TrimSpace(file.data), []byte("<")) {
+		books, err = imports.ParseKindleNotebook(file.data)
+	} else {
+		books, err = imports.ParseKindleClippings(file.data)
+	}
+	if err != nil {
+		return ctx.BadRequestf("invalid kindle file: %s", err.Error())
+	}
+
+	report, err := c.importService.ImportBooks(books, tags, ctx.QueryParam("article") == "true")
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to import kindle highlights")
+	}
+
+	return ctx.Success(reqres.ImportReportResponse{
+		OK:     true,
+		Report: report,
+	})
+}
+
+// ImportExport 는 Pocket, Instapaper, Wallabag, Raindrop.io, Omnivore, Readwise Reader 의 내보내기 파일을 가져온다.
+// tag, 즐겨찾기, 보관 여부, 저장한 시각을 옮기고, 본문이 들어있는 항목은 내려받지 않는다.
+// `?fetch=true` 이면 본문이 없는 항목을 내려받고, 아니면 제목과 url 만 가진 article 을 만든다.
+func (c *ImportController) ImportExport(ctx http.ContextExtended) error {
+	source := ctx.Param("source")
+	if !imports.IsValidSource(source) {
+		return ctx.BadRequestf("invalid source: %s", source)
+	}
+
+	tags, err := importTags(ctx)
+	if err != nil {
+		return ctx.BadRequestf("invalid tags: %s", err.Error())
+	}
+
+	file, err := readImportFile(ctx)
+	if err != nil {
+		return readFileError(ctx, "invalid request body", err)
+	}
+
+	items, err := imports.ParseExport(source, file.data)
+	if err != nil {
+		return ctx.BadRequestf("invalid %s export: %s", source, err.Error())
+	}
+
+	report, err := c.importService.ImportURLs(items, tags, ctx.QueryParam("fetch") == "true")
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to import "+source+" export")
+	}
+
+	return ctx.Success(reqres.ImportReportResponse{
+		OK:     true,
+		Report: report,
+	})
+}
+
+type importFile struct {
+	data        []byte
+	ext         string
+	contentType string
+}
+
+// readImportFile 은 multipart 의 file 필드 또는 요청 body 자체를 읽는다.
+func readImportFile(ctx http.ContextExtended) (*importFile, error) {
+	if fileHeader, err := ctx.FormFile("file"); err == nil {
+		data, err := readFormFile(fileHeader)
+		if err != nil {
+			return nil, err
+		}
+		return &importFile{
+			data:        data,
+			ext:         strings.ToLower(filepath.Ext(fileHeader.Filename)),
+			contentType: fileHeader.Header.Get("Content-Type"),
+		}, nil
+	}
+
+	data, err := readLimited(ctx.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+	return &importFile{
+		data:        data,
+		contentType: ctx.Request().Header.Get("Content-Type"),
+	}, nil
+}
+
+func readFormFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	f, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readLimited(f)
+}
+
+// readLimited 는 r 을 uploadMaxSize 까지 읽는다. 더 크면 errFileTooLarge 다.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, uploadMaxSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(data) > uploadMaxSize {
+		return nil, errors.Wrapf(errFileTooLarge, "larger than %d bytes", uploadMaxSize)
+	}
+	return data, nil
+}
+
+// readFileError 는 읽지 못한 파일이 너무 크면 413, 아니면 400 으로 돌려준다.
+func readFileError(ctx http.ContextExtended, message string, err error) error {
+	if errors.Is(err, errFileTooLarge) {
+		return ctx.RequestEntityTooLargef("%s: %s", message, err.Error())
+	}
+	return ctx.BadRequestf("%s: %s", message, err.Error())
+}
+
+// importTags 는 `?tags=a,b` 로 받은, 모든 항목에 붙일 tag 들이다.
+func importTags(ctx http.ContextExtended) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(ctx.QueryParam("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, models.ValidateTags(tags)
+}
+
+// urlListFormatOf 는 확장자나 Content-Type 으로 형식을 정한다. 알 수 없으면 내용을 보고 정하도록 비워둔다.
+func urlListFormatOf(ext, contentType string) string {
+	switch ext {
+	case ".csv":
+		return imports.FormatCSV
+	case ".json":
+		return imports.FormatJSON
+	case ".txt":
+		return imports.FormatText
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return imports.FormatCSV
+	case "application/json":
+		return imports.FormatJSON
+	}
+	return ""
+}
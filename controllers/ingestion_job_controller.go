@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/controllers/reqres"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"strings"
+)
+
+type IngestionJobController struct {
+	ingestionService       services.IngestionService
+	ingestionJobRepository repositories.IngestionJobRepository
+}
+
+func NewIngestionJobController() *IngestionJobController {
+	return &IngestionJobController{
+		ingestionService:       services.GetIngestionService(),
+		ingestionJobRepository: repositories.GetIngestionJobRepository(),
+	}
+}
+
+func (c *IngestionJobController) Route(e *echo.Echo) {
+	e.GET("/apis/ingestion-jobs", http.Provide(c.FindJobs))
+	e.POST("/apis/ingestion-jobs", http.Provide(c.EnqueueJobs))
+	e.GET("/apis/ingestion-jobs/:id", http.Provide(c.GetJob))
+	e.POST("/apis/ingestion-jobs/:id/retry", http.Provide(c.RetryJob))
+	e.POST("/apis/ingestion-jobs/:id/cancel", http.Provide(c.CancelJob))
+}
+
+func (c *IngestionJobController) FindJobs(ctx http.ContextExtended) error {
+	state := ctx.QueryParamStr("state")
+	if state != "" && !models.IsValidIngestionJobState(state) {
+		return ctx.BadRequestf("invalid state: %s", state)
+	}
+
+	page, offset, limit := ctx.PageOffsetLimit()
+	jobs, cnt, err := c.ingestionJobRepository.FindWithPage(state, offset, limit)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to find ingestion jobs")
+	}
+
+	return ctx.Success(reqres.IngestionJobsResponse{
+		OK:         true,
+		Jobs:       jobs,
+		Pagination: http.NewPagination(page, cnt),
+	})
+}
+
+func (c *IngestionJobController) EnqueueJobs(ctx http.ContextExtended) error {
+	var req reqres.EnqueueIngestionJobsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	} else if err = req.Validate(); err != nil {
+		return ctx.BadRequestf("invalid request body: %s", err.Error())
+	}
+
+	jobs := []*models.IngestionJob{}
+	for _, url := range req.URLs {
+		job, err := c.ingestionService.Enqueue(strings.TrimSpace(url), req.Tags, models.IngestionSourceAPI)
+		if err != nil {
+			return ctx.InternalServerError(err, "failed to enqueue ingestion job")
+		}
+		jobs = append(jobs, job)
+	}
+
+	return ctx.Success(reqres.IngestionJobsResponse{
+		OK:   true,
+		Jobs: jobs,
+	})
+}
+
+func (c *IngestionJobController) GetJob(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	job, err := c.ingestionJobRepository.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to get ingestion job: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to get ingestion job")
+	}
+
+	return ctx.Success(reqres.IngestionJobResponse{
+		OK:  true,
+		Job: job,
+	})
+}
+
+func (c *IngestionJobController) RetryJob(ctx http.ContextExtended) error {
+	return c.changeJob(ctx, c.ingestionService.Retry, "failed to retry ingestion job")
+}
+
+func (c *IngestionJobController) CancelJob(ctx http.ContextExtended) error {
+	return c.changeJob(ctx, c.ingestionService.Cancel, "failed to cancel ingestion job")
+}
+
+func (c *IngestionJobController) changeJob(ctx http.ContextExtended, change func(id int64) (*models.IngestionJob, error), message string) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	job, err := change(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("%s: %s", message, err.Error())
+		} else if errors.Is(err, services.ErrInvalidIngestionJobState) {
+			return ctx.BadRequestf("%s: %s", message, err.Error())
+		}
+		return ctx.InternalServerError(err, message)
+	}
+
+	return ctx.Success(reqres.IngestionJobResponse{
+		OK:  true,
+		Job: job,
+	})
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/controllers/reqres"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type LinkCheckController struct {
+	linkCheckService    services.LinkCheckService
+	articleRepository   repositories.ArticleRepository
+	linkCheckRepository repositories.LinkCheckRepository
+}
+
+func NewLinkCheckController() *LinkCheckController {
+	return &LinkCheckController{
+		linkCheckService:    services.GetLinkCheckService(),
+		articleRepository:   repositories.GetArticleRepository(),
+		linkCheckRepository: repositories.GetLinkCheckRepository(),
+	}
+}
+
+func (c *LinkCheckController) Route(e *echo.Echo) {
+	e.GET("/apis/link-checks/report", http.Provide(c.GetReport))
+	e.GET("/apis/articles/:id/link-checks", http.Provide(c.FindLinkChecks))
+	e.POST("/apis/articles/:id/link-checks", http.Provide(c.CheckLink))
+}
+
+// GetReport 는 link status 별 article 수와, 원본이 없어지거나 바뀌어 보관본만 남은 article 목록을 돌려준다.
+func (c *LinkCheckController) GetReport(ctx http.ContextExtended) error {
+	counts, err := c.articleRepository.CountByLinkStatus()
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to count articles by link status")
+	}
+
+	page, offset, limit := ctx.PageOffsetLimit()
+	query := &repositories.ArticleQuery{LinkStatuses: []string{models.LinkGone, models.LinkChanged}}
+	articles, cnt, err := c.articleRepository.FindAllWithPage(query, offset, limit)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to find lost articles")
+	}
+
+	unchecked := counts[""]
+	delete(counts, "")
+
+	return ctx.Success(reqres.LinkReportResponse{
+		OK:         true,
+		Counts:     counts,
+		Unchecked:  unchecked,
+		Articles:   articles,
+		Pagination: http.NewPagination(page, cnt),
+	})
+}
+
+func (c *LinkCheckController) FindLinkChecks(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	checks, err := c.linkCheckRepository.FindByArticleID(id)
+	if err != nil {
+		return ctx.InternalServerError(err, "failed to find link checks")
+	}
+
+	return ctx.Success(reqres.LinkChecksResponse{
+		OK:     true,
+		Checks: checks,
+	})
+}
+
+func (c *LinkCheckController) CheckLink(ctx http.ContextExtended) error {
+	id, err := ctx.ParamInt64("id")
+	if err != nil {
+		return ctx.BadRequest("invalid id")
+	}
+
+	check, err := c.linkCheckService.Check(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.NotFoundf("failed to check link: %s", err.Error())
+		}
+		return ctx.InternalServerError(err, "failed to check link")
+	}
+
+	return ctx.Success(reqres.LinkCheckResponse{
+		OK:    true,
+		Check: check,
+	})
+}
@@ -1,7 +1,6 @@
 package reqres
 
 import (
-	"fmt"
 	"github.com/jaeyo/personal-archive/common/http"
 	"github.com/jaeyo/personal-archive/models"
 )
@@ -42,15 +41,23 @@ type ArticlesResponse struct {
 	Pagination *http.Pagination   `json:"pagination"`
 }
 
+type ArticleRevisionsResponse struct {
+	OK        bool                    `json:"ok"`
+	Revisions models.ArticleRevisions `json:"revisions"`
+}
+
+type ArticleRevisionResponse struct {
+	OK       bool                    `json:"ok"`
+	Revision *models.ArticleRevision `json:"revision"`
+}
+
+type ArticleRevisionDiffResponse struct {
+	OK   bool   `json:"ok"`
+	From int64  `json:"from"`
+	To   int64  `json:"to"`
+	Diff string `json:"diff"`
+}
+
 func validateTags(tags []string) error {
-	for _, tag := range tags {
-		if tag == "untagged" {
-			return fmt.Errorf("'untagged' tag reserved")
-		} else if tag == "all" {
-			return fmt.Errorf("'all' tag reserved")
-		} else if len(tag) > 36 {
-			return fmt.Errorf("tag should be less than 36: %s", tag)
-		}
-	}
-	return nil
+	return models.ValidateTags(tags)
 }
@@ -0,0 +1,45 @@
+package reqres
+
+import "github.com/jaeyo/personal-archive/models"
+
+type ExtractionRuleRequest struct {
+	Domain          string `json:"domain" validate:"required,max=256"`
+	ContentSelector string `json:"contentSelector" validate:"max=512"`
+	RemoveSelector  string `json:"removeSelector" validate:"max=1024"`
+	TitleSelector   string `json:"titleSelector" validate:"max=512"`
+	AuthorSelector  string `json:"authorSelector" validate:"max=512"`
+}
+
+func (r *ExtractionRuleRequest) ToModel() *models.ExtractionRule {
+	return &models.ExtractionRule{
+		Domain:          r.Domain,
+		ContentSelector: r.ContentSelector,
+		RemoveSelector:  r.RemoveSelector,
+		TitleSelector:   r.TitleSelector,
+		AuthorSelector:  r.AuthorSelector,
+	}
+}
+
+type DryRunExtractionRuleRequest struct {
+	URL string `json:"url" validate:"required,max=1024"`
+	// 비어있으면 저장된 rule 중 url 과 일치하는 것을 적용한다
+	Rule *ExtractionRuleRequest `json:"rule"`
+}
+
+type ExtractionRuleResponse struct {
+	OK             bool                   `json:"ok"`
+	ExtractionRule *models.ExtractionRule `json:"extractionRule"`
+}
+
+type ExtractionRulesResponse struct {
+	OK              bool                     `json:"ok"`
+	ExtractionRules []*models.ExtractionRule `json:"extractionRules"`
+}
+
+type DryRunExtractionRuleResponse struct {
+	OK        bool   `json:"ok"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	Content   string `json:"content"`
+	Extractor string `json:"extractor"`
+}
@@ -0,0 +1,36 @@
+package reqres
+
+import "github.com/jaeyo/personal-archive/models"
+
+type CreateFeedRequest struct {
+	URL  string   `json:"url" validate:"required,max=1024"`
+	Tags []string `json:"tags"`
+}
+
+func (r *CreateFeedRequest) Validate() error {
+	return validateTags(r.Tags)
+}
+
+type UpdateFeedRequest struct {
+	Title string   `json:"title" validate:"max=256"`
+	Tags  []string `json:"tags"`
+}
+
+func (r *UpdateFeedRequest) Validate() error {
+	return validateTags(r.Tags)
+}
+
+type FeedResponse struct {
+	OK   bool         `json:"ok"`
+	Feed *models.Feed `json:"feed"`
+}
+
+type FeedsResponse struct {
+	OK    bool           `json:"ok"`
+	Feeds []*models.Feed `json:"feeds"`
+}
+
+type ImportOPMLResponse struct {
+	OK       bool `json:"ok"`
+	Imported int  `json:"imported"`
+}
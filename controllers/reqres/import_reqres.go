@@ -0,0 +1,8 @@
+package reqres
+
+import "github.com/jaeyo/personal-archive/services"
+
+type ImportReportResponse struct {
+	OK     bool                   `json:"ok"`
+	Report *services.ImportReport `json:"report"`
+}
@@ -0,0 +1,35 @@
+package reqres
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/models"
+)
+
+type EnqueueIngestionJobsRequest struct {
+	URLs []string `json:"urls" validate:"required"`
+	Tags []string `json:"tags"`
+}
+
+func (r *EnqueueIngestionJobsRequest) Validate() error {
+	if len(r.URLs) == 0 {
+		return fmt.Errorf("urls required")
+	}
+	for _, url := range r.URLs {
+		if url == "" || len(url) > 1024 {
+			return fmt.Errorf("invalid url: %s", url)
+		}
+	}
+	return validateTags(r.Tags)
+}
+
+type IngestionJobResponse struct {
+	OK  bool                 `json:"ok"`
+	Job *models.IngestionJob `json:"job"`
+}
+
+type IngestionJobsResponse struct {
+	OK         bool                   `json:"ok"`
+	Jobs       []*models.IngestionJob `json:"jobs"`
+	Pagination *http.Pagination       `json:"pagination,omitempty"`
+}
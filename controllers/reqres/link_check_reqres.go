@@ -0,0 +1,26 @@
+package reqres
+
+import (
+	"github.com/jaeyo/personal-archive/common/http"
+	"github.com/jaeyo/personal-archive/models"
+)
+
+type LinkCheckResponse struct {
+	OK    bool              `json:"ok"`
+	Check *models.LinkCheck `json:"check"`
+}
+
+type LinkChecksResponse struct {
+	OK     bool              `json:"ok"`
+	Checks models.LinkChecks `json:"checks"`
+}
+
+type LinkReportResponse struct {
+	OK bool `json:"ok"`
+	// link status 별 article 수
+	Counts    map[string]int64 `json:"counts"`
+	Unchecked int64            `json:"unchecked"`
+	// 원본이 gone 이거나 changed 인 article
+	Articles   []*models.Article `json:"articles"`
+	Pagination *http.Pagination  `json:"pagination"`
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/jaeyo/personal-archive/common"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+var assetHashRegex = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// AssetStore 는 이미지 등의 바이너리를 sha256 해시 이름으로 저장하며, 같은 내용의 파일은 article 들이 공유한다.
+type AssetStore interface {
+	Put(data []byte) (string, error)
+	Open(hash string) (*os.File, error)
+	Exist(hash string) bool
+	Delete(hash string) error
+}
+
+type assetStore struct {
+	dir string
+}
+
+var GetAssetStore = func() func() AssetStore {
+	var instance AssetStore
+	var once sync.Once
+
+	return func() AssetStore {
+		once.Do(func() {
+			instance = NewAssetStore(filepath.Join(common.DataDir(), "assets"))
+		})
+		return instance
+	}
+}()
+
+func NewAssetStore(dir string) AssetStore {
+	return &assetStore{dir: dir}
+}
+
+func (s *assetStore) Put(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if s.Exist(hash) {
+		return hash, nil
+	}
+
+	path, err := s.path(hash)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create asset dir")
+	}
+
+	// 쓰는 도중의 파일이 노출되지 않도록 임시 파일에 쓴 뒤 rename 한다
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temp file")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", errors.Wrap(err, "failed to write asset")
+	}
+	if err := f.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to close asset")
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", errors.Wrap(err, "failed to rename asset")
+	}
+	return hash, nil
+}
+
+func (s *assetStore) Open(hash string) (*os.File, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *assetStore) Exist(hash string) bool {
+	path, err := s.path(hash)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func (s *assetStore) Delete(hash string) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *assetStore) path(hash string) (string, error) {
+	if !assetHashRegex.MatchString(hash) {
+		return "", fmt.Errorf("invalid asset hash: %s", hash)
+	}
+	return filepath.Join(s.dir, hash[:2], hash), nil
+}
@@ -0,0 +1,26 @@
+package internal
+
+import "os"
+
+type AssetStoreMock struct {
+	OnPut    func(data []byte) (string, error)
+	OnOpen   func(hash string) (*os.File, error)
+	OnExist  func(hash string) bool
+	OnDelete func(hash string) error
+}
+
+func (m *AssetStoreMock) Put(data []byte) (string, error) {
+	return m.OnPut(data)
+}
+
+func (m *AssetStoreMock) Open(hash string) (*os.File, error) {
+	return m.OnOpen(hash)
+}
+
+func (m *AssetStoreMock) Exist(hash string) bool {
+	return m.OnExist(hash)
+}
+
+func (m *AssetStoreMock) Delete(hash string) error {
+	return m.OnDelete(hash)
+}
@@ -25,10 +25,7 @@ var GetDatabase = func() func() *DB {
 
 	return func() *DB {
 		once.Do(func() {
-			dbDir := "/data"
-			if common.IsLocal() {
-				dbDir = "./test_data"
-			}
+			dbDir := common.DataDir()
 			ensureDirExist(dbDir)
 			dbPath := fmt.Sprintf("%s/personal-archive.db", dbDir)
 
@@ -46,10 +43,17 @@ var GetDatabase = func() func() *DB {
 func (d *DB) Init() error {
 	if err := d.AutoMigrate(
 		&models.Article{},
+		&models.ArticleRevision{},
 		&models.ArticleTag{},
+		&models.Asset{},
+		&models.ExtractionRule{},
+		&models.Feed{},
+		&models.IngestionJob{},
+		&models.LinkCheck{},
 		&models.Misc{},
 		&models.Note{},
 		&models.Paragraph{},
+		&models.PocketItem{},
 		&models.ReferenceArticle{},
 		&models.ReferenceWeb{},
 	); err != nil {
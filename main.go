@@ -16,7 +16,11 @@ import (
 func main() {
 	initialize()
 
+	services.GetIngestionService().Start()
 	services.GetPocketSyncService().Start()
+	services.GetFeedSyncService().Start()
+	services.GetLinkCheckService().Start()
+	services.GetEmailService().Start()
 
 	startHttpServer()
 }
@@ -61,6 +65,12 @@ func routeForControllers(e *echo.Echo) {
 		controllers.NewArticleTagController(),
 		controllers.NewSettingController(),
 		controllers.NewNoteController(),
+		controllers.NewAssetController(),
+		controllers.NewFeedController(),
+		controllers.NewExtractionRuleController(),
+		controllers.NewIngestionJobController(),
+		controllers.NewLinkCheckController(),
+		controllers.NewImportController(),
 	} {
 		controller.Route(e)
 	}
@@ -1,26 +1,48 @@
 package models
 
 import (
+	"github.com/jaeyo/personal-archive/common/markdown"
 	"gorm.io/gorm"
+	"strings"
 	"time"
 )
 
 const (
-	KindMarkdown = "markdown"
-	KindTweet    = "tweet"
-	KindSlideShare = "slideshare"
-	KindYoutube = "youtube"
+	KindMarkdown   = "markdown"
+	KindTweet      = "tweet"
+	KindSlideShare = "slideshare" // 예전에 저장된 SlideShare article, 시작할 때 KindEmbed 로 옮겨진다
+	KindYoutube    = "youtube"
+	KindPDF        = "pdf"
+	KindEmbed      = "embed"
+	KindEmail      = "email"
 )
 
 type Article struct {
-	ID           int64       `gorm:"column:id;primarykey" json:"id"`
-	Kind         string      `gorm:"column:kind;type:varchar(24);not null" json:"kind"`
-	URL          string      `gorm:"column:url;type:varchar(256);not null" json:"url"`
-	Content      string      `gorm:"column:content;type:text" json:"content"`
-	Title        string      `gorm:"column:title;type:varchar(256);not null;uniqueIndex" json:"title"`
-	Tags         ArticleTags `gorm:"foreignKey:ArticleID" json:"tags"`
-	Created      time.Time   `gorm:"column:created;type:datetime;not null" json:"created"`
-	LastModified time.Time   `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
+	ID           int64        `gorm:"column:id;primarykey" json:"id"`
+	Kind         string       `gorm:"column:kind;type:varchar(24);not null" json:"kind"`
+	URL          string       `gorm:"column:url;type:varchar(256);not null" json:"url"`
+	CanonicalURL *string      `gorm:"column:canonical_url;type:varchar(1024);uniqueIndex" json:"canonicalUrl"`
+	Content      string       `gorm:"column:content;type:text" json:"content"`
+	Title        string       `gorm:"column:title;type:varchar(256);not null;uniqueIndex" json:"title"`
+	Tags         ArticleTags  `gorm:"foreignKey:ArticleID" json:"tags"`
+	Assets       Assets       `gorm:"foreignKey:ArticleID" json:"-"`
+	Extractor    string       `gorm:"column:extractor;type:varchar(24)" json:"extractor"`
+	Author       string       `gorm:"column:author;type:varchar(256);index" json:"author"`
+	Published    *time.Time   `gorm:"column:published;type:datetime;index" json:"published"`
+	SiteName     string       `gorm:"column:site_name;type:varchar(256);index" json:"siteName"`
+	Excerpt      string       `gorm:"column:excerpt;type:text" json:"excerpt"`
+	LeadImage    string       `gorm:"column:lead_image;type:varchar(1024)" json:"leadImage"`
+	Language     string       `gorm:"column:language;type:varchar(8);index" json:"language"`
+	WordCount    int          `gorm:"column:word_count;type:integer;not null;default:0" json:"wordCount"`
+	ReadingTime  int          `gorm:"column:reading_time;type:integer;not null;default:0" json:"readingTime"`
+	Embed        ArticleEmbed `gorm:"embedded;embeddedPrefix:embed_" json:"embed"`
+	// 원본 url 의 마지막 확인 결과, 한 번도 확인하지 않았으면 비어있다
+	LinkStatus   string     `gorm:"column:link_status;type:varchar(16);not null;default:'';index" json:"linkStatus"`
+	LinkChecked  *time.Time `gorm:"column:link_checked;type:datetime;index" json:"linkChecked"`
+	Favorite     bool       `gorm:"column:favorite;not null;default:false;index" json:"favorite"`
+	Archived     bool       `gorm:"column:archived;not null;default:false;index" json:"archived"`
+	Created      time.Time  `gorm:"column:created;type:datetime;not null" json:"created"`
+	LastModified time.Time  `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
 }
 
 func NewArticle(kind, url, content, title string, tags []string) *Article {
@@ -29,13 +51,26 @@ func NewArticle(kind, url, content, title string, tags []string) *Article {
 		articleTags = append(articleTags, &ArticleTag{Tag: tag})
 	}
 
-	return &Article{
+	article := &Article{
 		Kind:    kind,
 		URL:     url,
 		Content: content,
 		Title:   title,
 		Tags:    articleTags,
 	}
+	article.UpdateStatistics()
+	return article
+}
+
+// UpdateStatistics 는 content 로부터 단어 수와 읽는 시간을 다시 계산한다.
+func (a *Article) UpdateStatistics() {
+	a.WordCount = markdown.WordCount(a.Content)
+	a.ReadingTime = markdown.ReadingMinutes(a.WordCount)
+}
+
+// IsFetchable 은 올린 파일이나 메일처럼 웹에서 가져오지 않은 article 이 아니라 다시 내려받을 수 있는지 확인한다.
+func (a *Article) IsFetchable() bool {
+	return strings.HasPrefix(a.URL, "http://") || strings.HasPrefix(a.URL, "https://")
 }
 
 func (a *Article) TableName() string {
@@ -0,0 +1,10 @@
+package models
+
+// ArticleEmbed 는 oEmbed provider 의 player 정보다. provider 가 준 html 은 저장하지 않고 검증된 iframe 주소와 크기만 저장한다.
+type ArticleEmbed struct {
+	// e.g. `SlideShare`, `Vimeo`
+	Provider string `gorm:"column:provider;type:varchar(64)" json:"provider"`
+	Src      string `gorm:"column:src;type:varchar(1024)" json:"src"`
+	Width    int    `gorm:"column:width;type:integer;not null;default:0" json:"width"`
+	Height   int    `gorm:"column:height;type:integer;not null;default:0" json:"height"`
+}
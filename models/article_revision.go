@@ -0,0 +1,53 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ArticleRevision.Source 의 값들
+const (
+	// 처음 만들어질 때
+	RevisionSourceFetch = "fetch"
+	// 기록을 남기기 시작하기 전에 만들어진 article 의 첫 변경 직전 상태
+	RevisionSourceInitial   = "initial"
+	RevisionSourceUserEdit  = "user-edit"
+	RevisionSourceRefetch   = "refetch"
+	RevisionSourceReExtract = "re-extract"
+	RevisionSourceImport    = "import"
+	RevisionSourceEmail     = "email"
+	RevisionSourceUpload    = "upload"
+	RevisionSourceRestore   = "restore"
+)
+
+// ArticleRevision 은 article 의 제목과 본문이 바뀔 때마다 바뀐 뒤의 상태를 남긴 것이다.
+type ArticleRevision struct {
+	ID        int64     `gorm:"column:id;primarykey" json:"id"`
+	ArticleID int64     `gorm:"column:article_id;not null;index" json:"articleId"`
+	Title     string    `gorm:"column:title;type:varchar(256);not null" json:"title"`
+	Content   string    `gorm:"column:content;type:text" json:"content,omitempty"`
+	Source    string    `gorm:"column:source;type:varchar(24);not null" json:"source"`
+	Created   time.Time `gorm:"column:created;type:datetime;not null" json:"created"`
+}
+
+func NewArticleRevision(article *Article, source string) *ArticleRevision {
+	return &ArticleRevision{
+		ArticleID: article.ID,
+		Title:     article.Title,
+		Content:   article.Content,
+		Source:    source,
+	}
+}
+
+func (r *ArticleRevision) TableName() string {
+	return "article_revision"
+}
+
+func (r *ArticleRevision) BeforeSave(db *gorm.DB) error {
+	if r.Created.IsZero() {
+		r.Created = time.Now()
+	}
+	return nil
+}
+
+type ArticleRevisions []*ArticleRevision
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"github.com/jaeyo/personal-archive/common"
 )
 
@@ -16,6 +17,20 @@ func (t *ArticleTag) TableName() string {
 
 type ArticleTags []*ArticleTag
 
+// ValidateTags 는 목록 조회에 예약된 이름이거나 너무 긴 tag 가 있으면 에러를 돌려준다.
+func ValidateTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "untagged" {
+			return fmt.Errorf("'untagged' tag reserved")
+		} else if tag == "all" {
+			return fmt.Errorf("'all' tag reserved")
+		} else if len(tag) > 36 {
+			return fmt.Errorf("tag should be less than 36: %s", tag)
+		}
+	}
+	return nil
+}
+
 func (t ArticleTags) FilterExcluded(tags common.Strings) (ArticleTags, ArticleTags) {
 	var excluded, notExcluded ArticleTags
 	for _, articleTag := range t {
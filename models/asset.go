@@ -0,0 +1,70 @@
+package models
+
+import (
+	"github.com/jaeyo/personal-archive/common"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	AssetKindImage      = "image"
+	AssetKindSnapshot   = "snapshot"
+	AssetKindAttachment = "attachment"
+)
+
+type Asset struct {
+	ID           int64     `gorm:"column:id;primarykey" json:"id"`
+	ArticleID    int64     `gorm:"column:article_id;type:integer;not null;index" json:"articleID"`
+	Kind         string    `gorm:"column:kind;type:varchar(24);not null;default:image" json:"kind"`
+	Hash         string    `gorm:"column:hash;type:varchar(64);not null;index" json:"hash"`
+	ContentType  string    `gorm:"column:content_type;type:varchar(128);not null" json:"contentType"`
+	OriginURL    string    `gorm:"column:origin_url;type:varchar(1024);not null" json:"originURL"`
+	Size         int64     `gorm:"column:size;type:integer;not null" json:"size"`
+	Created      time.Time `gorm:"column:created;type:datetime;not null" json:"created"`
+	LastModified time.Time `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
+}
+
+func (a *Asset) TableName() string {
+	return "asset"
+}
+
+func (a *Asset) BeforeSave(db *gorm.DB) error {
+	if a.Created.IsZero() {
+		a.Created = time.Now()
+	}
+	a.LastModified = time.Now()
+	return nil
+}
+
+type Assets []*Asset
+
+func (a Assets) ContainHash(hash string) bool {
+	for _, asset := range a {
+		if asset.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Assets) ExtractIDs() []int64 {
+	ids := []int64{}
+	for _, asset := range a {
+		ids = append(ids, asset.ID)
+	}
+	return ids
+}
+
+func (a Assets) ExtractHashes() []string {
+	hashes := []string{}
+	for _, asset := range a {
+		if !common.Strings(hashes).Contain(asset.Hash) {
+			hashes = append(hashes, asset.Hash)
+		}
+	}
+	return hashes
+}
+
+func AssetPath(hash string) string {
+	return "/apis/assets/" + hash
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// ExtractionRule 은 readability 로 본문이 잘 추출되지 않는 사이트에 적용할 CSS selector 들이다.
+type ExtractionRule struct {
+	ID     int64  `gorm:"column:id;primarykey" json:"id"`
+	Domain string `gorm:"column:domain;type:varchar(256);not null;uniqueIndex" json:"domain"`
+	// 비어있으면 불필요한 요소만 지운 뒤 readability 로 추출한다
+	ContentSelector string    `gorm:"column:content_selector;type:varchar(512);not null" json:"contentSelector"`
+	RemoveSelector  string    `gorm:"column:remove_selector;type:varchar(1024);not null" json:"removeSelector"`
+	TitleSelector   string    `gorm:"column:title_selector;type:varchar(512);not null" json:"titleSelector"`
+	AuthorSelector  string    `gorm:"column:author_selector;type:varchar(512);not null" json:"authorSelector"`
+	Created         time.Time `gorm:"column:created;type:datetime;not null" json:"created"`
+	LastModified    time.Time `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
+}
+
+func (r *ExtractionRule) TableName() string {
+	return "extraction_rule"
+}
+
+func (r *ExtractionRule) BeforeSave(db *gorm.DB) error {
+	if r.Created.IsZero() {
+		r.Created = time.Now()
+	}
+	r.LastModified = time.Now()
+	return nil
+}
+
+type ExtractionRules []*ExtractionRule
+
+// MostSpecificFor 는 host 와 일치하거나 host 의 상위 도메인인 rule 중 가장 구체적인 것을 돌려준다.
+func (r ExtractionRules) MostSpecificFor(host string) *ExtractionRule {
+	host = strings.ToLower(host)
+
+	var matched *ExtractionRule
+	for _, rule := range r {
+		domain := strings.ToLower(rule.Domain)
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if matched == nil || len(domain) > len(matched.Domain) {
+			matched = rule
+		}
+	}
+	return matched
+}
+
+// DomainCandidates 는 host 자신과 상위 도메인들이다. e.g. `a.b.com` -> [`a.b.com`, `b.com`, `com`]
+func DomainCandidates(host string) []string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	var candidates []string
+	for host != "" {
+		candidates = append(candidates, host)
+		idx := strings.Index(host, ".")
+		if idx < 0 {
+			break
+		}
+		host = host[idx+1:]
+	}
+	return candidates
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Feed struct {
+	ID    int64      `gorm:"column:id;primarykey" json:"id"`
+	URL   string     `gorm:"column:url;type:varchar(1024);not null;uniqueIndex" json:"url"`
+	Title string     `gorm:"column:title;type:varchar(256);not null" json:"title"`
+	Tags  StringList `gorm:"column:tags;type:varchar(512);not null" json:"tags"`
+	// 마지막 poll 에서 받은 가장 최근 항목
+	LastGUID string `gorm:"column:last_guid;type:varchar(1024);not null" json:"lastGUID"`
+	// 이미 가져왔거나 건너뛴 항목들의 guid 를 줄바꿈으로 이은 것
+	SeenGUIDs string `gorm:"column:seen_guids;type:text;not null;default:''" json:"-"`
+	// conditional get 을 위한 응답 헤더 값
+	ETag             string     `gorm:"column:etag;type:varchar(256);not null" json:"etag"`
+	HTTPLastModified string     `gorm:"column:http_last_modified;type:varchar(64);not null" json:"httpLastModified"`
+	LastPolled       *time.Time `gorm:"column:last_polled;type:datetime" json:"lastPolled"`
+	LastError        string     `gorm:"column:last_error;type:text;not null" json:"lastError"`
+	Created          time.Time  `gorm:"column:created;type:datetime;not null" json:"created"`
+	LastModified     time.Time  `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
+}
+
+func (f *Feed) TableName() string {
+	return "feed"
+}
+
+func (f *Feed) BeforeSave(db *gorm.DB) error {
+	if f.Created.IsZero() {
+		f.Created = time.Now()
+	}
+	f.LastModified = time.Now()
+	return nil
+}
+
+type Feeds []*Feed
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	IngestionJobQueued    = "queued"
+	IngestionJobRunning   = "running"
+	IngestionJobSucceeded = "succeeded"
+	IngestionJobFailed    = "failed"
+	IngestionJobCanceled  = "canceled"
+)
+
+// IngestionJob.Source 의 값들
+const (
+	IngestionSourceAPI    = "api"
+	IngestionSourcePocket = "pocket"
+	IngestionSourceFeed   = "feed"
+	IngestionSourceImport = "import"
+)
+
+// IngestionJob 은 url 로 article 을 만드는 작업이다. queue 에 쌓였다가 worker 가 꺼내 처리한다.
+type IngestionJob struct {
+	ID   int64      `gorm:"column:id;primarykey" json:"id"`
+	URL  string     `gorm:"column:url;type:varchar(1024);not null;index" json:"url"`
+	Tags StringList `gorm:"column:tags;type:varchar(512);not null" json:"tags"`
+	// import 할 때 함께 받은 제목, 비어있지 않으면 추출한 제목 대신 쓴다
+	Title string `gorm:"column:title;type:varchar(256);not null;default:''" json:"title"`
+	// 추출한 문서에 제목이 없을 때 대신 쓸 제목
+	FallbackTitle string `gorm:"column:fallback_title;type:varchar(256);not null;default:''" json:"fallbackTitle"`
+	// import 한 항목을 원래 서비스에 저장한 시각, 즐겨찾기, 보관 여부로 만들어질 article 에 그대로 옮긴다
+	ArticleCreated *time.Time `gorm:"column:article_created;type:datetime" json:"articleCreated"`
+	Favorite       bool       `gorm:"column:favorite;not null;default:false" json:"favorite"`
+	Archived       bool       `gorm:"column:archived;not null;default:false" json:"archived"`
+	Source         string     `gorm:"column:source;type:varchar(24);not null" json:"source"`
+	State          string     `gorm:"column:state;type:varchar(16);not null;index" json:"state"`
+	Attempts       int        `gorm:"column:attempts;type:integer;not null;default:0" json:"attempts"`
+	// 실패 후 다시 시도할 시각
+	NextAttempt  time.Time `gorm:"column:next_attempt;type:datetime;not null;index" json:"nextAttempt"`
+	LastError    string    `gorm:"column:last_error;type:text;not null" json:"lastError"`
+	ArticleID    *int64    `gorm:"column:article_id" json:"articleId"`
+	Created      time.Time `gorm:"column:created;type:datetime;not null" json:"created"`
+	LastModified time.Time `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
+}
+
+func NewIngestionJob(url string, tags []string, source string) *IngestionJob {
+	return &IngestionJob{
+		URL:         url,
+		Tags:        tags,
+		Source:      source,
+		State:       IngestionJobQueued,
+		NextAttempt: time.Now(),
+	}
+}
+
+func (j *IngestionJob) TableName() string {
+	return "ingestion_job"
+}
+
+func (j *IngestionJob) BeforeSave(db *gorm.DB) error {
+	if j.Created.IsZero() {
+		j.Created = time.Now()
+	}
+	j.LastModified = time.Now()
+	return nil
+}
+
+func (j *IngestionJob) IsRetryable() bool {
+	return j.State == IngestionJobFailed || j.State == IngestionJobCanceled
+}
+
+func (j *IngestionJob) IsCancelable() bool {
+	return j.State == IngestionJobQueued || j.State == IngestionJobRunning
+}
+
+type IngestionJobs []*IngestionJob
+
+func IsValidIngestionJobState(state string) bool {
+	switch state {
+	case IngestionJobQueued, IngestionJobRunning, IngestionJobSucceeded, IngestionJobFailed, IngestionJobCanceled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// LinkCheck.Status, Article.LinkStatus 의 값들
+const (
+	LinkAlive = "alive"
+	// 다른 문서로 옮겨졌다
+	LinkRedirected = "redirected"
+	// 404, 410 이거나 host 가 없어졌다
+	LinkGone = "gone"
+	// 응답은 오지만 보관할 때와 다른 문서가 되었다
+	LinkChanged = "changed"
+	// timeout, 5xx, 접근 거부처럼 죽었는지 판단할 수 없는 경우
+	LinkError = "error"
+)
+
+func IsValidLinkStatus(status string) bool {
+	switch status {
+	case LinkAlive, LinkRedirected, LinkGone, LinkChanged, LinkError:
+		return true
+	}
+	return false
+}
+
+// LinkCheck 는 article 원본 url 의 상태가 바뀔 때마다 남기는 기록이다. 상태가 그대로면 남기지 않는다.
+type LinkCheck struct {
+	ID         int64  `gorm:"column:id;primarykey" json:"id"`
+	ArticleID  int64  `gorm:"column:article_id;not null;index" json:"articleId"`
+	Status     string `gorm:"column:status;type:varchar(16);not null" json:"status"`
+	StatusCode int    `gorm:"column:status_code;type:integer;not null;default:0" json:"statusCode"`
+	// redirect 를 따라간 최종 url
+	FinalURL string    `gorm:"column:final_url;type:varchar(1024);not null" json:"finalUrl"`
+	Error    string    `gorm:"column:error;type:text;not null" json:"error"`
+	Created  time.Time `gorm:"column:created;type:datetime;not null" json:"created"`
+}
+
+func (c *LinkCheck) TableName() string {
+	return "link_check"
+}
+
+func (c *LinkCheck) BeforeSave(db *gorm.DB) error {
+	if c.Created.IsZero() {
+		c.Created = time.Now()
+	}
+	return nil
+}
+
+type LinkChecks []*LinkCheck
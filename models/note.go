@@ -9,6 +9,7 @@ type Note struct {
 	ID           int64      `gorm:"column:id;primarykey" json:"id"`
 	Title        string     `gorm:"column:title;type:varchar(256);not null;uniqueIndex" json:"title"`
 	Paragraphs   Paragraphs `gorm:"foreignKey:NoteID" json:"paragraphs"`
+	Tags         StringList `gorm:"column:tags;type:varchar(512);not null;default:''" json:"tags"`
 	Created      time.Time  `gorm:"column:created;type:datetime;not null" json:"created"`
 	LastModified time.Time  `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// PocketItem 은 지워진 항목의 article 도 찾을 수 있도록 남긴 pocket 항목의 id 와 url 이다.
+type PocketItem struct {
+	ID     int64  `gorm:"column:id;primarykey" json:"id"`
+	ItemID string `gorm:"column:item_id;type:varchar(32);not null;uniqueIndex" json:"itemId"`
+	URL    string `gorm:"column:url;type:varchar(1024);not null" json:"url"`
+	// pocket 에서의 상태, pocket.StatusUnread 등
+	Status   string `gorm:"column:status;type:varchar(4);not null" json:"status"`
+	Favorite bool   `gorm:"column:favorite;not null;default:false" json:"favorite"`
+	// Status, Favorite 가 바뀐 것을 아직 article 에 반영하지 못했으면 true, 가져오는 중인 job 이 끝나면 다시 반영한다
+	Pending bool `gorm:"column:pending;not null;default:false;index" json:"pending"`
+	// 항목을 가져오려고 만든 ingestion job, 끝나면 job 의 ArticleID 로 article 을 찾는다
+	JobID        *int64    `gorm:"column:job_id" json:"jobId"`
+	Created      time.Time `gorm:"column:created;type:datetime;not null" json:"created"`
+	LastModified time.Time `gorm:"column:last_modified;type:datetime;not null" json:"lastModified"`
+}
+
+func (i *PocketItem) TableName() string {
+	return "pocket_item"
+}
+
+func (i *PocketItem) BeforeSave(db *gorm.DB) error {
+	if i.Created.IsZero() {
+		i.Created = time.Now()
+	}
+	i.LastModified = time.Now()
+	return nil
+}
+
+type PocketItems []*PocketItem
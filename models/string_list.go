@@ -0,0 +1,37 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringList 는 태그 목록처럼 짧은 문자열 목록을 콤마로 이어서 한 컬럼에 저장한다.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		s = ""
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("unsupported type for string list: %T", value)
+	}
+
+	list := StringList{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*l = list
+	return nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ArticleQuery 의 Sort 로 사용할 수 있는 값들과 실제 컬럼
+var articleSortColumns = map[string]string{
+	"created":     "article.created",
+	"published":   "article.published",
+	"title":       "article.title",
+	"wordCount":   "article.word_count",
+	"readingTime": "article.reading_time",
+}
+
+// ArticleQuery 는 article 목록 조회의 정렬, 필터 조건이다. 비어있는 조건은 적용하지 않는다.
+type ArticleQuery struct {
+	// articleSortColumns 의 key, 비어있으면 created
+	Sort string
+	Asc  bool
+
+	Author         string
+	SiteName       string
+	Language       string
+	PublishedFrom  *time.Time
+	PublishedTo    *time.Time
+	MinReadingTime int
+	MaxReadingTime int
+	// 원본 url 의 확인 결과 중 하나
+	LinkStatuses []string
+	Favorite     *bool
+	Archived     *bool
+}
+
+func IsValidArticleSort(sort string) bool {
+	_, ok := articleSortColumns[sort]
+	return sort == "" || ok
+}
+
+func (q *ArticleQuery) filter(tx *gorm.DB) *gorm.DB {
+	if q == nil {
+		return tx
+	}
+
+	if q.Author != "" {
+		tx = tx.Where("article.author = ?", q.Author)
+	}
+	if q.SiteName != "" {
+		tx = tx.Where("article.site_name = ?", q.SiteName)
+	}
+	if q.Language != "" {
+		tx = tx.Where("article.language = ?", q.Language)
+	}
+	if q.PublishedFrom != nil {
+		tx = tx.Where("article.published >= ?", *q.PublishedFrom)
+	}
+	if q.PublishedTo != nil {
+		tx = tx.Where("article.published < ?", *q.PublishedTo)
+	}
+	if q.MinReadingTime > 0 {
+		tx = tx.Where("article.reading_time >= ?", q.MinReadingTime)
+	}
+	if q.MaxReadingTime > 0 {
+		tx = tx.Where("article.reading_time <= ?", q.MaxReadingTime)
+	}
+	if len(q.LinkStatuses) > 0 {
+		tx = tx.Where("article.link_status IN ?", q.LinkStatuses)
+	}
+	if q.Favorite != nil {
+		tx = tx.Where("article.favorite = ?", *q.Favorite)
+	}
+	if q.Archived != nil {
+		tx = tx.Where("article.archived = ?", *q.Archived)
+	}
+	return tx
+}
+
+func (q *ArticleQuery) order(tx *gorm.DB) *gorm.DB {
+	column := articleSortColumns["created"]
+	direction := "DESC"
+	if q != nil {
+		if c, ok := articleSortColumns[q.Sort]; ok {
+			column = c
+		}
+		if q.Asc {
+			direction = "ASC"
+		}
+	}
+
+	tx = tx.Order(column + " " + direction)
+	if column != articleSortColumns["created"] {
+		tx = tx.Order("article.created DESC")
+	}
+	return tx
+}
@@ -4,20 +4,28 @@ import (
 	"github.com/jaeyo/personal-archive/internal"
 	"github.com/jaeyo/personal-archive/models"
 	"sync"
+	"time"
 )
 
 type ArticleRepository interface {
 	Save(article *models.Article) error
-	FindAllWithPage(offset, limit int) (models.Articles, int64, error)
-	FindByIDsWithPage(ids []int64, offset, limit int) (models.Articles, int64, error)
+	FindAllWithPage(query *ArticleQuery, offset, limit int) (models.Articles, int64, error)
+	FindByIDsWithPage(ids []int64, query *ArticleQuery, offset, limit int) (models.Articles, int64, error)
 	FindByIDs(ids []int64) (models.Articles, error)
+	FindByKind(kind string) (models.Articles, error)
 	GetByID(id int64) (*models.Article, error)
-	FindByTagWithPage(tag string, offset, limit int) (models.Articles, int64, error)
-	FindUntaggedWithPage(offset, limit int) (models.Articles, int64, error)
+	GetByCanonicalURL(canonicalURL string) (*models.Article, error)
+	FindWithoutCanonicalURL() (models.Articles, error)
+	FindLinkCheckDue(checkedBefore time.Time, limit int) (models.Articles, error)
+	FindByTagWithPage(tag string, query *ArticleQuery, offset, limit int) (models.Articles, int64, error)
+	FindUntaggedWithPage(query *ArticleQuery, offset, limit int) (models.Articles, int64, error)
 	GetUntaggedCount() (int64, error)
 	GetAllCount() (int64, error)
+	CountByLinkStatus() (map[string]int64, error)
 	ExistByTitle(title string) (bool, error)
 	ExistByIDs(ids []int64) (bool, error)
+	UpdateCanonicalURL(id int64, canonicalURL string) error
+	UpdateLinkStatus(id int64, status string, checked time.Time) error
 	DeleteByIDs(ids []int64) error
 }
 
@@ -56,11 +64,9 @@ func (r *articleRepository) Save(article *models.Article) error {
 
 }
 
-func (r *articleRepository) FindAllWithPage(offset, limit int) (models.Articles, int64, error) {
+func (r *articleRepository) FindAllWithPage(query *ArticleQuery, offset, limit int) (models.Articles, int64, error) {
 	var articles []*models.Article
-	if err := r.database.
-		Preload("Tags").
-		Order("created DESC").
+	if err := query.order(query.filter(r.database.Preload("Tags"))).
 		Offset(offset).
 		Limit(limit).
 		Find(&articles).Error; err != nil {
@@ -68,8 +74,7 @@ func (r *articleRepository) FindAllWithPage(offset, limit int) (models.Articles,
 	}
 
 	var cnt int64
-	if err := r.database.
-		Model(&models.Article{}).
+	if err := query.filter(r.database.Model(&models.Article{})).
 		Count(&cnt).Error; err != nil {
 		return nil, -1, err
 	}
@@ -78,23 +83,35 @@ func (r *articleRepository) FindAllWithPage(offset, limit int) (models.Articles,
 	return articles, cnt, nil
 }
 
-func (r *articleRepository) FindByIDsWithPage(ids []int64, offset, limit int) (models.Articles, int64, error) {
+func (r *articleRepository) FindByIDsWithPage(ids []int64, query *ArticleQuery, offset, limit int) (models.Articles, int64, error) {
 	if len(ids) == 0 {
 		return []*models.Article{}, 0, nil
 	}
 
+	// 검색 결과는 정렬 조건이 지정된 경우에만 정렬한다
+	tx := query.filter(r.database.Preload("Tags").Where("id IN ?", ids))
+	if query != nil && query.Sort != "" {
+		tx = query.order(tx)
+	}
+
 	var articles []*models.Article
-	if err := r.database.
-		Preload("Tags").
-		Where("id IN ?", ids).
+	if err := tx.
 		Offset(offset).
 		Limit(limit).
 		Find(&articles).Error; err != nil {
 		return nil, -1, err
 	}
 
+	cnt := int64(len(ids))
+	if query != nil {
+		if err := query.filter(r.database.Model(&models.Article{}).Where("id IN ?", ids)).
+			Count(&cnt).Error; err != nil {
+			return nil, -1, err
+		}
+	}
+
 	ensureArticleAssociationNotNil(articles)
-	return articles, int64(len(ids)), nil
+	return articles, cnt, nil
 }
 
 func (r *articleRepository) FindByIDs(ids []int64) (models.Articles, error) {
@@ -114,22 +131,78 @@ func (r *articleRepository) FindByIDs(ids []int64) (models.Articles, error) {
 	return articles, nil
 }
 
+func (r *articleRepository) FindByKind(kind string) (models.Articles, error) {
+	var articles []*models.Article
+	if err := r.database.
+		Preload("Tags").
+		Where("kind = ?", kind).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	ensureArticleAssociationNotNil(articles)
+	return articles, nil
+}
+
 func (r *articleRepository) GetByID(id int64) (*models.Article, error) {
 	var article models.Article
 	err := r.database.
 		Preload("Tags").
+		Preload("Assets").
 		First(&article, id).Error
 	ensureArticleAssociationNotNil([]*models.Article{&article})
 	return &article, err
 }
 
-func (r *articleRepository) FindByTagWithPage(tag string, offset, limit int) (models.Articles, int64, error) {
+func (r *articleRepository) GetByCanonicalURL(canonicalURL string) (*models.Article, error) {
+	var article models.Article
+	err := r.database.
+		Preload("Tags").
+		Preload("Assets").
+		Where("canonical_url = ?", canonicalURL).
+		First(&article).Error
+	ensureArticleAssociationNotNil([]*models.Article{&article})
+	return &article, err
+}
+
+func (r *articleRepository) FindWithoutCanonicalURL() (models.Articles, error) {
+	var articles []*models.Article
+	if err := r.database.
+		Where("canonical_url IS NULL").
+		Order("id").
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	ensureArticleAssociationNotNil(articles)
+	return articles, nil
+}
+
+// FindLinkCheckDue 는 원본 url 을 한 번도 확인하지 않았거나 checkedBefore 이전에 확인한 article 을 오래된 순으로 돌려준다.
+func (r *articleRepository) FindLinkCheckDue(checkedBefore time.Time, limit int) (models.Articles, error) {
 	var articles []*models.Article
 	if err := r.database.
+		Select("id", "kind", "url", "canonical_url", "title", "link_status", "link_checked").
+		Where("link_checked IS NULL OR link_checked < ?", checkedBefore).
+		// Kindle 책처럼 내려받을 수 없는 url 의 article 은 확인하지 않는다
+		Where("(url LIKE 'http://%' OR url LIKE 'https://%')").
+		Order("link_checked").
+		Order("id").
+		Limit(limit).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	ensureArticleAssociationNotNil(articles)
+	return articles, nil
+}
+
+func (r *articleRepository) FindByTagWithPage(tag string, query *ArticleQuery, offset, limit int) (models.Articles, int64, error) {
+	var articles []*models.Article
+	if err := query.order(query.filter(r.database.
 		Preload("Tags").
 		Joins("JOIN article_tag ON article_tag.article_id = article.id").
-		Where("article_tag.tag = ?", tag).
-		Order("article.created DESC").
+		Where("article_tag.tag = ?", tag))).
 		Offset(offset).
 		Limit(limit).
 		Find(&articles).Error; err != nil {
@@ -137,10 +210,10 @@ func (r *articleRepository) FindByTagWithPage(tag string, offset, limit int) (mo
 	}
 
 	var cnt int64
-	if err := r.database.
+	if err := query.filter(r.database.
 		Model(&models.Article{}).
 		Joins("JOIN article_tag ON article_tag.article_id = article.id").
-		Where("article_tag.tag = ?", tag).
+		Where("article_tag.tag = ?", tag)).
 		Count(&cnt).Error; err != nil {
 		return nil, -1, err
 	}
@@ -149,12 +222,11 @@ func (r *articleRepository) FindByTagWithPage(tag string, offset, limit int) (mo
 	return articles, cnt, nil
 }
 
-func (r *articleRepository) FindUntaggedWithPage(offset, limit int) (models.Articles, int64, error) {
+func (r *articleRepository) FindUntaggedWithPage(query *ArticleQuery, offset, limit int) (models.Articles, int64, error) {
 	var articles []*models.Article
-	if err := r.database.
+	if err := query.order(query.filter(r.database.
 		Joins("LEFT JOIN article_tag ON article_tag.article_id = article.id").
-		Where("article_tag.id IS NULL").
-		Order("article.created DESC").
+		Where("article_tag.id IS NULL"))).
 		Offset(offset).
 		Limit(limit).
 		Find(&articles).Error; err != nil {
@@ -162,10 +234,10 @@ func (r *articleRepository) FindUntaggedWithPage(offset, limit int) (models.Arti
 	}
 
 	var cnt int64
-	if err := r.database.
+	if err := query.filter(r.database.
 		Model(&models.Article{}).
 		Joins("LEFT JOIN article_tag ON article_tag.article_id = article.id").
-		Where("article_tag.id IS NULL").
+		Where("article_tag.id IS NULL")).
 		Count(&cnt).Error; err != nil {
 		return nil, -1, err
 	}
@@ -192,6 +264,27 @@ func (r *articleRepository) GetAllCount() (int64, error) {
 	return cnt, err
 }
 
+// CountByLinkStatus 는 link status 별 article 수를 돌려준다. 확인하지 않은 article 은 빈 문자열에 센다.
+func (r *articleRepository) CountByLinkStatus() (map[string]int64, error) {
+	var rows []struct {
+		LinkStatus string
+		Cnt        int64
+	}
+	if err := r.database.
+		Model(&models.Article{}).
+		Select("link_status, COUNT(*) AS cnt").
+		Group("link_status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.LinkStatus] = row.Cnt
+	}
+	return counts, nil
+}
+
 func (r *articleRepository) ExistByTitle(title string) (bool, error) {
 	var cnt int64
 	err := r.database.
@@ -210,6 +303,25 @@ func (r *articleRepository) ExistByIDs(ids []int64) (bool, error) {
 	return cnt == int64(len(ids)), err
 }
 
+// UpdateCanonicalURL 은 last_modified 를 바꾸지 않고 canonical url 만 채운다.
+func (r *articleRepository) UpdateCanonicalURL(id int64, canonicalURL string) error {
+	return r.database.
+		Model(&models.Article{}).
+		Where("id = ?", id).
+		UpdateColumn("canonical_url", canonicalURL).Error
+}
+
+// UpdateLinkStatus 는 last_modified 를 바꾸지 않고 원본 url 의 확인 결과만 저장한다.
+func (r *articleRepository) UpdateLinkStatus(id int64, status string, checked time.Time) error {
+	return r.database.
+		Model(&models.Article{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"link_status":  status,
+			"link_checked": checked,
+		}).Error
+}
+
 func (r *articleRepository) DeleteByIDs(ids []int64) error {
 	if err := r.database.Where("id IN ?", ids).Delete(&models.Article{}).Error; err != nil {
 		return err
@@ -223,5 +335,8 @@ func ensureArticleAssociationNotNil(articles []*models.Article) {
 		if article.Tags == nil {
 			article.Tags = models.ArticleTags{}
 		}
+		if article.Assets == nil {
+			article.Assets = models.Assets{}
+		}
 	}
 }
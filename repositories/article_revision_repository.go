@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+)
+
+type ArticleRevisionRepository interface {
+	Save(revision *models.ArticleRevision) error
+	FindByArticleID(articleID int64) (models.ArticleRevisions, error)
+	GetByID(id int64) (*models.ArticleRevision, error)
+	ExistByArticleID(articleID int64) (bool, error)
+	DeleteByArticleIDs(articleIDs []int64) error
+}
+
+type articleRevisionRepository struct {
+	database *internal.DB
+}
+
+var GetArticleRevisionRepository = func() func() ArticleRevisionRepository {
+	var instance ArticleRevisionRepository
+	var once sync.Once
+
+	return func() ArticleRevisionRepository {
+		once.Do(func() {
+			instance = &articleRevisionRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *articleRevisionRepository) Save(revision *models.ArticleRevision) error {
+	return r.database.Save(revision).Error
+}
+
+// FindByArticleID 는 최근 revision 부터 돌려준다. 목록에는 본문이 필요 없으므로 content 는 채우지 않는다.
+func (r *articleRevisionRepository) FindByArticleID(articleID int64) (models.ArticleRevisions, error) {
+	var revisions []*models.ArticleRevision
+	if err := r.database.
+		Select("id", "article_id", "title", "source", "created").
+		Where("article_id = ?", articleID).
+		Order("id DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *articleRevisionRepository) GetByID(id int64) (*models.ArticleRevision, error) {
+	var revision models.ArticleRevision
+	if err := r.database.First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *articleRevisionRepository) ExistByArticleID(articleID int64) (bool, error) {
+	var cnt int64
+	err := r.database.
+		Model(&models.ArticleRevision{}).
+		Where("article_id = ?", articleID).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (r *articleRevisionRepository) DeleteByArticleIDs(articleIDs []int64) error {
+	return r.database.Where("article_id IN ?", articleIDs).Delete(&models.ArticleRevision{}).Error
+}
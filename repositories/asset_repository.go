@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+)
+
+type AssetRepository interface {
+	GetByHash(hash string) (*models.Asset, error)
+	GetByArticleIDAndKind(articleID int64, kind string) (*models.Asset, error)
+	FindByArticleIDs(articleIDs []int64) (models.Assets, error)
+	CountByHash(hash string) (int64, error)
+	DeleteByIDs(ids []int64) error
+}
+
+type assetRepository struct {
+	database *internal.DB
+}
+
+var GetAssetRepository = func() func() AssetRepository {
+	var instance AssetRepository
+	var once sync.Once
+
+	return func() AssetRepository {
+		once.Do(func() {
+			instance = &assetRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *assetRepository) GetByHash(hash string) (*models.Asset, error) {
+	var asset models.Asset
+	if err := r.database.
+		Where("hash = ?", hash).
+		First(&asset).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *assetRepository) GetByArticleIDAndKind(articleID int64, kind string) (*models.Asset, error) {
+	var asset models.Asset
+	if err := r.database.
+		Where("article_id = ? AND kind = ?", articleID, kind).
+		Order("id DESC").
+		First(&asset).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *assetRepository) FindByArticleIDs(articleIDs []int64) (models.Assets, error) {
+	if len(articleIDs) == 0 {
+		return models.Assets{}, nil
+	}
+
+	var assets []*models.Asset
+	if err := r.database.
+		Where("article_id IN ?", articleIDs).
+		Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+func (r *assetRepository) CountByHash(hash string) (int64, error) {
+	var cnt int64
+	err := r.database.
+		Model(&models.Asset{}).
+		Where("hash = ?", hash).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (r *assetRepository) DeleteByIDs(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.database.Where("id IN ?", ids).Delete(&models.Asset{}).Error
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+)
+
+type ExtractionRuleRepository interface {
+	Save(rule *models.ExtractionRule) error
+	FindAll() (models.ExtractionRules, error)
+	FindByDomains(domains []string) (models.ExtractionRules, error)
+	GetByID(id int64) (*models.ExtractionRule, error)
+	ExistByDomain(domain string) (bool, error)
+	DeleteByIDs(ids []int64) error
+}
+
+type extractionRuleRepository struct {
+	database *internal.DB
+}
+
+var GetExtractionRuleRepository = func() func() ExtractionRuleRepository {
+	var instance ExtractionRuleRepository
+	var once sync.Once
+
+	return func() ExtractionRuleRepository {
+		once.Do(func() {
+			instance = &extractionRuleRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *extractionRuleRepository) Save(rule *models.ExtractionRule) error {
+	return r.database.Save(rule).Error
+}
+
+func (r *extractionRuleRepository) FindAll() (models.ExtractionRules, error) {
+	var rules models.ExtractionRules
+	if err := r.database.
+		Order("domain ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *extractionRuleRepository) FindByDomains(domains []string) (models.ExtractionRules, error) {
+	var rules models.ExtractionRules
+	if err := r.database.
+		Where("domain IN ?", domains).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *extractionRuleRepository) GetByID(id int64) (*models.ExtractionRule, error) {
+	var rule models.ExtractionRule
+	if err := r.database.
+		Where("id = ?", id).
+		First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *extractionRuleRepository) ExistByDomain(domain string) (bool, error) {
+	var count int64
+	if err := r.database.
+		Model(&models.ExtractionRule{}).
+		Where("domain = ?", domain).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *extractionRuleRepository) DeleteByIDs(ids []int64) error {
+	return r.database.
+		Where("id IN ?", ids).
+		Delete(&models.ExtractionRule{}).Error
+}
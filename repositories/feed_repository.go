@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+	"time"
+)
+
+type FeedRepository interface {
+	Save(feed *models.Feed) error
+	FindAll() (models.Feeds, error)
+	FindPolledBefore(tm time.Time) (models.Feeds, error)
+	GetByID(id int64) (*models.Feed, error)
+	ExistByURL(url string) (bool, error)
+	DeleteByIDs(ids []int64) error
+}
+
+type feedRepository struct {
+	database *internal.DB
+}
+
+var GetFeedRepository = func() func() FeedRepository {
+	var instance FeedRepository
+	var once sync.Once
+
+	return func() FeedRepository {
+		once.Do(func() {
+			instance = &feedRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *feedRepository) Save(feed *models.Feed) error {
+	return r.database.Save(feed).Error
+}
+
+func (r *feedRepository) FindAll() (models.Feeds, error) {
+	var feeds []*models.Feed
+	if err := r.database.
+		Order("title ASC").
+		Find(&feeds).Error; err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+func (r *feedRepository) FindPolledBefore(tm time.Time) (models.Feeds, error) {
+	var feeds []*models.Feed
+	if err := r.database.
+		Where("last_polled IS NULL OR last_polled < ?", tm).
+		Order("last_polled ASC").
+		Find(&feeds).Error; err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+func (r *feedRepository) GetByID(id int64) (*models.Feed, error) {
+	var feed models.Feed
+	if err := r.database.First(&feed, id).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *feedRepository) ExistByURL(url string) (bool, error) {
+	var cnt int64
+	err := r.database.
+		Model(&models.Feed{}).
+		Where("url = ?", url).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (r *feedRepository) DeleteByIDs(ids []int64) error {
+	return r.database.Where("id IN ?", ids).Delete(&models.Feed{}).Error
+}
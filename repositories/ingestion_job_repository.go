@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+	"time"
+)
+
+type IngestionJobRepository interface {
+	Save(job *models.IngestionJob) error
+	FindWithPage(state string, offset, limit int) (models.IngestionJobs, int64, error)
+	FindRunnable(now time.Time, limit int) (models.IngestionJobs, error)
+	GetByID(id int64) (*models.IngestionJob, error)
+	GetActiveByURL(url string) (*models.IngestionJob, error)
+	Transition(job *models.IngestionJob, from string) (bool, error)
+	UpdateQueued(job *models.IngestionJob) (bool, error)
+	ResetRunning() error
+}
+
+type ingestionJobRepository struct {
+	database *internal.DB
+}
+
+var GetIngestionJobRepository = func() func() IngestionJobRepository {
+	var instance IngestionJobRepository
+	var once sync.Once
+
+	return func() IngestionJobRepository {
+		once.Do(func() {
+			instance = &ingestionJobRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *ingestionJobRepository) Save(job *models.IngestionJob) error {
+	return r.database.Save(job).Error
+}
+
+// FindWithPage 는 최근 job 부터 돌려준다. state 가 비어있으면 모든 state 의 job 을 돌려준다.
+func (r *ingestionJobRepository) FindWithPage(state string, offset, limit int) (models.IngestionJobs, int64, error) {
+	tx := r.database.Model(&models.IngestionJob{})
+	if state != "" {
+		tx = tx.Where("state = ?", state)
+	}
+
+	var cnt int64
+	if err := tx.Count(&cnt).Error; err != nil {
+		return nil, -1, err
+	}
+
+	var jobs []*models.IngestionJob
+	if err := tx.
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, -1, err
+	}
+	return jobs, cnt, nil
+}
+
+func (r *ingestionJobRepository) FindRunnable(now time.Time, limit int) (models.IngestionJobs, error) {
+	var jobs []*models.IngestionJob
+	if err := r.database.
+		Where("state = ? AND next_attempt <= ?", models.IngestionJobQueued, now).
+		Order("next_attempt ASC, id ASC").
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *ingestionJobRepository) GetByID(id int64) (*models.IngestionJob, error) {
+	var job models.IngestionJob
+	if err := r.database.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetActiveByURL 은 url 의 queued 또는 running 상태인 job 을 돌려준다.
+func (r *ingestionJobRepository) GetActiveByURL(url string) (*models.IngestionJob, error) {
+	var job models.IngestionJob
+	if err := r.database.
+		Where("url = ? AND state IN ?", url, []string{models.IngestionJobQueued, models.IngestionJobRunning}).
+		First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Transition 은 저장된 job 의 state 가 아직 from 일 때만 저장하고, 그 사이 바뀌었으면 (e.g. 실행 중에 취소) false 를 돌려준다.
+func (r *ingestionJobRepository) Transition(job *models.IngestionJob, from string) (bool, error) {
+	job.LastModified = time.Now()
+	tx := r.database.
+		Model(&models.IngestionJob{}).
+		Where("id = ? AND state = ?", job.ID, from).
+		Updates(map[string]interface{}{
+			"state":         job.State,
+			"attempts":      job.Attempts,
+			"next_attempt":  job.NextAttempt,
+			"last_error":    job.LastError,
+			"article_id":    job.ArticleID,
+			"last_modified": job.LastModified,
+		})
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// UpdateQueued 는 job 이 아직 대기 중일 때만 tag, 제목 등을 저장하고, 아니면 false 를 돌려준다.
+func (r *ingestionJobRepository) UpdateQueued(job *models.IngestionJob) (bool, error) {
+	job.LastModified = time.Now()
+	tx := r.database.
+		Model(&models.IngestionJob{}).
+		Where("id = ? AND state = ?", job.ID, models.IngestionJobQueued).
+		Updates(map[string]interface{}{
+			"tags":            job.Tags,
+			"title":           job.Title,
+			"fallback_title":  job.FallbackTitle,
+			"article_created": job.ArticleCreated,
+			"favorite":        job.Favorite,
+			"archived":        job.Archived,
+			"last_modified":   job.LastModified,
+		})
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// ResetRunning 은 처리 도중 프로세스가 내려가서 running 으로 남은 job 을 다시 queue 에 넣는다.
+func (r *ingestionJobRepository) ResetRunning() error {
+	return r.database.
+		Model(&models.IngestionJob{}).
+		Where("state = ?", models.IngestionJobRunning).
+		UpdateColumn("state", models.IngestionJobQueued).Error
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+)
+
+type LinkCheckRepository interface {
+	Save(check *models.LinkCheck) error
+	FindByArticleID(articleID int64) (models.LinkChecks, error)
+	DeleteByArticleIDs(articleIDs []int64) error
+}
+
+type linkCheckRepository struct {
+	database *internal.DB
+}
+
+var GetLinkCheckRepository = func() func() LinkCheckRepository {
+	var instance LinkCheckRepository
+	var once sync.Once
+
+	return func() LinkCheckRepository {
+		once.Do(func() {
+			instance = &linkCheckRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *linkCheckRepository) Save(check *models.LinkCheck) error {
+	return r.database.Save(check).Error
+}
+
+// FindByArticleID 는 최근 기록부터 돌려준다.
+func (r *linkCheckRepository) FindByArticleID(articleID int64) (models.LinkChecks, error) {
+	var checks []*models.LinkCheck
+	if err := r.database.
+		Where("article_id = ?", articleID).
+		Order("id DESC").
+		Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (r *linkCheckRepository) DeleteByArticleIDs(articleIDs []int64) error {
+	return r.database.Where("article_id IN ?", articleIDs).Delete(&models.LinkCheck{}).Error
+}
@@ -1,48 +1,75 @@
 package mock
 
-import "github.com/jaeyo/personal-archive/models"
+import (
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"time"
+)
 
 type ArticleRepositoryMock struct {
-	OnSave                 func(article *models.Article) error
-	OnFindAllWithPage      func(offset, limit int) (models.Articles, int64, error)
-	OnFindByIDsWithPage    func(ids []int64, offset, limit int) (models.Articles, int64, error)
-	OnFindByIDs            func(ids []int64) (models.Articles, error)
-	OnGetByID              func(id int64) (*models.Article, error)
-	OnFindByTagWithPage    func(tag string, offset, limit int) (models.Articles, int64, error)
-	OnFindUntaggedWithPage func(offset, limit int) (models.Articles, int64, error)
-	OnGetUntaggedCount     func() (int64, error)
-	OnGetAllCount          func() (int64, error)
-	OnExistByTitle         func(title string) (bool, error)
-	OnExistByIDs           func(ids []int64) (bool, error)
-	OnDeleteByIDs          func(ids []int64) error
+	OnSave                    func(article *models.Article) error
+	OnFindAllWithPage         func(query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error)
+	OnFindByIDsWithPage       func(ids []int64, query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error)
+	OnFindByIDs               func(ids []int64) (models.Articles, error)
+	OnFindByKind              func(kind string) (models.Articles, error)
+	OnGetByID                 func(id int64) (*models.Article, error)
+	OnGetByCanonicalURL       func(canonicalURL string) (*models.Article, error)
+	OnFindWithoutCanonicalURL func() (models.Articles, error)
+	OnFindLinkCheckDue        func(checkedBefore time.Time, limit int) (models.Articles, error)
+	OnFindByTagWithPage       func(tag string, query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error)
+	OnFindUntaggedWithPage    func(query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error)
+	OnGetUntaggedCount        func() (int64, error)
+	OnGetAllCount             func() (int64, error)
+	OnCountByLinkStatus       func() (map[string]int64, error)
+	OnExistByTitle            func(title string) (bool, error)
+	OnExistByIDs              func(ids []int64) (bool, error)
+	OnUpdateCanonicalURL      func(id int64, canonicalURL string) error
+	OnUpdateLinkStatus        func(id int64, status string, checked time.Time) error
+	OnDeleteByIDs             func(ids []int64) error
 }
 
 func (m *ArticleRepositoryMock) Save(article *models.Article) error {
 	return m.OnSave(article)
 }
 
-func (m *ArticleRepositoryMock) FindAllWithPage(offset, limit int) (models.Articles, int64, error) {
-	return m.OnFindAllWithPage(offset, limit)
+func (m *ArticleRepositoryMock) FindAllWithPage(query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error) {
+	return m.OnFindAllWithPage(query, offset, limit)
 }
 
-func (m *ArticleRepositoryMock) FindByIDsWithPage(ids []int64, offset, limit int) (models.Articles, int64, error) {
-	return m.OnFindByIDsWithPage(ids, offset, limit)
+func (m *ArticleRepositoryMock) FindByIDsWithPage(ids []int64, query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error) {
+	return m.OnFindByIDsWithPage(ids, query, offset, limit)
 }
 
 func (m *ArticleRepositoryMock) FindByIDs(ids []int64) (models.Articles, error) {
 	return m.OnFindByIDs(ids)
 }
 
+func (m *ArticleRepositoryMock) FindByKind(kind string) (models.Articles, error) {
+	return m.OnFindByKind(kind)
+}
+
 func (m *ArticleRepositoryMock) GetByID(id int64) (*models.Article, error) {
 	return m.OnGetByID(id)
 }
 
-func (m *ArticleRepositoryMock) FindByTagWithPage(tag string, offset, limit int) (models.Articles, int64, error) {
-	return m.OnFindByTagWithPage(tag, offset, limit)
+func (m *ArticleRepositoryMock) GetByCanonicalURL(canonicalURL string) (*models.Article, error) {
+	return m.OnGetByCanonicalURL(canonicalURL)
+}
+
+func (m *ArticleRepositoryMock) FindWithoutCanonicalURL() (models.Articles, error) {
+	return m.OnFindWithoutCanonicalURL()
+}
+
+func (m *ArticleRepositoryMock) FindLinkCheckDue(checkedBefore time.Time, limit int) (models.Articles, error) {
+	return m.OnFindLinkCheckDue(checkedBefore, limit)
+}
+
+func (m *ArticleRepositoryMock) FindByTagWithPage(tag string, query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error) {
+	return m.OnFindByTagWithPage(tag, query, offset, limit)
 }
 
-func (m *ArticleRepositoryMock) FindUntaggedWithPage(offset, limit int) (models.Articles, int64, error) {
-	return m.OnFindUntaggedWithPage(offset, limit)
+func (m *ArticleRepositoryMock) FindUntaggedWithPage(query *repositories.ArticleQuery, offset, limit int) (models.Articles, int64, error) {
+	return m.OnFindUntaggedWithPage(query, offset, limit)
 }
 
 func (m *ArticleRepositoryMock) GetUntaggedCount() (int64, error) {
@@ -53,6 +80,10 @@ func (m *ArticleRepositoryMock) GetAllCount() (int64, error) {
 	return m.OnGetAllCount()
 }
 
+func (m *ArticleRepositoryMock) CountByLinkStatus() (map[string]int64, error) {
+	return m.OnCountByLinkStatus()
+}
+
 func (m *ArticleRepositoryMock) ExistByTitle(title string) (bool, error) {
 	return m.OnExistByTitle(title)
 }
@@ -61,6 +92,14 @@ func (m *ArticleRepositoryMock) ExistByIDs(ids []int64) (bool, error) {
 	return m.OnExistByIDs(ids)
 }
 
+func (m *ArticleRepositoryMock) UpdateCanonicalURL(id int64, canonicalURL string) error {
+	return m.OnUpdateCanonicalURL(id, canonicalURL)
+}
+
+func (m *ArticleRepositoryMock) UpdateLinkStatus(id int64, status string, checked time.Time) error {
+	return m.OnUpdateLinkStatus(id, status, checked)
+}
+
 func (m *ArticleRepositoryMock) DeleteByIDs(ids []int64) error {
 	return m.OnDeleteByIDs(ids)
 }
@@ -0,0 +1,31 @@
+package mock
+
+import "github.com/jaeyo/personal-archive/models"
+
+type ArticleRevisionRepositoryMock struct {
+	OnSave               func(revision *models.ArticleRevision) error
+	OnFindByArticleID    func(articleID int64) (models.ArticleRevisions, error)
+	OnGetByID            func(id int64) (*models.ArticleRevision, error)
+	OnExistByArticleID   func(articleID int64) (bool, error)
+	OnDeleteByArticleIDs func(articleIDs []int64) error
+}
+
+func (m *ArticleRevisionRepositoryMock) Save(revision *models.ArticleRevision) error {
+	return m.OnSave(revision)
+}
+
+func (m *ArticleRevisionRepositoryMock) FindByArticleID(articleID int64) (models.ArticleRevisions, error) {
+	return m.OnFindByArticleID(articleID)
+}
+
+func (m *ArticleRevisionRepositoryMock) GetByID(id int64) (*models.ArticleRevision, error) {
+	return m.OnGetByID(id)
+}
+
+func (m *ArticleRevisionRepositoryMock) ExistByArticleID(articleID int64) (bool, error) {
+	return m.OnExistByArticleID(articleID)
+}
+
+func (m *ArticleRevisionRepositoryMock) DeleteByArticleIDs(articleIDs []int64) error {
+	return m.OnDeleteByArticleIDs(articleIDs)
+}
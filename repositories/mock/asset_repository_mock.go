@@ -0,0 +1,31 @@
+package mock
+
+import "github.com/jaeyo/personal-archive/models"
+
+type AssetRepositoryMock struct {
+	OnGetByHash             func(hash string) (*models.Asset, error)
+	OnGetByArticleIDAndKind func(articleID int64, kind string) (*models.Asset, error)
+	OnFindByArticleIDs      func(articleIDs []int64) (models.Assets, error)
+	OnCountByHash           func(hash string) (int64, error)
+	OnDeleteByIDs           func(ids []int64) error
+}
+
+func (m *AssetRepositoryMock) GetByHash(hash string) (*models.Asset, error) {
+	return m.OnGetByHash(hash)
+}
+
+func (m *AssetRepositoryMock) GetByArticleIDAndKind(articleID int64, kind string) (*models.Asset, error) {
+	return m.OnGetByArticleIDAndKind(articleID, kind)
+}
+
+func (m *AssetRepositoryMock) FindByArticleIDs(articleIDs []int64) (models.Assets, error) {
+	return m.OnFindByArticleIDs(articleIDs)
+}
+
+func (m *AssetRepositoryMock) CountByHash(hash string) (int64, error) {
+	return m.OnCountByHash(hash)
+}
+
+func (m *AssetRepositoryMock) DeleteByIDs(ids []int64) error {
+	return m.OnDeleteByIDs(ids)
+}
@@ -0,0 +1,36 @@
+package mock
+
+import "github.com/jaeyo/personal-archive/models"
+
+type ExtractionRuleRepositoryMock struct {
+	OnSave          func(rule *models.ExtractionRule) error
+	OnFindAll       func() (models.ExtractionRules, error)
+	OnFindByDomains func(domains []string) (models.ExtractionRules, error)
+	OnGetByID       func(id int64) (*models.ExtractionRule, error)
+	OnExistByDomain func(domain string) (bool, error)
+	OnDeleteByIDs   func(ids []int64) error
+}
+
+func (m *ExtractionRuleRepositoryMock) Save(rule *models.ExtractionRule) error {
+	return m.OnSave(rule)
+}
+
+func (m *ExtractionRuleRepositoryMock) FindAll() (models.ExtractionRules, error) {
+	return m.OnFindAll()
+}
+
+func (m *ExtractionRuleRepositoryMock) FindByDomains(domains []string) (models.ExtractionRules, error) {
+	return m.OnFindByDomains(domains)
+}
+
+func (m *ExtractionRuleRepositoryMock) GetByID(id int64) (*models.ExtractionRule, error) {
+	return m.OnGetByID(id)
+}
+
+func (m *ExtractionRuleRepositoryMock) ExistByDomain(domain string) (bool, error) {
+	return m.OnExistByDomain(domain)
+}
+
+func (m *ExtractionRuleRepositoryMock) DeleteByIDs(ids []int64) error {
+	return m.OnDeleteByIDs(ids)
+}
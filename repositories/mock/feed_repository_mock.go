@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"github.com/jaeyo/personal-archive/models"
+	"time"
+)
+
+type FeedRepositoryMock struct {
+	OnSave             func(feed *models.Feed) error
+	OnFindAll          func() (models.Feeds, error)
+	OnFindPolledBefore func(tm time.Time) (models.Feeds, error)
+	OnGetByID          func(id int64) (*models.Feed, error)
+	OnExistByURL       func(url string) (bool, error)
+	OnDeleteByIDs      func(ids []int64) error
+}
+
+func (m *FeedRepositoryMock) Save(feed *models.Feed) error {
+	return m.OnSave(feed)
+}
+
+func (m *FeedRepositoryMock) FindAll() (models.Feeds, error) {
+	return m.OnFindAll()
+}
+
+func (m *FeedRepositoryMock) FindPolledBefore(tm time.Time) (models.Feeds, error) {
+	return m.OnFindPolledBefore(tm)
+}
+
+func (m *FeedRepositoryMock) GetByID(id int64) (*models.Feed, error) {
+	return m.OnGetByID(id)
+}
+
+func (m *FeedRepositoryMock) ExistByURL(url string) (bool, error) {
+	return m.OnExistByURL(url)
+}
+
+func (m *FeedRepositoryMock) DeleteByIDs(ids []int64) error {
+	return m.OnDeleteByIDs(ids)
+}
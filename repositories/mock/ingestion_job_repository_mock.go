@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"github.com/jaeyo/personal-archive/models"
+	"time"
+)
+
+type IngestionJobRepositoryMock struct {
+	OnSave           func(job *models.IngestionJob) error
+	OnFindWithPage   func(state string, offset, limit int) (models.IngestionJobs, int64, error)
+	OnFindRunnable   func(now time.Time, limit int) (models.IngestionJobs, error)
+	OnGetByID        func(id int64) (*models.IngestionJob, error)
+	OnGetActiveByURL func(url string) (*models.IngestionJob, error)
+	OnTransition     func(job *models.IngestionJob, from string) (bool, error)
+	OnUpdateQueued   func(job *models.IngestionJob) (bool, error)
+	OnResetRunning   func() error
+}
+
+func (m *IngestionJobRepositoryMock) Save(job *models.IngestionJob) error {
+	return m.OnSave(job)
+}
+
+func (m *IngestionJobRepositoryMock) FindWithPage(state string, offset, limit int) (models.IngestionJobs, int64, error) {
+	return m.OnFindWithPage(state, offset, limit)
+}
+
+func (m *IngestionJobRepositoryMock) FindRunnable(now time.Time, limit int) (models.IngestionJobs, error) {
+	return m.OnFindRunnable(now, limit)
+}
+
+func (m *IngestionJobRepositoryMock) GetByID(id int64) (*models.IngestionJob, error) {
+	return m.OnGetByID(id)
+}
+
+func (m *IngestionJobRepositoryMock) GetActiveByURL(url string) (*models.IngestionJob, error) {
+	return m.OnGetActiveByURL(url)
+}
+
+func (m *IngestionJobRepositoryMock) Transition(job *models.IngestionJob, from string) (bool, error) {
+	return m.OnTransition(job, from)
+}
+
+func (m *IngestionJobRepositoryMock) UpdateQueued(job *models.IngestionJob) (bool, error) {
+	return m.OnUpdateQueued(job)
+}
+
+func (m *IngestionJobRepositoryMock) ResetRunning() error {
+	return m.OnResetRunning()
+}
@@ -0,0 +1,21 @@
+package mock
+
+import "github.com/jaeyo/personal-archive/models"
+
+type LinkCheckRepositoryMock struct {
+	OnSave               func(check *models.LinkCheck) error
+	OnFindByArticleID    func(articleID int64) (models.LinkChecks, error)
+	OnDeleteByArticleIDs func(articleIDs []int64) error
+}
+
+func (m *LinkCheckRepositoryMock) Save(check *models.LinkCheck) error {
+	return m.OnSave(check)
+}
+
+func (m *LinkCheckRepositoryMock) FindByArticleID(articleID int64) (models.LinkChecks, error) {
+	return m.OnFindByArticleID(articleID)
+}
+
+func (m *LinkCheckRepositoryMock) DeleteByArticleIDs(articleIDs []int64) error {
+	return m.OnDeleteByArticleIDs(articleIDs)
+}
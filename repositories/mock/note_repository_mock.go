@@ -0,0 +1,51 @@
+package mock
+
+import "github.com/jaeyo/personal-archive/models"
+
+type NoteRepositoryMock struct {
+	OnSave              func(note *models.Note) error
+	OnFindAllWithPage   func(offset, limit int) (models.Notes, int64, error)
+	OnFindByIDsWithPage func(ids []int64, offset, limit int) (models.Notes, int64, error)
+	OnFindByIDs         func(ids []int64) (models.Notes, error)
+	OnFindTitles        func() (models.Notes, error)
+	OnGetByID           func(id int64) (*models.Note, error)
+	OnGetByTitle        func(title string) (*models.Note, error)
+	OnExistByTitle      func(title string) (bool, error)
+	OnDeleteByIDs       func(ids []int64) error
+}
+
+func (m *NoteRepositoryMock) Save(note *models.Note) error {
+	return m.OnSave(note)
+}
+
+func (m *NoteRepositoryMock) FindAllWithPage(offset, limit int) (models.Notes, int64, error) {
+	return m.OnFindAllWithPage(offset, limit)
+}
+
+func (m *NoteRepositoryMock) FindByIDsWithPage(ids []int64, offset, limit int) (models.Notes, int64, error) {
+	return m.OnFindByIDsWithPage(ids, offset, limit)
+}
+
+func (m *NoteRepositoryMock) FindByIDs(ids []int64) (models.Notes, error) {
+	return m.OnFindByIDs(ids)
+}
+
+func (m *NoteRepositoryMock) FindTitles() (models.Notes, error) {
+	return m.OnFindTitles()
+}
+
+func (m *NoteRepositoryMock) GetByID(id int64) (*models.Note, error) {
+	return m.OnGetByID(id)
+}
+
+func (m *NoteRepositoryMock) GetByTitle(title string) (*models.Note, error) {
+	return m.OnGetByTitle(title)
+}
+
+func (m *NoteRepositoryMock) ExistByTitle(title string) (bool, error) {
+	return m.OnExistByTitle(title)
+}
+
+func (m *NoteRepositoryMock) DeleteByIDs(ids []int64) error {
+	return m.OnDeleteByIDs(ids)
+}
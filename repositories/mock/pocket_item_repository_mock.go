@@ -0,0 +1,21 @@
+package mock
+
+import "github.com/jaeyo/personal-archive/models"
+
+type PocketItemRepositoryMock struct {
+	OnSave        func(item *models.PocketItem) error
+	OnGetByItemID func(itemID string) (*models.PocketItem, error)
+	OnFindPending func() (models.PocketItems, error)
+}
+
+func (m *PocketItemRepositoryMock) Save(item *models.PocketItem) error {
+	return m.OnSave(item)
+}
+
+func (m *PocketItemRepositoryMock) GetByItemID(itemID string) (*models.PocketItem, error) {
+	return m.OnGetByItemID(itemID)
+}
+
+func (m *PocketItemRepositoryMock) FindPending() (models.PocketItems, error) {
+	return m.OnFindPending()
+}
@@ -13,6 +13,7 @@ type NoteRepository interface {
 	FindByIDs(ids []int64) (models.Notes, error)
 	FindTitles() (models.Notes, error)
 	GetByID(id int64) (*models.Note, error)
+	GetByTitle(title string) (*models.Note, error)
 	ExistByTitle(title string) (bool, error)
 	DeleteByIDs(ids []int64) error
 }
@@ -131,6 +132,19 @@ func (r *noteRepository) GetByID(id int64) (*models.Note, error) {
 	return &note, err
 }
 
+func (r *noteRepository) GetByTitle(title string) (*models.Note, error) {
+	var note models.Note
+	err := r.database.
+		Preload("Paragraphs").
+		Preload("Paragraphs.ReferenceArticles").
+		Preload("Paragraphs.ReferenceWebs").
+		Where("title = ?", title).
+		First(&note).Error
+
+	ensureNoteAssociationNotNil(models.Notes{&note})
+	return &note, err
+}
+
 func (r *noteRepository) ExistByTitle(title string) (bool, error) {
 	var cnt int64
 	err := r.database.
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"sync"
+)
+
+type PocketItemRepository interface {
+	Save(item *models.PocketItem) error
+	GetByItemID(itemID string) (*models.PocketItem, error)
+	FindPending() (models.PocketItems, error)
+}
+
+type pocketItemRepository struct {
+	database *internal.DB
+}
+
+var GetPocketItemRepository = func() func() PocketItemRepository {
+	var instance PocketItemRepository
+	var once sync.Once
+
+	return func() PocketItemRepository {
+		once.Do(func() {
+			instance = &pocketItemRepository{
+				database: internal.GetDatabase(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (r *pocketItemRepository) Save(item *models.PocketItem) error {
+	return r.database.Save(item).Error
+}
+
+func (r *pocketItemRepository) GetByItemID(itemID string) (*models.PocketItem, error) {
+	var item models.PocketItem
+	if err := r.database.Where("item_id = ?", itemID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *pocketItemRepository) FindPending() (models.PocketItems, error) {
+	var items []*models.PocketItem
+	if err := r.database.Where("pending = ?", true).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -2,28 +2,52 @@ package services
 
 import (
 	"fmt"
+	"github.com/jaeyo/personal-archive/common/canonical"
+	"github.com/jaeyo/personal-archive/common/diff"
+	"github.com/jaeyo/personal-archive/common/email"
 	"github.com/jaeyo/personal-archive/models"
 	"github.com/jaeyo/personal-archive/repositories"
 	"github.com/jaeyo/personal-archive/services/generators"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 	"sync"
 )
 
+var (
+	ErrNotFetchable = errors.New("article can not be fetched")
+	ErrTitleExists  = errors.New("title already exists")
+)
+
 type ArticleService interface {
 	Initialize()
 	CreateByURL(url string, tags []string) (*models.Article, error)
-	Search(keyword string, offset, limit int) ([]*models.Article, int64, error)
+	CreateByJob(job *models.IngestionJob) (*models.Article, error)
+	CreateStub(job *models.IngestionJob, content string) (*models.Article, error)
+	CreateByEmail(message *email.Message, tags []string) (*models.Article, error)
+	CreateByFile(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error)
+	Search(keyword string, query *repositories.ArticleQuery, offset, limit int) ([]*models.Article, int64, error)
 	UpdateTitle(id int64, newTitle string) error
 	UpdateTags(id int64, tags []string) error
 	UpdateContent(id int64, content string) error
+	GetSnapshot(id int64) (*generators.RawResponse, error)
+	ReExtract(id int64) (*models.Article, error)
+	Refetch(id int64) (*models.Article, error)
+	FindRevisions(id int64) (models.ArticleRevisions, error)
+	GetRevision(id, revisionID int64) (*models.ArticleRevision, error)
+	DiffRevisions(id, fromID, toID int64) (string, error)
+	RestoreRevision(id, revisionID int64) (*models.Article, error)
 	DeleteByIDs(ids []int64) error
 }
 
 type articleService struct {
-	articleGenerator        generators.ArticleGenerator
-	articleRepository       repositories.ArticleRepository
-	articleTagRepository    repositories.ArticleTagRepository
-	articleSearchRepository repositories.ArticleSearchRepository
+	articleGenerator          generators.ArticleGenerator
+	articleRepository         repositories.ArticleRepository
+	articleTagRepository      repositories.ArticleTagRepository
+	articleSearchRepository   repositories.ArticleSearchRepository
+	articleRevisionRepository repositories.ArticleRevisionRepository
+	linkCheckRepository       repositories.LinkCheckRepository
+	assetService              AssetService
 }
 
 var GetArticleService = func() func() ArticleService {
@@ -32,10 +56,13 @@ var GetArticleService = func() func() ArticleService {
 	return func() ArticleService {
 		once.Do(func() {
 			instance = &articleService{
-				articleGenerator:        generators.GetArticleGenerator(),
-				articleRepository:       repositories.GetArticleRepository(),
-				articleTagRepository:    repositories.GetArticleTagRepository(),
-				articleSearchRepository: repositories.GetArticleSearchRepository(),
+				articleGenerator:          generators.GetArticleGenerator(),
+				articleRepository:         repositories.GetArticleRepository(),
+				articleTagRepository:      repositories.GetArticleTagRepository(),
+				articleSearchRepository:   repositories.GetArticleSearchRepository(),
+				articleRevisionRepository: repositories.GetArticleRevisionRepository(),
+				linkCheckRepository:       repositories.GetLinkCheckRepository(),
+				assetService:              GetAssetService(),
 			}
 		})
 		return instance
@@ -46,28 +73,259 @@ func (s *articleService) Initialize() {
 	if err := s.articleSearchRepository.Initialize(); err != nil {
 		panic(err)
 	}
+
+	if err := s.migrateLegacySlideShares(); err != nil {
+		logrus.Warnf("failed to migrate legacy slideshare articles: %s", err.Error())
+	}
+
+	if err := s.backfillCanonicalURLs(); err != nil {
+		logrus.Warnf("failed to backfill canonical urls: %s", err.Error())
+	}
 }
 
+// migrateLegacySlideShares 는 provider 의 html 을 그대로 저장했던 SlideShare article 을 sanitize 된 embed 로 옮긴다.
+func (s *articleService) migrateLegacySlideShares() error {
+	articles, err := s.articleRepository.FindByKind(models.KindSlideShare)
+	if err != nil {
+		return errors.Wrap(err, "failed to find slideshare articles")
+	}
+
+	for _, article := range articles {
+		if err := generators.MigrateLegacySlideShare(article); err != nil {
+			logrus.Warnf("failed to migrate slideshare article %d: %s", article.ID, err.Error())
+			continue
+		}
+		if err := s.articleRepository.Save(article); err != nil {
+			return errors.Wrapf(err, "failed to save article %d", article.ID)
+		}
+	}
+	return nil
+}
+
+// CreateByURL 은 url 의 article 을 만든다. 같은 문서의 article 이 이미 있으면 새로 만들지 않고 tags 만 더해서 돌려준다.
 func (s *articleService) CreateByURL(url string, tags []string) (*models.Article, error) {
-	article, err := s.articleGenerator.NewArticle(url, tags)
+	return s.CreateByJob(&models.IngestionJob{URL: url, Tags: tags})
+}
+
+// CreateByJob 은 ingestion job 으로 article 을 만들고, 같은 문서의 article 이 이미 있으면 그 article 에 job 을 더한다.
+func (s *articleService) CreateByJob(job *models.IngestionJob) (*models.Article, error) {
+	// 추적용 parameter 만 다른 url 은 내려받기 전에 걸러낸다
+	if canonicalURL, err := canonical.Normalize(job.URL); err == nil {
+		if existing, err := s.findByCanonicalURL(canonicalURL); err != nil {
+			return nil, err
+		} else if existing != nil {
+			return s.mergeJob(existing, job)
+		}
+	}
+
+	article, err := s.articleGenerator.NewArticle(job.URL, job.Tags)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate new article")
 	}
 
-	if err = s.articleRepository.Save(article); err != nil {
+	// redirect, canonical link 를 따라가 보니 이미 있는 문서인 경우
+	if article.CanonicalURL != nil {
+		if existing, err := s.findByCanonicalURL(*article.CanonicalURL); err != nil {
+			return nil, err
+		} else if existing != nil {
+			s.discardAssets(article)
+			return s.mergeJob(existing, job)
+		}
+	}
+
+	title := job.Title
+	if title == "" && (article.Title == "" || article.Title == article.URL || article.Title == job.URL) {
+		// 추출한 제목이 없어 url 을 제목으로 쓴 경우
+		title = job.FallbackTitle
+	}
+	if title != "" && title != article.Title {
+		// 이미 있는 제목이면 추출한 제목을 그대로 둔다
+		if exist, err := s.articleRepository.ExistByTitle(title); err != nil {
+			return nil, errors.Wrap(err, "failed to check exist by title")
+		} else if !exist {
+			article.Title = title
+		}
+	}
+
+	applyJob(article, job)
+	if err = s.save(article, models.RevisionSourceFetch); err != nil {
+		return nil, err
+	}
+
+	return article, nil
+}
+
+// CreateStub 은 url 을 내려받지 않고 content 로 article 을 만들며, content 가 비어있으면 나중에 Refetch 로 채운다.
+func (s *articleService) CreateStub(job *models.IngestionJob, content string) (*models.Article, error) {
+	article, err := s.articleGenerator.NewStub(job.URL, job.Title, content, job.Tags)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate stub article")
+	}
+
+	if existing, err := s.findByCanonicalURL(*article.CanonicalURL); err != nil {
+		return nil, err
+	} else if existing != nil {
+		s.discardAssets(article)
+		return s.mergeJob(existing, job)
+	}
+
+	applyJob(article, job)
+	if err := s.save(article, models.RevisionSourceImport); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// CreateByEmail 은 받은 메일로 article 을 만든다. 같은 Message-ID 의 메일을 이미 받았으면 그 article 을 돌려준다.
+func (s *articleService) CreateByEmail(message *email.Message, tags []string) (*models.Article, error) {
+	if existing, err := s.findByCanonicalURL(message.URL()); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return existing, nil
+	}
+
+	article, err := s.articleGenerator.NewEmail(message, tags)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate article from email")
+	}
+
+	if err := s.save(article, models.RevisionSourceEmail); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// CreateByFile 은 올린 파일로 article 을 만들고, 같은 파일을 이미 올렸으면 그 article 에 tags 만 더한다.
+func (s *articleService) CreateByFile(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error) {
+	articles, err := s.articleGenerator.NewFile(name, data, tags, splitChapters)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate article from file")
+	}
+
+	for i, article := range articles {
+		if existing, err := s.findByCanonicalURL(*article.CanonicalURL); err != nil {
+			return nil, err
+		} else if existing != nil {
+			// 이미 올린 파일이면 epub 의 이미지처럼 새로 넣어둔 asset 을 지운다
+			s.discardAssets(article)
+			if articles[i], err = s.mergeTags(existing, tags); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := s.save(article, models.RevisionSourceUpload); err != nil {
+			return nil, err
+		}
+	}
+	return articles, nil
+}
+
+// applyJob 은 import 한 항목의 원래 서비스에서의 상태를 article 에 옮긴다.
+func applyJob(article *models.Article, job *models.IngestionJob) {
+	if job.ArticleCreated != nil {
+		article.Created = *job.ArticleCreated
+	}
+	article.Favorite = job.Favorite
+	article.Archived = job.Archived
+}
+
+// discardAssets 는 저장하지 않고 버리는 article 이 asset store 에 넣어둔 파일 중 다른 article 이 참조하지 않는 것을 지운다.
+func (s *articleService) discardAssets(article *models.Article) {
+	if len(article.Assets) == 0 {
+		return
+	}
+	if err := s.assetService.DeleteUnreferenced(article.Assets); err != nil {
+		logrus.Errorf("failed to delete assets of discarded article (%s): %s", article.URL, err.Error())
+	}
+}
+
+func (s *articleService) findByCanonicalURL(canonicalURL string) (*models.Article, error) {
+	article, err := s.articleRepository.GetByCanonicalURL(canonicalURL)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get article by canonical url")
+	}
+	return article, nil
+}
+
+func (s *articleService) mergeTags(article *models.Article, tags []string) (*models.Article, error) {
+	if !addTags(article, tags) {
+		return article, nil
+	}
+
+	if err := s.articleRepository.Save(article); err != nil {
 		return nil, errors.Wrap(err, "failed to save article")
 	}
+	return article, nil
+}
+
+// mergeJob 은 이미 있는 article 에 job 의 tag 를 더하고, 즐겨찾기와 보관은 켜기만 하며 저장한 시각은 더 이를 때만 바꾼다.
+func (s *articleService) mergeJob(article *models.Article, job *models.IngestionJob) (*models.Article, error) {
+	changed := addTags(article, job.Tags)
+	if job.Favorite && !article.Favorite {
+		article.Favorite, changed = true, true
+	}
+	if job.Archived && !article.Archived {
+		article.Archived, changed = true, true
+	}
+	if job.ArticleCreated != nil && job.ArticleCreated.Before(article.Created) {
+		article.Created, changed = *job.ArticleCreated, true
+	}
+	if !changed {
+		return article, nil
+	}
 
+	if err := s.articleRepository.Save(article); err != nil {
+		return nil, errors.Wrap(err, "failed to save article")
+	}
 	return article, nil
 }
 
-func (s *articleService) Search(keyword string, offset, limit int) ([]*models.Article, int64, error) {
+// addTags 는 article 에 없는 tag 를 더하고, 더한 tag 가 있는지 돌려준다.
+func addTags(article *models.Article, tags []string) bool {
+	toBeAdded := models.Tags(tags).FilterExcluded(article.Tags)
+	for _, tag := range toBeAdded {
+		article.Tags = append(article.Tags, &models.ArticleTag{Tag: tag})
+	}
+	return len(toBeAdded) > 0
+}
+
+// backfillCanonicalURLs 는 canonical url 이 없는 예전 article 에 정규화한 url 을 채우고, 중복이면 비워둔다.
+func (s *articleService) backfillCanonicalURLs() error {
+	articles, err := s.articleRepository.FindWithoutCanonicalURL()
+	if err != nil {
+		return errors.Wrap(err, "failed to find articles without canonical url")
+	}
+
+	for _, article := range articles {
+		canonicalURL, err := canonical.Normalize(article.URL)
+		if err != nil {
+			continue
+		}
+
+		if existing, err := s.findByCanonicalURL(canonicalURL); err != nil {
+			return err
+		} else if existing != nil {
+			logrus.Warnf("article %d is a duplicate of article %d: %s", article.ID, existing.ID, canonicalURL)
+			continue
+		}
+
+		if err := s.articleRepository.UpdateCanonicalURL(article.ID, canonicalURL); err != nil {
+			return errors.Wrapf(err, "failed to update canonical url of article %d", article.ID)
+		}
+	}
+	return nil
+}
+
+func (s *articleService) Search(keyword string, query *repositories.ArticleQuery, offset, limit int) ([]*models.Article, int64, error) {
 	ids, err := s.articleSearchRepository.Search(keyword)
 	if err != nil {
 		return nil, -1, errors.Wrap(err, "failed to search")
 	}
 
-	articles, cnt, err := s.articleRepository.FindByIDsWithPage(ids, offset, limit)
+	articles, cnt, err := s.articleRepository.FindByIDsWithPage(ids, query, offset, limit)
 	if err != nil {
 		return nil, -1, errors.Wrap(err, "failed to find article by ids")
 	}
@@ -88,12 +346,13 @@ func (s *articleService) UpdateTitle(id int64, newTitle string) error {
 		return errors.Wrap(err, "failed to get article")
 	}
 
+	if err := s.ensureBaselineRevision(article); err != nil {
+		return err
+	}
+
 	article.Title = newTitle
 
-	if err := s.articleRepository.Save(article); err != nil {
-		return errors.Wrap(err, "failed to save article")
-	}
-	return nil
+	return s.save(article, models.RevisionSourceUserEdit)
 }
 
 func (s *articleService) UpdateTags(id int64, tags []string) error {
@@ -131,11 +390,185 @@ func (s *articleService) UpdateContent(id int64, content string) error {
 		return errors.Wrap(err, "failed to get article)")
 	}
 
+	if err := s.ensureBaselineRevision(article); err != nil {
+		return err
+	}
+
 	article.Content = content
+	article.UpdateStatistics()
+
+	return s.save(article, models.RevisionSourceUserEdit)
+}
+
+func (s *articleService) GetSnapshot(id int64) (*generators.RawResponse, error) {
+	asset, f, err := s.assetService.OpenSnapshot(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open snapshot")
+	}
+	defer f.Close()
+
+	raw, err := generators.ReadSnapshot(asset, f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot")
+	}
+	return raw, nil
+}
+
+func (s *articleService) ReExtract(id int64) (*models.Article, error) {
+	article, err := s.articleRepository.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get article")
+	}
+
+	raw, err := s.GetSnapshot(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get snapshot")
+	}
+
+	if err := s.ensureBaselineRevision(article); err != nil {
+		return nil, err
+	}
+
+	if err := s.articleGenerator.ReExtract(article, raw); err != nil {
+		return nil, errors.Wrap(err, "failed to re-extract article")
+	}
+
+	if err := s.save(article, models.RevisionSourceReExtract); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// Refetch 는 article 의 url 을 다시 내려받아 제목과 본문을 새로 만든다. 이전 상태는 revision 으로 남아 있다.
+func (s *articleService) Refetch(id int64) (*models.Article, error) {
+	article, err := s.articleRepository.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get article")
+	} else if !article.IsFetchable() {
+		return nil, errors.Wrapf(ErrNotFetchable, "url %s", article.URL)
+	}
+
+	if err := s.ensureBaselineRevision(article); err != nil {
+		return nil, err
+	}
+
+	if err := s.articleGenerator.Refetch(article); err != nil {
+		return nil, errors.Wrap(err, "failed to refetch article")
+	}
+
+	if err := s.save(article, models.RevisionSourceRefetch); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+func (s *articleService) FindRevisions(id int64) (models.ArticleRevisions, error) {
+	if _, err := s.articleRepository.GetByID(id); err != nil {
+		return nil, errors.Wrap(err, "failed to get article")
+	}
+
+	revisions, err := s.articleRevisionRepository.FindByArticleID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find revisions")
+	}
+	return revisions, nil
+}
+
+// GetRevision 은 article 에 속한 revision 을 돌려준다. 다른 article 의 revision 이면 gorm.ErrRecordNotFound 이다.
+func (s *articleService) GetRevision(id, revisionID int64) (*models.ArticleRevision, error) {
+	revision, err := s.articleRevisionRepository.GetByID(revisionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get revision")
+	} else if revision.ArticleID != id {
+		return nil, errors.Wrapf(gorm.ErrRecordNotFound, "revision %d is not of article %d", revisionID, id)
+	}
+	return revision, nil
+}
 
+// DiffRevisions 는 두 revision 사이의 변경을 unified diff 로 돌려준다. 제목이 바뀌었으면 첫 줄에 함께 나온다.
+func (s *articleService) DiffRevisions(id, fromID, toID int64) (string, error) {
+	from, err := s.GetRevision(id, fromID)
+	if err != nil {
+		return "", err
+	}
+	to, err := s.GetRevision(id, toID)
+	if err != nil {
+		return "", err
+	}
+
+	return diff.Unified(
+		fmt.Sprintf("revision/%d", from.ID),
+		fmt.Sprintf("revision/%d", to.ID),
+		revisionText(from),
+		revisionText(to),
+		3,
+	), nil
+}
+
+func revisionText(revision *models.ArticleRevision) string {
+	return "# " + revision.Title + "\n\n" + revision.Content
+}
+
+// RestoreRevision 은 article 의 제목과 본문을 revision 의 것으로 되돌린다. 되돌린 것도 새 revision 으로 남는다.
+func (s *articleService) RestoreRevision(id, revisionID int64) (*models.Article, error) {
+	revision, err := s.GetRevision(id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := s.articleRepository.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get article")
+	}
+
+	if revision.Title != article.Title {
+		exist, err := s.articleRepository.ExistByTitle(revision.Title)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check exist by title")
+		} else if exist {
+			return nil, errors.Wrapf(ErrTitleExists, "title %s", revision.Title)
+		}
+	}
+
+	if err := s.ensureBaselineRevision(article); err != nil {
+		return nil, err
+	}
+
+	article.Title = revision.Title
+	article.Content = revision.Content
+	article.UpdateStatistics()
+
+	if err := s.save(article, models.RevisionSourceRestore); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// ensureBaselineRevision 은 revision 을 남기기 전에 만들어진 article 이 처음 바뀌기 전에, 바뀌기 전 상태를 남겨둔다.
+func (s *articleService) ensureBaselineRevision(article *models.Article) error {
+	exist, err := s.articleRevisionRepository.ExistByArticleID(article.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to check exist revision")
+	} else if exist {
+		return nil
+	}
+
+	if err := s.articleRevisionRepository.Save(models.NewArticleRevision(article, models.RevisionSourceInitial)); err != nil {
+		return errors.Wrap(err, "failed to save initial revision")
+	}
+	return nil
+}
+
+// save 는 article 과 source 의 revision 을 저장하고, 실패하면 먼저 보관해둔 asset 파일을 지운다.
+func (s *articleService) save(article *models.Article, source string) error {
 	if err := s.articleRepository.Save(article); err != nil {
+		s.discardAssets(article)
 		return errors.Wrap(err, "failed to save article")
 	}
+
+	if err := s.articleRevisionRepository.Save(models.NewArticleRevision(article, source)); err != nil {
+		return errors.Wrap(err, "failed to save revision")
+	}
 	return nil
 }
 
@@ -151,8 +584,20 @@ func (s *articleService) DeleteByIDs(ids []int64) error {
 		return errors.Wrap(err, "failed to delete article tag by ids")
 	}
 
+	if err := s.articleRevisionRepository.DeleteByArticleIDs(ids); err != nil {
+		return errors.Wrap(err, "failed to delete revisions by article ids")
+	}
+
+	if err := s.linkCheckRepository.DeleteByArticleIDs(ids); err != nil {
+		return errors.Wrap(err, "failed to delete link checks by article ids")
+	}
+
 	if err := s.articleRepository.DeleteByIDs(ids); err != nil {
 		return errors.Wrap(err, "failed to delete article by ids")
 	}
+
+	if err := s.assetService.DeleteByArticleIDs(ids); err != nil {
+		return errors.Wrap(err, "failed to delete assets by article ids")
+	}
 	return nil
 }
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"github.com/jaeyo/personal-archive/internal"
 	"github.com/jaeyo/personal-archive/models"
 	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 func TestUpdateTitle(t *testing.T) {
@@ -27,7 +32,227 @@ func TestUpdateTitle(t *testing.T) {
 			return nil
 		},
 	}
+	var revisions []*models.ArticleRevision
+	svc.articleRevisionRepository = &mock.ArticleRevisionRepositoryMock{
+		OnExistByArticleID: func(articleID int64) (bool, error) { return true, nil },
+		OnSave: func(revision *models.ArticleRevision) error {
+			revisions = append(revisions, revision)
+			return nil
+		},
+	}
 	err = svc.UpdateTitle(-1, "new title")
 	require.NoError(t, err)
 	require.Equal(t, savedArticle.Title, "new title")
+	require.Len(t, revisions, 1)
+	require.Equal(t, models.RevisionSourceUserEdit, revisions[0].Source)
+}
+
+func TestCreateByURLWithDuplicate(t *testing.T) {
+	existing := &models.Article{ID: 1, Tags: models.ArticleTags{{Tag: "go"}}}
+	var saved *models.Article
+	var deleted []string
+	svc := &articleService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) {
+				if canonicalURL == "https://example.com/post" {
+					return existing, nil
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+			OnSave: func(article *models.Article) error {
+				saved = article
+				return nil
+			},
+		},
+		articleGenerator: &generators.ArticleGeneratorMock{
+			OnNewArticle: func(url string, tags []string) (*models.Article, error) {
+				canonicalURL := "https://example.com/post"
+				return &models.Article{URL: url, CanonicalURL: &canonicalURL, Assets: models.Assets{{Hash: "shared"}, {Hash: "new"}}}, nil
+			},
+		},
+		assetService: &assetService{
+			assetRepository: &mock.AssetRepositoryMock{
+				OnCountByHash: func(hash string) (int64, error) {
+					if hash == "shared" {
+						return 1, nil
+					}
+					return 0, nil
+				},
+			},
+			assetStore: &internal.AssetStoreMock{
+				OnDelete: func(hash string) error {
+					deleted = append(deleted, hash)
+					return nil
+				},
+			},
+		},
+	}
+
+	// case 1: tracking parameter 만 다른 url
+	article, err := svc.CreateByURL("https://example.com/post?utm_source=feed", []string{"go", "pocket"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), article.ID)
+	require.Equal(t, existing, saved)
+	require.True(t, article.Tags.ContainTag("pocket"))
+	require.Len(t, article.Tags, 2)
+
+	// case 2: redirect 후 canonical url 이 같은 url, 내려받으며 넣어둔 asset 중 다른 article 이 참조하지 않는 것은 지운다
+	saved = nil
+	article, err = svc.CreateByURL("https://short.example.com/abc", []string{"go"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), article.ID)
+	require.Nil(t, saved)
+	require.Equal(t, []string{"new"}, deleted)
+}
+
+func TestCreateByURLWithSaveFailure(t *testing.T) {
+	var deleted []string
+	svc := &articleService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+			OnSave:              func(article *models.Article) error { return errors.New("database is locked") },
+		},
+		articleGenerator: &generators.ArticleGeneratorMock{
+			OnNewArticle: func(url string, tags []string) (*models.Article, error) {
+				return &models.Article{URL: url, Title: "title", Assets: models.Assets{{Hash: "image"}, {Hash: "snapshot"}}}, nil
+			},
+		},
+		assetService: &assetService{
+			assetRepository: &mock.AssetRepositoryMock{
+				OnCountByHash: func(hash string) (int64, error) { return 0, nil },
+			},
+			assetStore: &internal.AssetStoreMock{
+				OnDelete: func(hash string) error {
+					deleted = append(deleted, hash)
+					return nil
+				},
+			},
+		},
+	}
+
+	// 저장하지 못한 article 의 asset 파일은 지운다
+	_, err := svc.CreateByURL("https://example.com/post", nil)
+	require.Error(t, err)
+	require.Equal(t, []string{"image", "snapshot"}, deleted)
+}
+
+func TestCreateByJobWithFallbackTitle(t *testing.T) {
+	var extractedTitle string
+	svc := &articleService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+			OnExistByTitle:      func(title string) (bool, error) { return false, nil },
+			OnSave:              func(article *models.Article) error { return nil },
+		},
+		articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+			OnSave: func(revision *models.ArticleRevision) error { return nil },
+		},
+		articleGenerator: &generators.ArticleGeneratorMock{
+			OnNewArticle: func(url string, tags []string) (*models.Article, error) {
+				return &models.Article{URL: url, Title: extractedTitle}, nil
+			},
+		},
+	}
+	job := &models.IngestionJob{URL: "https://example.com/post", FallbackTitle: "pocket title"}
+
+	// case 1: 추출한 제목이 없어 url 이 제목이 된 경우
+	extractedTitle = job.URL
+	article, err := svc.CreateByJob(job)
+	require.NoError(t, err)
+	require.Equal(t, "pocket title", article.Title)
+
+	// case 2: 추출한 제목이 있는 경우
+	extractedTitle = "extracted"
+	article, err = svc.CreateByJob(job)
+	require.NoError(t, err)
+	require.Equal(t, "extracted", article.Title)
+}
+
+func TestCreateByJobWithExistingArticle(t *testing.T) {
+	created := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing := &models.Article{ID: 3, URL: "https://example.com/post", Created: created, Tags: models.ArticleTags{{Tag: "go"}}}
+	saved := 0
+	svc := &articleService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return existing, nil },
+			OnSave: func(article *models.Article) error {
+				saved++
+				return nil
+			},
+		},
+	}
+
+	// case 1: 이미 있는 article 에 tag, 즐겨찾기, 보관 여부, 더 이른 저장 시각을 더한다
+	earlier := created.Add(-time.Hour)
+	article, err := svc.CreateByJob(&models.IngestionJob{URL: existing.URL, Tags: models.StringList{"go", "web"}, Favorite: true, Archived: true, ArticleCreated: &earlier})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), article.ID)
+	require.Len(t, article.Tags, 2)
+	require.True(t, article.Favorite)
+	require.True(t, article.Archived)
+	require.Equal(t, earlier, article.Created)
+	require.Equal(t, 1, saved)
+
+	// case 2: 즐겨찾기, 보관을 끄지 않는다
+	_, err = svc.CreateByJob(&models.IngestionJob{URL: existing.URL})
+	require.NoError(t, err)
+	require.True(t, article.Favorite)
+	require.True(t, article.Archived)
+	require.Equal(t, 1, saved)
+}
+
+func TestRestoreRevision(t *testing.T) {
+	article := &models.Article{ID: 1, Title: "title", Content: "edited"}
+	var revisions []*models.ArticleRevision
+	svc := &articleService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByID:      func(id int64) (*models.Article, error) { return article, nil },
+			OnSave:         func(article *models.Article) error { return nil },
+			OnExistByTitle: func(title string) (bool, error) { return true, nil },
+		},
+		articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+			OnGetByID: func(id int64) (*models.ArticleRevision, error) {
+				return &models.ArticleRevision{ID: id, ArticleID: id, Title: "title", Content: "original"}, nil
+			},
+			OnExistByArticleID: func(articleID int64) (bool, error) { return len(revisions) > 0, nil },
+			OnSave: func(revision *models.ArticleRevision) error {
+				revisions = append(revisions, revision)
+				return nil
+			},
+		},
+	}
+
+	// case 1: 다른 article 의 revision
+	_, err := svc.RestoreRevision(1, 2)
+	require.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// case 2: 되돌릴 제목을 다른 article 이 쓰고 있다
+	article.Title = "renamed"
+	_, err = svc.RestoreRevision(1, 1)
+	require.True(t, errors.Is(err, ErrTitleExists))
+	article.Title = "title"
+
+	// case 3: 기록이 없던 article 은 되돌리기 전 상태도 남는다
+	restored, err := svc.RestoreRevision(1, 1)
+	require.NoError(t, err)
+	require.Equal(t, "original", restored.Content)
+	require.Len(t, revisions, 2)
+	require.Equal(t, models.RevisionSourceInitial, revisions[0].Source)
+	require.Equal(t, "edited", revisions[0].Content)
+	require.Equal(t, models.RevisionSourceRestore, revisions[1].Source)
+	require.Equal(t, "original", revisions[1].Content)
+}
+
+func TestRefetchNotFetchable(t *testing.T) {
+	svc := &articleService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByID: func(id int64) (*models.Article, error) {
+				return &models.Article{ID: id, URL: "file:///book.epub"}, nil
+			},
+		},
+	}
+
+	// 올린 파일처럼 웹에서 가져오지 않은 article 은 다시 내려받을 수 없다
+	_, err := svc.Refetch(1)
+	require.True(t, errors.Is(err, ErrNotFetchable))
 }
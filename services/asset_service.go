@@ -0,0 +1,98 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"os"
+	"sync"
+)
+
+type AssetService interface {
+	Open(hash string) (*models.Asset, *os.File, error)
+	OpenSnapshot(articleID int64) (*models.Asset, *os.File, error)
+	DeleteByArticleIDs(articleIDs []int64) error
+	DeleteUnreferenced(assets models.Assets) error
+}
+
+type assetService struct {
+	assetRepository repositories.AssetRepository
+	assetStore      internal.AssetStore
+}
+
+var GetAssetService = func() func() AssetService {
+	var once sync.Once
+	var instance AssetService
+	return func() AssetService {
+		once.Do(func() {
+			instance = &assetService{
+				assetRepository: repositories.GetAssetRepository(),
+				assetStore:      internal.GetAssetStore(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (s *assetService) Open(hash string) (*models.Asset, *os.File, error) {
+	asset, err := s.assetRepository.GetByHash(hash)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get asset")
+	}
+
+	f, err := s.assetStore.Open(hash)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to open asset file")
+	}
+	return asset, f, nil
+}
+
+// OpenSnapshot 은 article 의 원본 응답을 연다. pdf article 은 원본을 attachment 로만 보관하므로 attachment 를 대신 연다.
+func (s *assetService) OpenSnapshot(articleID int64) (*models.Asset, *os.File, error) {
+	asset, err := s.assetRepository.GetByArticleIDAndKind(articleID, models.AssetKindSnapshot)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		asset, err = s.assetRepository.GetByArticleIDAndKind(articleID, models.AssetKindAttachment)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get snapshot")
+	}
+
+	f, err := s.assetStore.Open(asset.Hash)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to open snapshot file")
+	}
+	return asset, f, nil
+}
+
+// DeleteByArticleIDs 는 article 들의 asset 을 지우고, 더 이상 어떤 article 도 참조하지 않는 파일을 정리한다.
+func (s *assetService) DeleteByArticleIDs(articleIDs []int64) error {
+	assets, err := s.assetRepository.FindByArticleIDs(articleIDs)
+	if err != nil {
+		return errors.Wrap(err, "failed to find assets")
+	}
+
+	if err := s.assetRepository.DeleteByIDs(assets.ExtractIDs()); err != nil {
+		return errors.Wrap(err, "failed to delete assets by ids")
+	}
+	return s.DeleteUnreferenced(assets)
+}
+
+// DeleteUnreferenced 는 assets 의 파일 중 더 이상 어떤 article 도 참조하지 않는 파일을 지운다.
+func (s *assetService) DeleteUnreferenced(assets models.Assets) error {
+	for _, hash := range assets.ExtractHashes() {
+		cnt, err := s.assetRepository.CountByHash(hash)
+		if err != nil {
+			return errors.Wrap(err, "failed to count assets by hash")
+		} else if cnt > 0 {
+			continue
+		}
+
+		if err := s.assetStore.Delete(hash); err != nil {
+			logrus.Errorf("failed to delete asset file (%s): %s", hash, err.Error())
+		}
+	}
+	return nil
+}
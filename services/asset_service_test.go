@@ -0,0 +1,48 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDeleteAssetsByArticleIDs(t *testing.T) {
+	var deletedIDs []int64
+	var deletedHashes []string
+
+	svc := &assetService{
+		assetRepository: &mock.AssetRepositoryMock{
+			OnFindByArticleIDs: func(articleIDs []int64) (models.Assets, error) {
+				return models.Assets{
+					{ID: 1, ArticleID: 10, Hash: "shared"},
+					{ID: 2, ArticleID: 10, Hash: "owned"},
+					{ID: 3, ArticleID: 11, Hash: "owned"},
+				}, nil
+			},
+			OnDeleteByIDs: func(ids []int64) error {
+				deletedIDs = ids
+				return nil
+			},
+			OnCountByHash: func(hash string) (int64, error) {
+				// 다른 article 이 아직 참조하고 있는 경우
+				if hash == "shared" {
+					return 1, nil
+				}
+				return 0, nil
+			},
+		},
+		assetStore: &internal.AssetStoreMock{
+			OnDelete: func(hash string) error {
+				deletedHashes = append(deletedHashes, hash)
+				return nil
+			},
+		},
+	}
+
+	err := svc.DeleteByArticleIDs([]int64{10, 11})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3}, deletedIDs)
+	require.Equal(t, []string{"owned"}, deletedHashes)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common"
+	"github.com/jaeyo/personal-archive/common/email"
+	"github.com/jaeyo/personal-archive/common/smtpd"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+)
+
+type EmailService interface {
+	Start()
+	Receive(from string, to []string, data []byte) error
+}
+
+type emailService struct {
+	articleService ArticleService
+	addr           string
+	recipients     []string
+	allowedSenders []string
+}
+
+var GetEmailService = func() func() EmailService {
+	var once sync.Once
+	var instance EmailService
+	return func() EmailService {
+		once.Do(func() {
+			instance = &emailService{
+				articleService: GetArticleService(),
+				addr:           common.SMTPAddr(),
+				recipients:     common.SMTPRecipients(),
+				allowedSenders: common.SMTPAllowedSenders(),
+			}
+		})
+		return instance
+	}
+}()
+
+// Start 는 SMTP_ADDR, SMTP_RECIPIENTS, SMTP_ALLOWED_SENDERS 가 모두 설정되어 있을 때만 뉴스레터를 받을 SMTP 서버를 띄운다.
+func (s *emailService) Start() {
+	if s.addr == "" {
+		return
+	} else if len(s.recipients) == 0 {
+		logrus.Warnf("SMTP_RECIPIENTS is empty, smtp server not started")
+		return
+	} else if len(s.allowedSenders) == 0 {
+		logrus.Warnf("SMTP_ALLOWED_SENDERS is empty, smtp server not started")
+		return
+	}
+
+	server := &smtpd.Server{
+		Addr:            s.addr,
+		AcceptRecipient: s.isRecipient,
+		Handler:         s.Receive,
+	}
+	go func() {
+		logrus.Infof("smtp server listening on %s", s.addr)
+		if err := server.ListenAndServe(); err != nil {
+			logrus.Errorf("smtp server stopped: %s", err.Error())
+		}
+	}()
+}
+
+// Receive 는 MAIL FROM 과 From header 가 모두 허용한 발신자인 메일을 발신자 tag 를 붙인 article 로 저장한다.
+func (s *emailService) Receive(from string, to []string, data []byte) error {
+	message, err := email.Parse(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse email")
+	}
+
+	if !s.isAllowedSender(from) {
+		return fmt.Errorf("sender not allowed: %s", from)
+	}
+	sender := from
+	if message.From != nil {
+		sender = message.From.Address
+	}
+	if !s.isAllowedSender(sender) {
+		return fmt.Errorf("sender not allowed: %s", sender)
+	}
+
+	article, err := s.articleService.CreateByEmail(message, senderTags(sender))
+	if err != nil {
+		return errors.Wrap(err, "failed to create article by email")
+	}
+	logrus.Infof("archived email %s from %s as article %d", message.MessageID, sender, article.ID)
+	return nil
+}
+
+func (s *emailService) isRecipient(address string) bool {
+	for _, recipient := range s.recipients {
+		if strings.EqualFold(recipient, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedSender 는 address 가 허용한 주소이거나 허용한 도메인 (`example.com`, `@example.com`) 의 주소인지 확인한다.
+func (s *emailService) isAllowedSender(address string) bool {
+	if address == "" {
+		return false
+	}
+
+	address = strings.ToLower(address)
+	domain := address[strings.LastIndex(address, "@")+1:]
+	for _, allowed := range s.allowedSenders {
+		allowed = strings.ToLower(allowed)
+		if allowed == address || strings.TrimPrefix(allowed, "@") == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// senderTags 는 발신자 주소를 tag 로 쓴다. tag 로 쓰기에 너무 길면 도메인을 쓴다.
+func senderTags(sender string) []string {
+	tag := strings.ToLower(sender)
+	if err := models.ValidateTags([]string{tag}); err != nil {
+		tag = tag[strings.LastIndex(tag, "@")+1:]
+	}
+	if tag == "" || models.ValidateTags([]string{tag}) != nil {
+		return []string{}
+	}
+	return []string{tag}
+}
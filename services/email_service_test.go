@@ -0,0 +1,84 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/common/email"
+	"github.com/jaeyo/personal-archive/common/smtpd"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"net"
+	"net/smtp"
+	"testing"
+)
+
+func TestEmailReceive(t *testing.T) {
+	var saved []*models.Article
+	articleRepository := &mock.ArticleRepositoryMock{
+		OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+		OnSave: func(article *models.Article) error {
+			saved = append(saved, article)
+			return nil
+		},
+	}
+	svc := &emailService{
+		articleService: &articleService{
+			articleRepository: articleRepository,
+			articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+				OnSave: func(revision *models.ArticleRevision) error { return nil },
+			},
+			articleGenerator: &generators.ArticleGeneratorMock{
+				OnNewEmail: func(message *email.Message, tags []string) (*models.Article, error) {
+					return models.NewArticle(models.KindEmail, message.URL(), message.Text, message.Subject, tags), nil
+				},
+			},
+		},
+		recipients:     []string{"archive@localhost"},
+		allowedSenders: []string{"@example.com"},
+	}
+
+	server := &smtpd.Server{AcceptRecipient: svc.isRecipient, Handler: svc.Receive}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+	addr := listener.Addr().String()
+
+	// case 1: 허용한 도메인의 발신자
+	err = smtp.SendMail(addr, nil, "bounce@example.com", []string{"archive@localhost"},
+		[]byte("From: Weekly <News@Example.com>\r\nSubject: Issue 1\r\nMessage-ID: <1@example.com>\r\n\r\nhello\r\n"))
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, "Issue 1", saved[0].Title)
+	require.Equal(t, "mid:1@example.com", saved[0].URL)
+	require.True(t, saved[0].Tags.ContainTag("news@example.com"))
+
+	// case 2: 허용하지 않은 발신자
+	err = smtp.SendMail(addr, nil, "spam@spam.net", []string{"archive@localhost"},
+		[]byte("From: spam@spam.net\r\nSubject: buy\r\n\r\nbuy now\r\n"))
+	require.Error(t, err)
+
+	// case 3: header 의 From 만 허용한 발신자인 경우
+	err = smtp.SendMail(addr, nil, "spam@spam.net", []string{"archive@localhost"},
+		[]byte("From: news@example.com\r\nSubject: fake\r\n\r\nbuy now\r\n"))
+	require.Error(t, err)
+
+	// case 4: envelope 의 발신자만 허용한 발신자인 경우
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"archive@localhost"},
+		[]byte("From: spam@spam.net\r\nSubject: fake\r\n\r\nbuy now\r\n"))
+	require.Error(t, err)
+
+	// case 5: 받지 않는 주소
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"other@localhost"},
+		[]byte("From: news@example.com\r\nSubject: Issue 2\r\n\r\nhello\r\n"))
+	require.Error(t, err)
+	require.Len(t, saved, 1)
+
+	// case 6: 허용한 발신자가 없으면 모두 거절한다
+	svc.allowedSenders = nil
+	err = smtp.SendMail(addr, nil, "news@example.com", []string{"archive@localhost"},
+		[]byte("From: news@example.com\r\nSubject: Issue 3\r\n\r\nhello\r\n"))
+	require.Error(t, err)
+	require.Len(t, saved, 1)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"github.com/andybalholm/cascadia"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidExtractionRule = errors.New("invalid extraction rule")
+	ErrExtractionRuleExists  = errors.New("extraction rule already exists")
+)
+
+type ExtractionRuleService interface {
+	Create(rule *models.ExtractionRule) error
+	Update(id int64, rule *models.ExtractionRule) error
+	DeleteByIDs(ids []int64) error
+	DryRun(url string, rule *models.ExtractionRule) (*generators.FetchedArticle, error)
+}
+
+type extractionRuleService struct {
+	extractionRuleRepository repositories.ExtractionRuleRepository
+}
+
+var GetExtractionRuleService = func() func() ExtractionRuleService {
+	var once sync.Once
+	var instance ExtractionRuleService
+	return func() ExtractionRuleService {
+		once.Do(func() {
+			instance = &extractionRuleService{
+				extractionRuleRepository: repositories.GetExtractionRuleRepository(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (s *extractionRuleService) Create(rule *models.ExtractionRule) error {
+	if err := normalizeExtractionRule(rule); err != nil {
+		return errors.Wrap(ErrInvalidExtractionRule, err.Error())
+	}
+
+	exist, err := s.extractionRuleRepository.ExistByDomain(rule.Domain)
+	if err != nil {
+		return errors.Wrap(err, "failed to check exist by domain")
+	} else if exist {
+		return errors.Wrapf(ErrExtractionRuleExists, "domain %s", rule.Domain)
+	}
+
+	if err := s.extractionRuleRepository.Save(rule); err != nil {
+		return errors.Wrap(err, "failed to save extraction rule")
+	}
+	return nil
+}
+
+func (s *extractionRuleService) Update(id int64, rule *models.ExtractionRule) error {
+	if err := normalizeExtractionRule(rule); err != nil {
+		return errors.Wrap(ErrInvalidExtractionRule, err.Error())
+	}
+
+	saved, err := s.extractionRuleRepository.GetByID(id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get extraction rule")
+	}
+
+	if saved.Domain != rule.Domain {
+		exist, err := s.extractionRuleRepository.ExistByDomain(rule.Domain)
+		if err != nil {
+			return errors.Wrap(err, "failed to check exist by domain")
+		} else if exist {
+			return errors.Wrapf(ErrExtractionRuleExists, "domain %s", rule.Domain)
+		}
+	}
+
+	saved.Domain = rule.Domain
+	saved.ContentSelector = rule.ContentSelector
+	saved.RemoveSelector = rule.RemoveSelector
+	saved.TitleSelector = rule.TitleSelector
+	saved.AuthorSelector = rule.AuthorSelector
+
+	if err := s.extractionRuleRepository.Save(saved); err != nil {
+		return errors.Wrap(err, "failed to save extraction rule")
+	}
+	return nil
+}
+
+func (s *extractionRuleService) DeleteByIDs(ids []int64) error {
+	if err := s.extractionRuleRepository.DeleteByIDs(ids); err != nil {
+		return errors.Wrap(err, "failed to delete extraction rules")
+	}
+	return nil
+}
+
+// DryRun 은 rule 을 저장하지 않고 url 에 적용한 결과를 돌려준다. rule 이 nil 이면 저장된 rule 중 일치하는 것을 적용한다.
+func (s *extractionRuleService) DryRun(url string, rule *models.ExtractionRule) (*generators.FetchedArticle, error) {
+	if rule != nil {
+		if err := validateSelectors(rule); err != nil {
+			return nil, errors.Wrap(ErrInvalidExtractionRule, err.Error())
+		}
+	}
+
+	fetched, err := generators.PreviewExtraction(url, rule)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract")
+	}
+	return fetched, nil
+}
+
+func normalizeExtractionRule(rule *models.ExtractionRule) error {
+	domain := strings.ToLower(strings.TrimSpace(rule.Domain))
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+	domain = strings.TrimSuffix(domain, "/")
+	if domain == "" || strings.ContainsAny(domain, "/ ") {
+		return fmt.Errorf("invalid domain: %s", rule.Domain)
+	}
+	rule.Domain = domain
+
+	return validateSelectors(rule)
+}
+
+func validateSelectors(rule *models.ExtractionRule) error {
+	for _, selector := range []string{rule.ContentSelector, rule.RemoveSelector, rule.TitleSelector, rule.AuthorSelector} {
+		if strings.TrimSpace(selector) == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return errors.Wrapf(err, "invalid selector: %s", selector)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/feed"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/common/opml"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/pkg/errors"
+	"net/http"
+	"sync"
+)
+
+type FeedService interface {
+	Create(url string, tags []string) (*models.Feed, error)
+	Update(id int64, title string, tags []string) error
+	DeleteByIDs(ids []int64) error
+	ImportOPML(data []byte) (int, error)
+	ExportOPML() ([]byte, error)
+}
+
+type feedService struct {
+	feedRepository repositories.FeedRepository
+}
+
+var GetFeedService = func() func() FeedService {
+	var once sync.Once
+	var instance FeedService
+	return func() FeedService {
+		once.Do(func() {
+			instance = &feedService{
+				feedRepository: repositories.GetFeedRepository(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (s *feedService) Create(url string, tags []string) (*models.Feed, error) {
+	exist, err := s.feedRepository.ExistByURL(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check exist by url")
+	} else if exist {
+		return nil, fmt.Errorf("feed %s already exists", url)
+	}
+
+	resp, err := requestFeed(url, "", "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request feed")
+	}
+
+	title := resp.feed.Title
+	if title == "" {
+		title = url
+	}
+
+	f := &models.Feed{
+		URL:   url,
+		Title: title,
+		Tags:  ensureTags(tags),
+	}
+	if err := s.feedRepository.Save(f); err != nil {
+		return nil, errors.Wrap(err, "failed to save feed")
+	}
+	return f, nil
+}
+
+func (s *feedService) Update(id int64, title string, tags []string) error {
+	f, err := s.feedRepository.GetByID(id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get feed")
+	}
+
+	if title != "" {
+		f.Title = title
+	}
+	f.Tags = ensureTags(tags)
+
+	if err := s.feedRepository.Save(f); err != nil {
+		return errors.Wrap(err, "failed to save feed")
+	}
+	return nil
+}
+
+func (s *feedService) DeleteByIDs(ids []int64) error {
+	if err := s.feedRepository.DeleteByIDs(ids); err != nil {
+		return errors.Wrap(err, "failed to delete feeds by ids")
+	}
+	return nil
+}
+
+// ImportOPML 은 OPML 의 구독 목록을 feed 로 등록한다. 상위 폴더 이름은 feed 의 태그가 된다.
+func (s *feedService) ImportOPML(data []byte) (int, error) {
+	subscriptions, err := opml.Parse(data)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse opml")
+	}
+
+	imported := 0
+	for _, subscription := range subscriptions {
+		exist, err := s.feedRepository.ExistByURL(subscription.XMLURL)
+		if err != nil {
+			return imported, errors.Wrap(err, "failed to check exist by url")
+		} else if exist {
+			continue
+		}
+
+		title := subscription.Title
+		if title == "" {
+			title = subscription.XMLURL
+		}
+		if err := s.feedRepository.Save(&models.Feed{
+			URL:   subscription.XMLURL,
+			Title: title,
+			Tags:  ensureTags(subscription.Categories),
+		}); err != nil {
+			return imported, errors.Wrap(err, "failed to save feed")
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+func (s *feedService) ExportOPML() ([]byte, error) {
+	feeds, err := s.feedRepository.FindAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find feeds")
+	}
+
+	var subscriptions []*opml.Subscription
+	for _, f := range feeds {
+		subscriptions = append(subscriptions, &opml.Subscription{
+			Title:      f.Title,
+			XMLURL:     f.URL,
+			Categories: f.Tags,
+		})
+	}
+	return opml.Marshal("personal-archive feeds", subscriptions)
+}
+
+type feedResponse struct {
+	feed         *feed.Feed
+	etag         string
+	lastModified string
+	notModified  bool
+}
+
+func requestFeed(url, etag, lastModified string) (*feedResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := fetch.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request feed")
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &feedResponse{etag: etag, lastModified: lastModified, notModified: true}, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+
+	parsed, err := feed.Parse(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse feed")
+	}
+
+	return &feedResponse{
+		feed:         parsed,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func ensureTags(tags []string) models.StringList {
+	if tags == nil {
+		return models.StringList{}
+	}
+	return tags
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/common/feed"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	feedPollInterval = 30 * time.Minute
+	// 처음 구독한 feed 는 과거 글 전체 대신 최근 글 몇 개만 가져온다
+	feedInitialItems    = 10
+	feedMaxItemsPerPoll = 30
+)
+
+type FeedSyncService interface {
+	Start()
+}
+
+type feedSyncService struct {
+	feedRepository   repositories.FeedRepository
+	ingestionService IngestionService
+}
+
+var GetFeedSyncService = func() func() FeedSyncService {
+	var once sync.Once
+	var instance FeedSyncService
+	return func() FeedSyncService {
+		once.Do(func() {
+			instance = &feedSyncService{
+				feedRepository:   repositories.GetFeedRepository(),
+				ingestionService: GetIngestionService(),
+			}
+		})
+		return instance
+	}
+}()
+
+func (s *feedSyncService) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			s.sync()
+		}
+	}()
+}
+
+func (s *feedSyncService) sync() {
+	feeds, err := s.feedRepository.FindPolledBefore(time.Now().Add(-feedPollInterval))
+	if err != nil {
+		logrus.Errorf("failed to find feeds to poll: %s", err.Error())
+		return
+	}
+
+	for _, f := range feeds {
+		s.poll(f)
+	}
+}
+
+func (s *feedSyncService) poll(f *models.Feed) {
+	logrus.Infof("start to poll feed (%s)", f.URL)
+
+	now := time.Now()
+	f.LastPolled = &now
+
+	resp, err := requestFeed(f.URL, f.ETag, f.HTTPLastModified)
+	if err != nil {
+		logrus.Errorf("failed to request feed (%s): %s", f.URL, err.Error())
+		f.LastError = err.Error()
+		s.save(f)
+		return
+	}
+
+	f.LastError = ""
+	if resp.notModified {
+		s.save(f)
+		return
+	}
+
+	seen := seenFeedGUIDs(f)
+	candidates := newFeedItems(resp.feed.Items, seen)
+	done := map[string]bool{}
+	if len(seen) == 0 {
+		// 처음 poll 에서 건너뛴 과거 항목은 나중에도 가져오지 않는다
+		for _, item := range resp.feed.Items[len(candidates):] {
+			done[item.GUID] = true
+		}
+	}
+	for _, item := range candidates {
+		if item.Link != "" {
+			if _, err := s.ingestionService.Enqueue(item.Link, f.Tags, models.IngestionSourceFeed); err != nil {
+				logrus.Errorf("failed to enqueue url (%s): %s", item.Link, err.Error())
+				continue
+			}
+		}
+		done[item.GUID] = true
+	}
+
+	// 넣지 못한 항목은 다음 poll 에서 다시 가져온다
+	if len(resp.feed.Items) > 0 {
+		var guids []string
+		for _, item := range resp.feed.Items {
+			if seen[item.GUID] || done[item.GUID] {
+				guids = append(guids, item.GUID)
+			}
+		}
+		f.SeenGUIDs = strings.Join(guids, "\n")
+		f.LastGUID = resp.feed.Items[0].GUID
+	}
+	f.ETag = resp.etag
+	f.HTTPLastModified = resp.lastModified
+	s.save(f)
+}
+
+func (s *feedSyncService) save(f *models.Feed) {
+	if err := s.feedRepository.Save(f); err != nil {
+		logrus.Errorf("failed to save feed (%s): %s", f.URL, err.Error())
+	}
+}
+
+func seenFeedGUIDs(f *models.Feed) map[string]bool {
+	seen := map[string]bool{}
+	if f.SeenGUIDs != "" {
+		for _, guid := range strings.Split(f.SeenGUIDs, "\n") {
+			seen[guid] = true
+		}
+	}
+	return seen
+}
+
+// newFeedItems 는 items 중 seen 에 없는 항목들로, 처음 poll 하는 feed 는 최근 항목 몇 개만 돌려준다.
+func newFeedItems(items []*feed.Item, seen map[string]bool) []*feed.Item {
+	limit := feedMaxItemsPerPoll
+	if len(seen) == 0 {
+		limit = feedInitialItems
+	}
+
+	var result []*feed.Item
+	for _, item := range items {
+		if len(result) >= limit {
+			break
+		} else if !seen[item.GUID] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/feed"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewFeedItems(t *testing.T) {
+	var items []*feed.Item
+	for i := 0; i < 40; i++ {
+		items = append(items, &feed.Item{GUID: fmt.Sprintf("guid-%d", i)})
+	}
+
+	// 처음 poll 하는 경우 최근 항목 몇 개만
+	require.Len(t, newFeedItems(items, seenFeedGUIDs(&models.Feed{})), feedInitialItems)
+
+	// 지난 poll 에서 본 항목은 순서와 상관없이 건너뛴다
+	seen := seenFeedGUIDs(&models.Feed{SeenGUIDs: "guid-0\nguid-2\nguid-39"})
+	result := newFeedItems(items, seen)
+	require.Len(t, result, feedMaxItemsPerPoll)
+	require.Equal(t, "guid-1", result[0].GUID)
+	require.Equal(t, "guid-3", result[1].GUID)
+}
+
+func TestPollFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var items strings.Builder
+		for i := 0; i < feedInitialItems+2; i++ {
+			fmt.Fprintf(&items, "<item><guid>guid-%d</guid><link>https://example.com/%d</link></item>", i, i)
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Blog</title>%s</channel></rss>`, items.String())
+	}))
+	defer server.Close()
+
+	var enqueued []string
+	fail := "https://example.com/1"
+	svc := &feedSyncService{
+		feedRepository: &mock.FeedRepositoryMock{
+			OnSave: func(feed *models.Feed) error { return nil },
+		},
+		ingestionService: &ingestionService{
+			ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+				OnGetActiveByURL: func(url string) (*models.IngestionJob, error) { return nil, gorm.ErrRecordNotFound },
+				OnSave: func(job *models.IngestionJob) error {
+					if job.URL == fail {
+						return errors.New("database is locked")
+					}
+					enqueued = append(enqueued, job.URL)
+					return nil
+				},
+			},
+		},
+	}
+
+	// case 1: 처음 poll 은 최근 항목만, 가져오지 못한 항목은 본 것으로 남기지 않는다
+	f := &models.Feed{URL: server.URL}
+	svc.poll(f)
+	require.Len(t, enqueued, feedInitialItems-1)
+	require.Equal(t, "guid-0", f.LastGUID)
+	require.NotContains(t, strings.Split(f.SeenGUIDs, "\n"), "guid-1")
+	require.Contains(t, strings.Split(f.SeenGUIDs, "\n"), "guid-11")
+
+	// case 2: 다음 poll 에서 가져오지 못한 항목만 다시 가져온다
+	enqueued, fail = nil, ""
+	svc.poll(f)
+	require.Equal(t, []string{"https://example.com/1"}, enqueued)
+	require.Len(t, strings.Split(f.SeenGUIDs, "\n"), feedInitialItems+2)
+}
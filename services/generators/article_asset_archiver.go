@@ -0,0 +1,138 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	netUrl "net/url"
+	"regexp"
+	"strings"
+)
+
+const maxAssetSize = 20 * 1024 * 1024
+
+// ![alt](src "title") 형태의 markdown 이미지
+var markdownImageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(\s+"[^"]*")?\s*\)`)
+
+type articleAssetArchiver struct {
+	assetStore      internal.AssetStore
+	assetRepository repositories.AssetRepository
+	client          fetch.Client
+}
+
+func newArticleAssetArchiver() *articleAssetArchiver {
+	return &articleAssetArchiver{
+		assetStore:      internal.GetAssetStore(),
+		assetRepository: repositories.GetAssetRepository(),
+		client:          newAssetFetchClient(),
+	}
+}
+
+func newAssetFetchClient() fetch.Client {
+	config := fetch.ConfigFromEnv()
+	config.MaxBodySize = maxAssetSize
+	return fetch.NewClient(config)
+}
+
+// Archive 는 content 의 이미지를 asset store 에 보관하고 링크를 `/apis/assets/:hash` 로 바꾼 뒤, 새로 보관한 asset 만 돌려준다.
+func (a *articleAssetArchiver) Archive(articleURL, content string, known models.Assets) (string, models.Assets) {
+	assets := models.Assets{}
+	archived := map[string]string{}
+	for _, asset := range known {
+		if asset.Kind == models.AssetKindImage {
+			archived[asset.OriginURL] = asset.Hash
+		}
+	}
+
+	content = markdownImageRegex.ReplaceAllStringFunc(content, func(match string) string {
+		groups := markdownImageRegex.FindStringSubmatch(match)
+		alt, src, title := groups[1], groups[2], groups[3]
+
+		imageURL, err := resolveURL(articleURL, src)
+		if err != nil {
+			return match
+		}
+
+		hash, ok := archived[imageURL]
+		if !ok {
+			asset, err := a.download(imageURL)
+			if err != nil {
+				logrus.Warnf("failed to archive image %s: %s", imageURL, err.Error())
+				return match
+			}
+			hash = asset.Hash
+			archived[imageURL] = hash
+			if !assets.ContainHash(hash) {
+				assets = append(assets, asset)
+			}
+		}
+
+		return fmt.Sprintf("![%s](%s%s)", alt, models.AssetPath(hash), title)
+	})
+
+	return content, assets
+}
+
+// discard 는 article 을 만들지 못해 버려진 assets 중 다른 article 이 참조하지 않는 파일을 지운다.
+func (a *articleAssetArchiver) discard(assets models.Assets) {
+	for _, hash := range assets.ExtractHashes() {
+		if cnt, err := a.assetRepository.CountByHash(hash); err != nil {
+			logrus.Errorf("failed to count assets by hash (%s): %s", hash, err.Error())
+		} else if cnt == 0 {
+			if err := a.assetStore.Delete(hash); err != nil {
+				logrus.Errorf("failed to delete asset file (%s): %s", hash, err.Error())
+			}
+		}
+	}
+}
+
+func (a *articleAssetArchiver) download(imageURL string) (*models.Asset, error) {
+	resp, err := a.client.Get(imageURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request image")
+	} else if err := fetch.CheckStatus(resp); err != nil {
+		return nil, err
+	}
+	data := resp.Body
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("not an image: %s", contentType)
+	}
+
+	hash, err := a.assetStore.Put(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store image")
+	}
+
+	return &models.Asset{
+		Kind:        models.AssetKindImage,
+		Hash:        hash,
+		ContentType: contentType,
+		OriginURL:   imageURL,
+		Size:        int64(len(data)),
+	}, nil
+}
+
+func resolveURL(baseURL, ref string) (string, error) {
+	base, err := netUrl.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	return u.String(), nil
+}
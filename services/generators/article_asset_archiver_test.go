@@ -0,0 +1,58 @@
+package generators
+
+import (
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestArchive(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(png)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "assets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archiver := &articleAssetArchiver{assetStore: internal.NewAssetStore(dir), client: newAssetFetchClient()}
+	content := "a ![logo](/img/logo.png) b ![again](" + server.URL + "/img/logo.png \"title\") c ![gone](/missing.png)"
+
+	archived, assets := archiver.Archive(server.URL+"/posts/1", content, nil)
+
+	require.Len(t, assets, 1)
+	require.Equal(t, "image/png", assets[0].ContentType)
+	require.Equal(t, server.URL+"/img/logo.png", assets[0].OriginURL)
+
+	path := "/apis/assets/" + assets[0].Hash
+	expected := "a ![logo](" + path + ") b ![again](" + path + " \"title\") c ![gone](/missing.png)"
+	require.Equal(t, expected, archived)
+	require.True(t, internal.NewAssetStore(dir).Exist(assets[0].Hash))
+}
+
+func TestNewStubArchivesImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n0000"))
+	}))
+	defer server.Close()
+
+	gen := newFileTestGenerator()
+	gen.assetArchiver.client = newAssetFetchClient()
+
+	// 다른 서비스가 추출해둔 본문의 이미지도 보관한다
+	article, err := gen.NewStub(server.URL+"/posts/1", "title", "intro ![cover]("+server.URL+"/cover.png)", nil)
+	require.NoError(t, err)
+	require.Equal(t, "intro ![cover](/apis/assets/abc)", article.Content)
+	require.Len(t, article.Assets, 1)
+	require.Equal(t, server.URL+"/cover.png", article.Assets[0].OriginURL)
+}
@@ -0,0 +1,139 @@
+package generators
+
+import (
+	"bytes"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 본문이 아닐 가능성이 높은 요소의 class/id
+var unlikelyCandidateRegexp = regexp.MustCompile(`(?i)comment|sidebar|footer|header|menu|nav|share|social|related|sponsor|advert|popup|cookie|banner|breadcrumb|pagination`)
+
+// 본문일 가능성이 높은 요소의 class/id
+var positiveCandidateRegexp = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+
+// 텍스트를 담는 블럭. 이 요소들의 텍스트 양을 조상 요소들에 나눠 더해 본문 컨테이너를 고른다.
+const densityTextBlockSelector = "p, pre, blockquote, li, td, h2, h3, h4"
+
+const densityMinTextLength = 25
+
+// articleDensityExtractor 는 readability 가 실패한 페이지에서 DOM 요소별 텍스트 밀도로 본문 컨테이너를 골라 markdown 으로 변환한다.
+type articleDensityExtractor struct {
+}
+
+func (e *articleDensityExtractor) Extract(raw *RawResponse) (string, string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw.Body))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to parse response body")
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("h1").First().Text())
+	}
+	if title == "" {
+		title = raw.URL
+	}
+
+	doc.Find("script, style, noscript, iframe, svg, form, nav, aside, footer, button, input, select, textarea").Remove()
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "body" || goquery.NodeName(s) == "html" {
+			return
+		}
+		identity := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyCandidateRegexp.MatchString(identity) && !positiveCandidateRegexp.MatchString(identity) {
+			s.Remove()
+		}
+	})
+
+	content := e.findContentNode(doc)
+	if content == nil {
+		return "", "", errors.New("no content found")
+	}
+
+	contentHtml, err := goquery.OuterHtml(goquery.NewDocumentFromNode(content).Selection)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get html from content node")
+	}
+
+	markdownContent, err := markdown.ConvertFromHtml(contentHtml)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to convert html to markdown")
+	}
+	markdownContent = strings.TrimSpace(markdownContent)
+	if markdownContent == "" {
+		return "", "", errors.New("empty content")
+	}
+
+	return title, markdownContent, nil
+}
+
+func (e *articleDensityExtractor) findContentNode(doc *goquery.Document) *html.Node {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	addScore := func(node *html.Node, score float64) {
+		if _, ok := scores[node]; !ok {
+			candidates = append(candidates, node)
+		}
+		scores[node] += score
+	}
+
+	doc.Find(densityTextBlockSelector).Each(func(_ int, s *goquery.Selection) {
+		textLength := utf8.RuneCountInString(strings.TrimSpace(s.Text()))
+		if textLength < densityMinTextLength {
+			return
+		}
+
+		// 문장 부호가 많을수록 본문에 가깝다
+		score := 1 + float64(strings.Count(s.Text(), ",")) + float64(minInt(textLength/100, 3))
+		score *= 1 - e.linkDensity(s)
+
+		parent := s.Parent()
+		if parent.Length() > 0 {
+			addScore(parent.Get(0), score)
+		}
+		if grandParent := parent.Parent(); grandParent.Length() > 0 {
+			addScore(grandParent.Get(0), score/2)
+		}
+	})
+
+	var best *html.Node
+	var bestScore float64
+	for _, node := range candidates {
+		score := scores[node]
+		s := goquery.NewDocumentFromNode(node).Selection
+		identity := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if positiveCandidateRegexp.MatchString(identity) {
+			score *= 1.25
+		}
+		if best == nil || score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+
+	if best == nil {
+		if body := doc.Find("body"); body.Length() > 0 {
+			return body.Get(0)
+		}
+	}
+	return best
+}
+
+// linkDensity 는 요소의 텍스트 중 링크 텍스트의 비율이다. 메뉴나 목록처럼 링크로만 이루어진 영역을 걸러낸다.
+func (e *articleDensityExtractor) linkDensity(s *goquery.Selection) float64 {
+	textLength := utf8.RuneCountInString(s.Text())
+	if textLength == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linkLength += utf8.RuneCountInString(a.Text())
+	})
+	return float64(linkLength) / float64(textLength)
+}
@@ -0,0 +1,42 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestDensityExtract(t *testing.T) {
+	body := `<html>
+<head><title>Density test</title></head>
+<body>
+	<div class="menu"><a href="/a">Home</a> <a href="/b">About us and the rest of the team</a></div>
+	<div id="wrapper">
+		<div class="links">
+			<p><a href="/1">A very long link to another post on this blog</a></p>
+			<p><a href="/2">Another very long link to yet another post here</a></p>
+		</div>
+		<div class="post">
+			<h2>First section</h2>
+			<p>This is the first paragraph of the article, with enough text, commas, and words to look like prose.</p>
+			<p>This is the second paragraph, which continues the story, adds detail, and keeps going for a while.</p>
+			<pre><code>fmt.Println("hello")</code></pre>
+		</div>
+	</div>
+	<div class="comments"><p>Nice post, thanks for sharing this with everyone, really.</p></div>
+</body>
+</html>`
+
+	title, content, err := (&articleDensityExtractor{}).Extract(&RawResponse{
+		URL:  "https://example.com/post",
+		Body: []byte(body),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Density test", title)
+	require.Contains(t, content, "first paragraph of the article")
+	require.Contains(t, content, "second paragraph")
+	require.Contains(t, content, `fmt.Println("hello")`)
+	require.False(t, strings.Contains(content, "Home"))
+	require.False(t, strings.Contains(content, "Nice post"))
+	require.False(t, strings.Contains(content, "A very long link"))
+}
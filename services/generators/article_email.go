@@ -0,0 +1,108 @@
+package generators
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common/email"
+	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// NewEmail 은 받은 메일로 article 을 만든다. html 본문은 markdown 으로 바꾸고, 없으면 text 본문을 그대로 쓴다.
+func (g *articleGenerator) NewEmail(message *email.Message, tags []string) (*models.Article, error) {
+	url := message.URL()
+	content := strings.TrimSpace(message.Text)
+	assets := models.Assets{}
+	if message.HTML != "" {
+		html, archived, err := g.archiveInlineImages(message)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = markdown.ConvertFromHtml(html); err != nil {
+			g.assetArchiver.discard(archived)
+			return nil, errors.Wrap(err, "failed to convert html to markdown")
+		}
+		var remote models.Assets
+		content, remote = g.assetArchiver.Archive(url, content, archived)
+		assets = append(archived, remote...)
+	}
+
+	title := message.Subject
+	if title == "" {
+		title = "(no subject)"
+	}
+	title, err := g.getUniqueTitle(title)
+	if err != nil {
+		g.assetArchiver.discard(assets)
+		return nil, errors.Wrap(err, "failed to get unique title")
+	}
+
+	article := models.NewArticle(models.KindEmail, url, content, title, tags)
+	article.CanonicalURL = &url
+	article.Assets = assets
+	article.Extractor = models.KindEmail
+	article.Published = message.Date
+	if message.From != nil {
+		article.Author = message.From.Name
+		if article.Author == "" {
+			article.Author = message.From.Address
+		}
+		if i := strings.LastIndex(message.From.Address, "@"); i >= 0 {
+			article.SiteName = strings.ToLower(message.From.Address[i+1:])
+		}
+	}
+	return article, nil
+}
+
+// archiveInlineImages 는 `cid:` 로 참조하는 inline 이미지를 보관하고 링크를 바꾼 html body 를 돌려준다.
+func (g *articleGenerator) archiveInlineImages(message *email.Message) (string, models.Assets, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(message.HTML))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to parse html")
+	}
+
+	parts := map[string]*email.Part{}
+	for _, part := range message.Inline {
+		if strings.HasPrefix(part.ContentType, "image/") {
+			parts[part.ContentID] = part
+		}
+	}
+
+	assets := models.Assets{}
+	var putErr error
+	doc.Find("img[src^='cid:']").Each(func(_ int, s *goquery.Selection) {
+		part, ok := parts[strings.TrimPrefix(s.AttrOr("src", ""), "cid:")]
+		if !ok || putErr != nil {
+			return
+		}
+
+		hash, err := g.assetArchiver.assetStore.Put(part.Data)
+		if err != nil {
+			putErr = errors.Wrap(err, "failed to store inline image")
+			return
+		}
+		s.SetAttr("src", models.AssetPath(hash))
+		if !assets.ContainHash(hash) {
+			assets = append(assets, &models.Asset{
+				Kind:        models.AssetKindImage,
+				Hash:        hash,
+				ContentType: part.ContentType,
+				OriginURL:   "cid:" + part.ContentID,
+				Size:        int64(len(part.Data)),
+			})
+		}
+	})
+	if putErr != nil {
+		g.assetArchiver.discard(assets)
+		return "", nil, putErr
+	}
+
+	// <head> 의 title, style 이 본문에 섞이지 않도록 body 만 옮긴다
+	html, err := doc.Find("body").Html()
+	if err != nil {
+		g.assetArchiver.discard(assets)
+		return "", nil, errors.Wrap(err, "failed to render html")
+	}
+	return html, assets, nil
+}
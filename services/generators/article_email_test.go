@@ -0,0 +1,91 @@
+package generators
+
+import (
+	"github.com/jaeyo/personal-archive/common/email"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+)
+
+func TestNewEmail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n0000"))
+	}))
+	defer server.Close()
+
+	gen := &articleGenerator{
+		assetArchiver: &articleAssetArchiver{
+			assetStore: &internal.AssetStoreMock{
+				OnPut: func(data []byte) (string, error) {
+					if string(data) == "png" {
+						return "abc", nil
+					}
+					return "def", nil
+				},
+			},
+			client: newAssetFetchClient(),
+		},
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnExistByTitle: func(title string) (bool, error) { return false, nil },
+		},
+	}
+
+	article, err := gen.NewEmail(&email.Message{
+		MessageID: "1@example.com",
+		From:      &mail.Address{Name: "Weekly", Address: "news@Example.com"},
+		Subject:   "Issue #1",
+		HTML:      `<html><head><title>ignored</title></head><body><p>Hello <b>you</b></p><img src="cid:logo" alt="logo"><img src="cid:unknown"><img src="` + server.URL + `/remote.png"></body></html>`,
+		Inline:    []*email.Part{{ContentID: "logo", ContentType: "image/png", Data: []byte("png")}},
+	}, []string{"news@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, models.KindEmail, article.Kind)
+	require.Equal(t, "mid:1@example.com", article.URL)
+	require.Equal(t, "mid:1@example.com", *article.CanonicalURL)
+	require.Equal(t, "Issue #1", article.Title)
+	require.Equal(t, "Hello **you**\n\n![logo](/apis/assets/abc)![](cid:unknown)![](/apis/assets/def)", article.Content)
+	require.Equal(t, "Weekly", article.Author)
+	require.Equal(t, "example.com", article.SiteName)
+	require.Len(t, article.Assets, 2)
+	require.Equal(t, "cid:logo", article.Assets[0].OriginURL)
+	require.Equal(t, server.URL+"/remote.png", article.Assets[1].OriginURL)
+}
+
+func TestNewEmailDiscardsInlineImages(t *testing.T) {
+	var deleted []string
+	gen := &articleGenerator{
+		assetArchiver: &articleAssetArchiver{
+			assetStore: &internal.AssetStoreMock{
+				OnPut: func(data []byte) (string, error) {
+					if string(data) == "b" {
+						return "", errors.New("disk full")
+					}
+					return string(data), nil
+				},
+				OnDelete: func(hash string) error {
+					deleted = append(deleted, hash)
+					return nil
+				},
+			},
+			assetRepository: &mock.AssetRepositoryMock{
+				OnCountByHash: func(hash string) (int64, error) { return 0, nil },
+			},
+		},
+	}
+
+	_, err := gen.NewEmail(&email.Message{
+		MessageID: "2@example.com",
+		HTML:      `<img src="cid:a"><img src="cid:b">`,
+		Inline: []*email.Part{
+			{ContentID: "a", ContentType: "image/png", Data: []byte("a")},
+			{ContentID: "b", ContentType: "image/png", Data: []byte("b")},
+		},
+	}, nil)
+	require.Error(t, err)
+	require.Equal(t, []string{"a"}, deleted)
+}
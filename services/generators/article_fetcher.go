@@ -1,6 +1,110 @@
 package generators
 
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+// 다시 시도해도 가져올 수 없는 url 의 에러들
+var (
+	ErrPageNotFound       = errors.New("page not found")
+	ErrUnsupportedContent = errors.New("unsupported content")
+	ErrInvalidURL         = errors.New("invalid url")
+)
+
 type ArticleFetcher interface {
-	Fetch(url string) (string, string, error)
+	Kind() string
+	Priority() int
 	IsFetchable(url string) bool
+	Fetch(url string) (*FetchedArticle, error)
+}
+
+// RawArticleExtractor 는 저장해둔 원본 응답으로부터 네트워크 없이 다시 추출할 수 있는 fetcher 이다.
+type RawArticleExtractor interface {
+	Extract(raw *RawResponse) (*FetchedArticle, error)
+}
+
+// RawArticleFetcher 는 원본 응답을 내려받은 뒤 추출하는 fetcher 로, 응답의 내용에 따라 다른 fetcher 가 대신 추출할 수 있다.
+type RawArticleFetcher interface {
+	RawArticleExtractor
+	FetchRaw(url string) (*RawResponse, error)
+}
+
+// ContentArticleExtractor 는 url 이 아닌 내려받은 응답의 내용(e.g. Content-Type)으로 추출 여부를 정할 수 있는 fetcher 이다.
+type ContentArticleExtractor interface {
+	RawArticleExtractor
+	CanExtract(raw *RawResponse) bool
+}
+
+// FetchedArticle.Extractor 의 값들
+const (
+	ExtractorReadability = "readability"
+	ExtractorTextDensity = "text-density"
+	ExtractorRule        = "rule"
+	// 다른 서비스가 추출해둔 본문을 가져왔다
+	ExtractorImport = "import"
+	// 올린 markdown, text 파일을 그대로 썼다
+	ExtractorFile = "file"
+	// 올린 epub 파일의 chapter 를 markdown 으로 바꿨다
+	ExtractorEPUB = "epub"
+)
+
+type FetchedArticle struct {
+	Title   string
+	Content string
+	ArticleMetadata
+	// 원본 응답, 있으면 snapshot 으로 보관된다
+	Raw *RawResponse
+	// fetcher 가 직접 asset store 에 보관한 파일 (e.g. 원본 pdf)
+	Assets models.Assets
+	// content 를 만든 추출기, 비어있으면 fetcher 의 kind 로 대신한다
+	Extractor string
+	// 외부 player 로 보여줄 article 의 embed 정보
+	Embed *models.ArticleEmbed
+	// 중복 판단에 쓰일 문서의 대표 url, 비어있으면 원본 응답의 url 로 대신한다
+	CanonicalURL string
+}
+
+type RawResponse struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (r *RawResponse) ContentType() string {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(r.Body)
+}
+
+// isTextContent 는 html, xml, text 처럼 본문을 추출할 수 있는 응답인지 여부다.
+func isTextContent(raw *RawResponse) bool {
+	contentType := strings.ToLower(raw.ContentType())
+	return strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "html") || strings.Contains(contentType, "xml")
+}
+
+func getRaw(url string) (*RawResponse, error) {
+	resp, err := fetch.Get(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request url")
+	}
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, errors.Wrapf(ErrPageNotFound, "response code is %d", resp.StatusCode)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response code is not success: %d", resp.StatusCode)
+	}
+
+	return &RawResponse{
+		URL:        resp.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       resp.Body,
+	}, nil
 }
@@ -1,14 +1,24 @@
 package generators
 
 type ArticleFetcherMock struct {
-	OnFetch       func(url string) (string, string, error)
+	OnKind        func() string
+	OnPriority    func() int
 	OnIsFetchable func(url string) bool
+	OnFetch       func(url string) (*FetchedArticle, error)
 }
 
-func (m *ArticleFetcherMock) Fetch(url string) (string, string, error) {
-	return m.OnFetch(url)
+func (m *ArticleFetcherMock) Kind() string {
+	return m.OnKind()
+}
+
+func (m *ArticleFetcherMock) Priority() int {
+	return m.OnPriority()
 }
 
 func (m *ArticleFetcherMock) IsFetchable(url string) bool {
 	return m.OnIsFetchable(url)
 }
+
+func (m *ArticleFetcherMock) Fetch(url string) (*FetchedArticle, error) {
+	return m.OnFetch(url)
+}
@@ -0,0 +1,48 @@
+package generators
+
+import (
+	"sort"
+	"sync"
+)
+
+// fetcher 우선순위, 값이 클수록 먼저 시도된다.
+const (
+	PriorityFallback = 0
+	PriorityDefault  = 100
+	PrioritySite     = 200
+)
+
+type ArticleFetcherFactory func() ArticleFetcher
+
+var fetcherRegistry = struct {
+	sync.Mutex
+	factories []ArticleFetcherFactory
+}{}
+
+// RegisterFetcher 는 각 fetcher 파일의 init() 에서 호출되어 generator 가 사용할 fetcher 를 등록한다.
+// fetcher 생성 시점에 repository 등을 참조할 수 있도록 인스턴스 대신 factory 를 받는다.
+func RegisterFetcher(factory ArticleFetcherFactory) {
+	fetcherRegistry.Lock()
+	defer fetcherRegistry.Unlock()
+	fetcherRegistry.factories = append(fetcherRegistry.factories, factory)
+}
+
+func newRegisteredFetchers() []ArticleFetcher {
+	fetcherRegistry.Lock()
+	defer fetcherRegistry.Unlock()
+
+	var fetchers []ArticleFetcher
+	for _, factory := range fetcherRegistry.factories {
+		fetchers = append(fetchers, factory())
+	}
+	return sortFetchers(fetchers)
+}
+
+func sortFetchers(fetchers []ArticleFetcher) []ArticleFetcher {
+	sorted := make([]ArticleFetcher, len(fetchers))
+	copy(sorted, fetchers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority() > sorted[j].Priority()
+	})
+	return sorted
+}
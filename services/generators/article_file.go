@@ -0,0 +1,311 @@
+package generators
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common/epub"
+	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	netUrl "net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewFile 이 읽을 수 있는 파일 형식
+const (
+	FileTypeHTML     = "html"
+	FileTypeMarkdown = "markdown"
+	FileTypeText     = "text"
+	FileTypeEPUB     = "epub"
+)
+
+// DetectFileType 은 확장자, 확장자로 알 수 없으면 내용으로 파일 형식을 정한다.
+func DetectFileType(name string, data []byte) (string, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm", ".xhtml":
+		return FileTypeHTML, nil
+	case ".md", ".markdown":
+		return FileTypeMarkdown, nil
+	case ".txt", ".text":
+		return FileTypeText, nil
+	case ".epub":
+		return FileTypeEPUB, nil
+	}
+
+	if epub.IsEPUB(data) {
+		return FileTypeEPUB, nil
+	}
+	switch contentType := http.DetectContentType(data); {
+	case strings.HasPrefix(contentType, "text/html"):
+		return FileTypeHTML, nil
+	case strings.HasPrefix(contentType, "text/plain") && utf8.Valid(data):
+		return FileTypeText, nil
+	default:
+		return "", fmt.Errorf("unsupported file type: %s", contentType)
+	}
+}
+
+// NewFile 은 올린 파일로 article 을 만들며, 같은 파일을 알아볼 수 있도록 canonical url 은 내용의 hash 로 만든다.
+func (g *articleGenerator) NewFile(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error) {
+	fileType, err := DetectFileType(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var article *models.Article
+	switch fileType {
+	case FileTypeEPUB:
+		return g.newEPUBFile(name, data, tags, splitChapters)
+	case FileTypeHTML:
+		article, err = g.newHTMLFile(name, data, tags)
+	default:
+		article, err = g.newTextFile(name, data, tags, fileType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models.Articles{article}, nil
+}
+
+func (g *articleGenerator) newHTMLFile(name string, data []byte, tags []string) (*models.Article, error) {
+	extractor := g.rawExtractorOf(models.KindMarkdown)
+	if extractor == nil {
+		return nil, errors.New("no extractor available for html")
+	}
+
+	url := fileURL(name)
+	raw := &RawResponse{
+		URL:        url,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {http.DetectContentType(data)}},
+		Body:       data,
+	}
+	fetched, err := extractor.Extract(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract")
+	}
+
+	title := fetched.Title
+	if title == "" {
+		title = fileTitleOf(name)
+	}
+	title, err = g.getUniqueTitle(title)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get unique title")
+	}
+
+	// 상대 경로의 이미지는 파일과 함께 올라오지 않으므로 절대 url 의 이미지만 보관된다
+	content, assets := g.assetArchiver.Archive(url, fetched.Content, nil)
+	// 원본을 snapshot 으로 남겨 다시 추출할 수 있게 한다
+	if snapshot, err := g.snapshotter.Snapshot(raw); err != nil {
+		logrus.Warnf("failed to snapshot %s: %s", name, err.Error())
+	} else {
+		assets = append(assets, snapshot)
+	}
+
+	canonicalURL := fileCanonicalURL(data, 0)
+	article := models.NewArticle(models.KindMarkdown, url, content, title, tags)
+	article.CanonicalURL = &canonicalURL
+	article.Assets = assets
+	article.Extractor = extractorOf(fetched, models.KindMarkdown)
+	applyMetadata(article, fetched.ArticleMetadata)
+	return article, nil
+}
+
+func (g *articleGenerator) newTextFile(name string, data []byte, tags []string, fileType string) (*models.Article, error) {
+	content := strings.ReplaceAll(string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), "\r\n", "\n")
+
+	title := ""
+	if fileType == FileTypeMarkdown {
+		title = markdownTitleOf(content)
+	}
+	if title == "" {
+		title = fileTitleOf(name)
+	}
+	title, err := g.getUniqueTitle(title)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get unique title")
+	}
+
+	canonicalURL := fileCanonicalURL(data, 0)
+	article := models.NewArticle(models.KindMarkdown, fileURL(name), content, title, tags)
+	article.CanonicalURL = &canonicalURL
+	article.Extractor = ExtractorFile
+	return article, nil
+}
+
+func (g *articleGenerator) newEPUBFile(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error) {
+	book, err := epub.Parse(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse epub")
+	}
+
+	bookTitle := book.Title
+	if bookTitle == "" {
+		bookTitle = fileTitleOf(name)
+	}
+	metadata := ArticleMetadata{Author: book.Author, Language: book.Language}
+
+	var articles models.Articles
+	var contents []string
+	assets := models.Assets{}
+	// 아직 저장하지 않은 chapter 끼리 제목이 겹치지 않도록 한다
+	titles := map[string]bool{}
+	for i, chapter := range book.Chapters {
+		content, chapterAssets, err := g.convertEPUBChapter(book, chapter, name)
+		if err != nil {
+			return nil, err
+		}
+		if content == "" {
+			continue
+		}
+
+		if !splitChapters {
+			contents = append(contents, content)
+			for _, asset := range chapterAssets {
+				if !assets.ContainHash(asset.Hash) {
+					assets = append(assets, asset)
+				}
+			}
+			continue
+		}
+
+		chapterTitle := chapter.Title
+		if chapterTitle == "" {
+			chapterTitle = fmt.Sprintf("Chapter %d", i+1)
+		}
+		title, err := g.getUniqueTitle(bookTitle + " - " + chapterTitle)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get unique title")
+		}
+		for titles[title] {
+			title += uniqueTitleSuffix
+		}
+		titles[title] = true
+
+		canonicalURL := fileCanonicalURL(data, i+1)
+		article := models.NewArticle(models.KindMarkdown, fmt.Sprintf("%s#chapter-%d", fileURL(name), i+1), content, title, tags)
+		article.CanonicalURL = &canonicalURL
+		article.Assets = chapterAssets
+		article.Extractor = ExtractorEPUB
+		applyMetadata(article, metadata)
+		articles = append(articles, article)
+	}
+
+	if splitChapters {
+		if len(articles) == 0 {
+			return nil, errors.New("no content in epub")
+		}
+		return articles, nil
+	}
+	if len(contents) == 0 {
+		return nil, errors.New("no content in epub")
+	}
+
+	title, err := g.getUniqueTitle(bookTitle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get unique title")
+	}
+	canonicalURL := fileCanonicalURL(data, 0)
+	article := models.NewArticle(models.KindMarkdown, fileURL(name), strings.Join(contents, "\n\n"), title, tags)
+	article.CanonicalURL = &canonicalURL
+	article.Assets = assets
+	article.Extractor = ExtractorEPUB
+	applyMetadata(article, metadata)
+	return models.Articles{article}, nil
+}
+
+// convertEPUBChapter 는 chapter 를 markdown 으로 바꾼다. epub 안에 든 이미지는 asset store 에 넣고 링크를 바꾼다.
+func (g *articleGenerator) convertEPUBChapter(book *epub.Book, chapter *epub.Chapter, name string) (string, models.Assets, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(chapter.HTML))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to parse %s", chapter.Path)
+	}
+
+	assets := models.Assets{}
+	var putErr error
+	doc.Find("img[src]").Each(func(_ int, s *goquery.Selection) {
+		src := epub.Resolve(path.Dir(chapter.Path), s.AttrOr("src", ""))
+		if src == "" || putErr != nil {
+			return
+		}
+		data, err := book.Read(src)
+		if err != nil {
+			logrus.Warnf("failed to read image %s in %s: %s", src, name, err.Error())
+			return
+		}
+
+		hash, err := g.assetArchiver.assetStore.Put(data)
+		if err != nil {
+			putErr = errors.Wrap(err, "failed to store image")
+			return
+		}
+		s.SetAttr("src", models.AssetPath(hash))
+		if !assets.ContainHash(hash) {
+			assets = append(assets, &models.Asset{
+				Kind:        models.AssetKindImage,
+				Hash:        hash,
+				ContentType: epub.ContentTypeOf(src),
+				OriginURL:   fileURL(name) + "/" + src,
+				Size:        int64(len(data)),
+			})
+		}
+	})
+	if putErr != nil {
+		return "", nil, putErr
+	}
+
+	// <head> 의 title, style 이 본문에 섞이지 않도록 body 만 옮긴다
+	html, err := doc.Find("body").Html()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to render html")
+	}
+	content, err := markdown.ConvertFromHtml(html)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to convert html to markdown")
+	}
+	return strings.TrimSpace(content), assets, nil
+}
+
+// fileURL 은 올린 파일을 가리키는 `file://` url 이다.
+func fileURL(name string) string {
+	return "file:///" + netUrl.PathEscape(path.Base(name))
+}
+
+// fileCanonicalURL 은 파일 내용의 hash 로 만든 canonical url 이다. chapter 가 0 이 아니면 epub 의 chapter 하나를 가리킨다.
+func fileCanonicalURL(data []byte, chapter int) string {
+	sum := sha256.Sum256(data)
+	url := "file:///sha256/" + hex.EncodeToString(sum[:])
+	if chapter > 0 {
+		url += fmt.Sprintf("#chapter-%d", chapter)
+	}
+	return url
+}
+
+// fileTitleOf 는 확장자를 뺀 파일 이름이다.
+func fileTitleOf(name string) string {
+	base := path.Base(name)
+	if title := strings.TrimSpace(strings.TrimSuffix(base, path.Ext(base))); title != "" {
+		return title
+	}
+	return base
+}
+
+// markdownTitleOf 는 markdown 본문의 첫 `# ` heading 이다.
+func markdownTitleOf(content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		}
+	}
+	return ""
+}
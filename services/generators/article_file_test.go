@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func newFileTestGenerator() *articleGenerator {
+	assetStore := &internal.AssetStoreMock{
+		OnPut: func(data []byte) (string, error) { return "abc", nil },
+	}
+	return &articleGenerator{
+		fetchers:      []ArticleFetcher{&articleMarkdownFetcher{densityExtractor: &articleDensityExtractor{}}},
+		assetArchiver: &articleAssetArchiver{assetStore: assetStore},
+		snapshotter:   &articleSnapshotter{assetStore: assetStore},
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnExistByTitle: func(title string) (bool, error) { return false, nil },
+		},
+	}
+}
+
+func TestDetectFileType(t *testing.T) {
+	for name, expected := range map[string]string{"a.HTM": FileTypeHTML, "a.md": FileTypeMarkdown, "a.txt": FileTypeText, "a.epub": FileTypeEPUB} {
+		fileType, err := DetectFileType(name, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, fileType)
+	}
+
+	// 확장자가 없으면 내용으로 정한다
+	fileType, err := DetectFileType("page", []byte("<!DOCTYPE html><html><body>hi</body></html>"))
+	require.NoError(t, err)
+	require.Equal(t, FileTypeHTML, fileType)
+	fileType, err = DetectFileType("memo", []byte("just text"))
+	require.NoError(t, err)
+	require.Equal(t, FileTypeText, fileType)
+
+	_, err = DetectFileType("image.png", []byte("\x89PNG\r\n\x1a\n"))
+	require.Error(t, err)
+}
+
+func TestNewFile(t *testing.T) {
+	gen := newFileTestGenerator()
+
+	// case 1: markdown 은 그대로, 제목은 첫 heading
+	articles, err := gen.NewFile("notes/go memo.md", []byte("\xef\xbb\xbfintro\r\n# Go 메모\r\nbody"), []string{"go"}, false)
+	require.NoError(t, err)
+	require.Len(t, articles, 1)
+	require.Equal(t, "Go 메모", articles[0].Title)
+	require.Equal(t, "intro\n# Go 메모\nbody", articles[0].Content)
+	require.Equal(t, "file:///go%20memo.md", articles[0].URL)
+	require.True(t, strings.HasPrefix(*articles[0].CanonicalURL, "file:///sha256/"))
+	require.Equal(t, ExtractorFile, articles[0].Extractor)
+	require.Equal(t, models.KindMarkdown, articles[0].Kind)
+
+	// case 2: text 는 파일 이름이 제목
+	articles, err = gen.NewFile("memo.txt", []byte("# not a title"), nil, false)
+	require.NoError(t, err)
+	require.Equal(t, "memo", articles[0].Title)
+
+	// case 3: html 은 본문을 추출하고 snapshot 을 남긴다
+	paragraph := strings.Repeat("Goroutines are lightweight threads managed by the Go runtime. ", 10)
+	articles, err = gen.NewFile("saved.html", []byte("<html><head><title>Saved page</title></head><body><article><h1>Saved page</h1><p>"+paragraph+"</p><p>"+paragraph+"</p></article></body></html>"), nil, false)
+	require.NoError(t, err)
+	require.Equal(t, "Saved page", articles[0].Title)
+	require.Contains(t, articles[0].Content, "Goroutines are lightweight threads")
+	require.Equal(t, ExtractorReadability, articles[0].Extractor)
+	require.Len(t, articles[0].Assets, 1)
+	require.Equal(t, models.AssetKindSnapshot, articles[0].Assets[0].Kind)
+}
+
+func TestNewFileEPUB(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range [][2]string{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`},
+		{"content.opf", `<package xmlns:dc="http://purl.org/dc/elements/1.1/"><metadata><dc:title>Book</dc:title><dc:creator>Gopher</dc:creator></metadata>
+<manifest><item id="c1" href="text/c1.xhtml"/><item id="c2" href="text/c2.xhtml"/><item id="c3" href="text/c3.xhtml"/></manifest>
+<spine><itemref idref="c1"/><itemref idref="c2"/><itemref idref="c3"/></spine></package>`},
+		{"text/c1.xhtml", `<html><head><title>One</title></head><body><p>first <img src="../images/a.png" alt="a"/></p></body></html>`},
+		{"text/c2.xhtml", `<html><body><div></div></body></html>`},
+		{"text/c3.xhtml", `<html><head><title>One</title></head><body><p>third</p></body></html>`},
+		{"images/a.png", "png"},
+	} {
+		f, err := w.Create(file[0])
+		require.NoError(t, err)
+		_, _ = f.Write([]byte(file[1]))
+	}
+	require.NoError(t, w.Close())
+
+	gen := newFileTestGenerator()
+
+	// case 1: 책 전체를 article 하나로
+	articles, err := gen.NewFile("book.epub", buf.Bytes(), nil, false)
+	require.NoError(t, err)
+	require.Len(t, articles, 1)
+	require.Equal(t, "Book", articles[0].Title)
+	require.Equal(t, "first ![a](/apis/assets/abc)\n\nthird", articles[0].Content)
+	require.Equal(t, "Gopher", articles[0].Author)
+	require.Equal(t, ExtractorEPUB, articles[0].Extractor)
+	require.Len(t, articles[0].Assets, 1)
+	require.Equal(t, "file:///book.epub/images/a.png", articles[0].Assets[0].OriginURL)
+
+	// case 2: chapter 마다, 빈 chapter 는 건너뛰고 겹치는 제목은 바꾼다
+	articles, err = gen.NewFile("book.epub", buf.Bytes(), nil, true)
+	require.NoError(t, err)
+	require.Len(t, articles, 2)
+	require.Equal(t, "Book - One", articles[0].Title)
+	require.Equal(t, "Book - One(1)", articles[1].Title)
+	require.Equal(t, "file:///book.epub#chapter-3", articles[1].URL)
+	require.True(t, strings.HasSuffix(*articles[1].CanonicalURL, "#chapter-3"))
+	require.Equal(t, "third", articles[1].Content)
+}
@@ -1,21 +1,36 @@
 package generators
 
 import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common"
+	"github.com/jaeyo/personal-archive/common/canonical"
+	"github.com/jaeyo/personal-archive/common/email"
+	"github.com/jaeyo/personal-archive/internal"
 	"github.com/jaeyo/personal-archive/models"
 	"github.com/jaeyo/personal-archive/repositories"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	netUrl "net/url"
+	"strings"
 	"sync"
 )
 
+const uniqueTitleSuffix = "(1)"
+
 type ArticleGenerator interface {
 	NewArticle(url string, tags []string) (*models.Article, error)
+	NewStub(url, title, content string, tags []string) (*models.Article, error)
+	NewEmail(message *email.Message, tags []string) (*models.Article, error)
+	NewFile(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error)
+	Refetch(article *models.Article) error
+	ReExtract(article *models.Article, raw *RawResponse) error
 }
 
 type articleGenerator struct {
-	markdownFetcher   ArticleFetcher
-	tweetFetcher      ArticleFetcher
-	slideShareFetcher ArticleFetcher
-	youtubeFetcher    ArticleFetcher
+	fetchers          []ArticleFetcher
+	assetArchiver     *articleAssetArchiver
+	snapshotter       *articleSnapshotter
 	articleRepository repositories.ArticleRepository
 }
 
@@ -25,10 +40,12 @@ var GetArticleGenerator = func() func() ArticleGenerator {
 	return func() ArticleGenerator {
 		once.Do(func() {
 			instance = &articleGenerator{
-				markdownFetcher:   &articleMarkdownFetcher{},
-				tweetFetcher:      &articleTweetFetcher{},
-				slideShareFetcher: &articleSlideShareFetcher{},
-				youtubeFetcher:    &articleYoutubeFetcher{},
+				fetchers:      newRegisteredFetchers(),
+				assetArchiver: newArticleAssetArchiver(),
+				snapshotter: &articleSnapshotter{
+					format:     common.SnapshotFormat(),
+					assetStore: internal.GetAssetStore(),
+				},
 				articleRepository: repositories.GetArticleRepository(),
 			}
 		})
@@ -37,39 +54,212 @@ var GetArticleGenerator = func() func() ArticleGenerator {
 }()
 
 func (g *articleGenerator) NewArticle(url string, tags []string) (*models.Article, error) {
-	title, content, kind, err := g.fetch(url)
+	if u, err := netUrl.Parse(url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Wrapf(ErrInvalidURL, "url %s", url)
+	}
+
+	fetched, kind, err := g.fetch(url)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get title/content/kind from url")
 	}
 
+	title, err := g.getUniqueTitle(fetched.Title)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get unique title")
+	}
+
+	content, assets := g.assetArchiver.Archive(url, fetched.Content, nil)
+	assets = append(assets, fetched.Assets...)
+
+	if fetched.Raw != nil {
+		// snapshot 보관에 실패하더라도 article 자체는 생성한다
+		if snapshot, err := g.snapshotter.Snapshot(fetched.Raw); err != nil {
+			logrus.Warnf("failed to snapshot %s: %s", url, err.Error())
+		} else {
+			assets = append(assets, snapshot)
+		}
+	}
+
+	article := models.NewArticle(kind, url, content, title, tags)
+	article.Assets = assets
+	article.Extractor = extractorOf(fetched, kind)
+	if fetched.Embed != nil {
+		article.Embed = *fetched.Embed
+	}
+	article.CanonicalURL = canonicalURLOf(fetched, url)
+	applyMetadata(article, fetched.ArticleMetadata)
+	return article, nil
+}
+
+// NewStub 은 url 을 내려받지 않고 주어진 제목과 본문으로 article 을 만든다. 제목이 없으면 url 을 제목으로 쓴다.
+func (g *articleGenerator) NewStub(url, title, content string, tags []string) (*models.Article, error) {
+	canonicalURL, err := canonical.Normalize(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if title == "" {
+		title = url
+	}
 	title, err = g.getUniqueTitle(title)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get unique title")
 	}
 
-	return models.NewArticle(kind, url, content, title, tags), nil
+	var assets models.Assets
+	if content != "" {
+		// 다른 서비스가 추출해둔 본문의 이미지도 내려받아 보관한다
+		content, assets = g.assetArchiver.Archive(url, content, nil)
+	}
+
+	article := models.NewArticle(models.KindMarkdown, url, content, title, tags)
+	article.CanonicalURL = &canonicalURL
+	if content != "" {
+		article.Extractor = ExtractorImport
+		article.Assets = assets
+	}
+	return article, nil
+}
+
+// Refetch 는 article 의 url 을 다시 내려받아 제목, 본문, 메타데이터를 새로 추출한 결과로 바꾼다.
+func (g *articleGenerator) Refetch(article *models.Article) error {
+	fetched, kind, err := g.fetch(article.URL)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch")
+	}
+
+	if TrimUniqueTitleSuffix(fetched.Title) != TrimUniqueTitleSuffix(article.Title) {
+		title, err := g.getUniqueTitle(fetched.Title)
+		if err != nil {
+			return errors.Wrap(err, "failed to get unique title")
+		}
+		article.Title = title
+	}
+
+	content, assets := g.assetArchiver.Archive(article.URL, fetched.Content, article.Assets)
+	assets = append(assets, fetched.Assets...)
+	if fetched.Raw != nil {
+		if snapshot, err := g.snapshotter.Snapshot(fetched.Raw); err != nil {
+			logrus.Warnf("failed to snapshot %s: %s", article.URL, err.Error())
+		} else {
+			assets = append(assets, snapshot)
+		}
+	}
+	for _, asset := range assets {
+		if !article.Assets.ContainHash(asset.Hash) {
+			article.Assets = append(article.Assets, asset)
+		}
+	}
+
+	article.Kind = kind
+	article.Content = content
+	article.Extractor = extractorOf(fetched, kind)
+	// kind 가 바뀌었을 수 있으므로 이전 embed, canonical url 을 남기지 않는다
+	article.Embed = models.ArticleEmbed{}
+	if fetched.Embed != nil {
+		article.Embed = *fetched.Embed
+	}
+	// 다른 article 이 이미 쓰는 canonical url 이면 중복으로 저장할 수 없으므로 이전 값을 둔다
+	if canonicalURL := canonicalURLOf(fetched, article.URL); canonicalURL != nil {
+		other, err := g.articleRepository.GetByCanonicalURL(*canonicalURL)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && other.ID == article.ID) {
+			article.CanonicalURL = canonicalURL
+		} else if err != nil {
+			return errors.Wrap(err, "failed to get article by canonical url")
+		}
+	}
+	article.UpdateStatistics()
+	applyMetadata(article, fetched.ArticleMetadata)
+	return nil
+}
+
+// ReExtract 는 보관된 원본 응답으로부터 article 의 content 를 다시 추출한다. 네트워크 요청은 새로운 이미지를 보관할 때만 발생한다.
+func (g *articleGenerator) ReExtract(article *models.Article, raw *RawResponse) error {
+	extractor := g.rawExtractorOf(article.Kind)
+	if extractor == nil {
+		return fmt.Errorf("%s article does not support re-extraction", article.Kind)
+	}
+
+	fetched, err := extractor.Extract(raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to extract")
+	}
+
+	content, assets := g.assetArchiver.Archive(article.URL, fetched.Content, article.Assets)
+	for _, asset := range fetched.Assets {
+		if !article.Assets.ContainHash(asset.Hash) {
+			assets = append(assets, asset)
+		}
+	}
+	article.Content = content
+	article.Assets = append(article.Assets, assets...)
+	article.Extractor = extractorOf(fetched, article.Kind)
+	article.UpdateStatistics()
+	applyMetadata(article, fetched.ArticleMetadata)
+	return nil
 }
 
-func (g *articleGenerator) fetch(url string) (string, string, string, error) {
-	fetch := g.markdownFetcher.Fetch
-	kind := models.KindMarkdown
-	if g.tweetFetcher.IsFetchable(url) {
-		fetch = g.tweetFetcher.Fetch
-		kind = models.KindTweet
-	} else if g.slideShareFetcher.IsFetchable(url) {
-		fetch = g.slideShareFetcher.Fetch
-		kind = models.KindSlideShare
-	} else if g.youtubeFetcher.IsFetchable(url) {
-		fetch = g.youtubeFetcher.Fetch
-		kind = models.KindYoutube
-	}
-
-	title, content, err := fetch(url)
+func (g *articleGenerator) fetch(url string) (*FetchedArticle, string, error) {
+	var lastErr error
+	for _, fetcher := range g.fetchers {
+		if !fetcher.IsFetchable(url) {
+			continue
+		}
+
+		fetched, kind, err := g.fetchWith(fetcher, url)
+		if err != nil {
+			logrus.Warnf("failed to fetch %s with %s fetcher, fall through: %s", url, kind, err.Error())
+			lastErr = errors.Wrapf(err, "failed to fetch with %s fetcher", kind)
+			continue
+		}
+
+		return fetched, kind, nil
+	}
+
+	if lastErr != nil {
+		return nil, "", lastErr
+	}
+	return nil, "", errors.Wrapf(ErrUnsupportedContent, "no fetcher available for url: %s", url)
+}
+
+// fetchWith 는 fetcher 로 가져오되, 내려받은 응답의 내용을 보고 추출하겠다는 다른 fetcher 가 있으면 그 fetcher 로 추출한다.
+func (g *articleGenerator) fetchWith(fetcher ArticleFetcher, url string) (*FetchedArticle, string, error) {
+	rawFetcher, ok := fetcher.(RawArticleFetcher)
+	if !ok {
+		fetched, err := fetcher.Fetch(url)
+		return fetched, fetcher.Kind(), err
+	}
+
+	raw, err := rawFetcher.FetchRaw(url)
 	if err != nil {
-		return "", "", "", err
+		return nil, fetcher.Kind(), err
 	}
+	for _, other := range g.fetchers {
+		if e, ok := other.(ContentArticleExtractor); ok && other != fetcher && e.CanExtract(raw) {
+			fetched, err := e.Extract(raw)
+			return fetched, other.Kind(), err
+		}
+	}
+	fetched, err := rawFetcher.Extract(raw)
+	return fetched, fetcher.Kind(), err
+}
 
-	return title, content, kind, nil
+// rawExtractorOf 는 kind 의 article 을 원본 응답으로부터 추출할 수 있는 fetcher 이다. 없으면 nil 이다.
+func (g *articleGenerator) rawExtractorOf(kind string) RawArticleExtractor {
+	for _, fetcher := range g.fetchers {
+		if e, ok := fetcher.(RawArticleExtractor); ok && fetcher.Kind() == kind {
+			return e
+		}
+	}
+	return nil
+}
+
+// TrimUniqueTitleSuffix 는 getUniqueTitle 이 겹치는 제목 뒤에 붙인 `(1)` 을 뗀 제목이다.
+func TrimUniqueTitleSuffix(title string) string {
+	for strings.HasSuffix(title, uniqueTitleSuffix) {
+		title = strings.TrimSuffix(title, uniqueTitleSuffix)
+	}
+	return title
 }
 
 func (g *articleGenerator) getUniqueTitle(title string) (string, error) {
@@ -79,7 +269,7 @@ func (g *articleGenerator) getUniqueTitle(title string) (string, error) {
 	}
 
 	for isTitleExist {
-		title += "(1)"
+		title += uniqueTitleSuffix
 		isTitleExist, err = g.articleRepository.ExistByTitle(title)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to check title duplication")
@@ -88,3 +278,44 @@ func (g *articleGenerator) getUniqueTitle(title string) (string, error) {
 
 	return title, nil
 }
+
+func extractorOf(fetched *FetchedArticle, kind string) string {
+	if fetched.Extractor != "" {
+		return fetched.Extractor
+	}
+	return kind
+}
+
+// canonicalURLOf 는 fetcher 가 찾은 대표 url, redirect 를 따라간 최종 url, 요청한 url 순으로 정규화하여 돌려준다.
+func canonicalURLOf(fetched *FetchedArticle, url string) *string {
+	candidates := []string{fetched.CanonicalURL}
+	if fetched.Raw != nil {
+		candidates = append(candidates, fetched.Raw.URL)
+	}
+	candidates = append(candidates, url)
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if normalized, err := canonical.Normalize(candidate); err == nil {
+			return &normalized
+		}
+	}
+	return nil
+}
+
+func applyMetadata(article *models.Article, metadata ArticleMetadata) {
+	article.Author = metadata.Author
+	article.Published = metadata.Published
+	article.SiteName = metadata.SiteName
+	article.Excerpt = metadata.Excerpt
+	article.LeadImage = metadata.LeadImage
+	article.Language = metadata.Language
+
+	if article.SiteName == "" {
+		if u, err := netUrl.Parse(article.URL); err == nil {
+			article.SiteName = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package generators
+
+import (
+	"github.com/jaeyo/personal-archive/common/email"
+	"github.com/jaeyo/personal-archive/models"
+)
+
+type ArticleGeneratorMock struct {
+	OnNewArticle func(url string, tags []string) (*models.Article, error)
+	OnNewStub    func(url, title, content string, tags []string) (*models.Article, error)
+	OnNewEmail   func(message *email.Message, tags []string) (*models.Article, error)
+	OnNewFile    func(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error)
+	OnRefetch    func(article *models.Article) error
+	OnReExtract  func(article *models.Article, raw *RawResponse) error
+}
+
+func (m *ArticleGeneratorMock) NewArticle(url string, tags []string) (*models.Article, error) {
+	return m.OnNewArticle(url, tags)
+}
+
+func (m *ArticleGeneratorMock) NewStub(url, title, content string, tags []string) (*models.Article, error) {
+	return m.OnNewStub(url, title, content, tags)
+}
+
+func (m *ArticleGeneratorMock) NewEmail(message *email.Message, tags []string) (*models.Article, error) {
+	return m.OnNewEmail(message, tags)
+}
+
+func (m *ArticleGeneratorMock) NewFile(name string, data []byte, tags []string, splitChapters bool) (models.Articles, error) {
+	return m.OnNewFile(name, data, tags, splitChapters)
+}
+
+func (m *ArticleGeneratorMock) Refetch(article *models.Article) error {
+	return m.OnRefetch(article)
+}
+
+func (m *ArticleGeneratorMock) ReExtract(article *models.Article, raw *RawResponse) error {
+	return m.OnReExtract(article, raw)
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/jaeyo/personal-archive/models"
 	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"testing"
 )
 
@@ -22,23 +24,115 @@ func TestFetch(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			gen := &articleGenerator{
-				markdownFetcher:   getFetcherByKind(models.KindMarkdown, tc.kind == models.KindMarkdown),
-				tweetFetcher:      getFetcherByKind(models.KindTweet, tc.kind == models.KindTweet),
-				slideShareFetcher: getFetcherByKind(models.KindSlideShare, tc.kind == models.KindSlideShare),
-				youtubeFetcher:    getFetcherByKind(models.KindYoutube, tc.kind == models.KindYoutube),
+				fetchers: sortFetchers([]ArticleFetcher{
+					getFetcherByKind(models.KindMarkdown, PriorityFallback, true, nil),
+					getFetcherByKind(models.KindTweet, PrioritySite, tc.kind == models.KindTweet, nil),
+					getFetcherByKind(models.KindSlideShare, PrioritySite, tc.kind == models.KindSlideShare, nil),
+					getFetcherByKind(models.KindYoutube, PrioritySite, tc.kind == models.KindYoutube, nil),
+				}),
 			}
-			title, _, kind, err := gen.fetch("")
+			fetched, kind, err := gen.fetch("")
 			require.NoError(t, err)
 			require.Equal(t, tc.kind, kind)
-			require.Equal(t, fmt.Sprintf("fetched by %s", tc.kind), title)
+			require.Equal(t, fmt.Sprintf("fetched by %s", tc.kind), fetched.Title)
 		})
 	}
 }
 
-func getFetcherByKind(kind string, fetchable bool) ArticleFetcher {
+func TestFetchFallThrough(t *testing.T) {
+	gen := &articleGenerator{
+		fetchers: sortFetchers([]ArticleFetcher{
+			getFetcherByKind(models.KindMarkdown, PriorityFallback, true, nil),
+			getFetcherByKind(models.KindTweet, PrioritySite, true, errors.New("tweet not found")),
+		}),
+	}
+	fetched, kind, err := gen.fetch("")
+	require.NoError(t, err)
+	require.Equal(t, models.KindMarkdown, kind)
+	require.Equal(t, "fetched by markdown", fetched.Title)
+
+	gen = &articleGenerator{
+		fetchers: []ArticleFetcher{
+			getFetcherByKind(models.KindTweet, PrioritySite, true, errors.New("tweet not found")),
+		},
+	}
+	_, _, err = gen.fetch("")
+	require.EqualError(t, err, "failed to fetch with tweet fetcher: tweet not found")
+}
+
+func TestRefetchChangedKind(t *testing.T) {
+	canonicalURL := "https://www.youtube.com/watch?v=abc"
+	article := &models.Article{
+		ID:           1,
+		Kind:         models.KindYoutube,
+		URL:          "https://example.com/post",
+		Title:        "fetched by markdown",
+		CanonicalURL: &canonicalURL,
+		Embed:        models.ArticleEmbed{Provider: "YouTube", Src: "https://www.youtube.com/embed/abc"},
+	}
+	gen := &articleGenerator{
+		fetchers:      []ArticleFetcher{getFetcherByKind(models.KindMarkdown, PriorityFallback, true, nil)},
+		assetArchiver: &articleAssetArchiver{},
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+		},
+	}
+
+	// kind 가 바뀌면 이전 embed 와 canonical url 을 새로 가져온 것으로 바꾼다
+	require.NoError(t, gen.Refetch(article))
+	require.Equal(t, models.KindMarkdown, article.Kind)
+	require.Equal(t, models.ArticleEmbed{}, article.Embed)
+	require.Equal(t, "https://example.com/post", *article.CanonicalURL)
+}
+
+func TestRefetchSuffixedTitle(t *testing.T) {
+	article := &models.Article{ID: 1, Kind: models.KindMarkdown, URL: "https://example.com/post", Title: "fetched by markdown(1)"}
+	gen := &articleGenerator{
+		fetchers:      []ArticleFetcher{getFetcherByKind(models.KindMarkdown, PriorityFallback, true, nil)},
+		assetArchiver: &articleAssetArchiver{},
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnExistByTitle: func(title string) (bool, error) {
+				return title == "fetched by markdown" || title == "fetched by markdown(1)", nil
+			},
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return article, nil },
+		},
+	}
+
+	// 겹치지 않도록 붙인 `(1)` 때문에 다시 가져올 때마다 제목이 길어지지 않는다
+	for i := 0; i < 2; i++ {
+		require.NoError(t, gen.Refetch(article))
+		require.Equal(t, "fetched by markdown(1)", article.Title)
+	}
+}
+
+func TestSortFetchers(t *testing.T) {
+	sorted := sortFetchers([]ArticleFetcher{
+		getFetcherByKind(models.KindMarkdown, PriorityFallback, true, nil),
+		getFetcherByKind(models.KindTweet, PrioritySite, true, nil),
+		getFetcherByKind(models.KindSlideShare, PriorityDefault, true, nil),
+		getFetcherByKind(models.KindYoutube, PrioritySite, true, nil),
+	})
+
+	var kinds []string
+	for _, fetcher := range sorted {
+		kinds = append(kinds, fetcher.Kind())
+	}
+	require.Equal(t, []string{models.KindTweet, models.KindYoutube, models.KindSlideShare, models.KindMarkdown}, kinds)
+}
+
+func getFetcherByKind(kind string, priority int, fetchable bool, fetchErr error) ArticleFetcher {
 	return &ArticleFetcherMock{
-		OnFetch: func(url string) (string, string, error) {
-			return fmt.Sprintf("fetched by %s", kind), "", nil
+		OnKind: func() string {
+			return kind
+		},
+		OnPriority: func() int {
+			return priority
+		},
+		OnFetch: func(url string) (*FetchedArticle, error) {
+			if fetchErr != nil {
+				return nil, fetchErr
+			}
+			return &FetchedArticle{Title: fmt.Sprintf("fetched by %s", kind)}, nil
 		},
 		OnIsFetchable: func(url string) bool {
 			return fetchable
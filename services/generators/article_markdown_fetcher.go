@@ -1,125 +1,238 @@
 package generators
 
 import (
-	"fmt"
+	"bytes"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-shiori/go-readability"
 	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
-	"os"
-	"os/exec"
+	"github.com/sirupsen/logrus"
+	netUrl "net/url"
 	"strings"
 )
 
 type articleMarkdownFetcher struct {
+	densityExtractor         *articleDensityExtractor
+	extractionRuleRepository repositories.ExtractionRuleRepository
 }
 
-func (g *articleMarkdownFetcher) Fetch(url string) (string, string, error) {
-	title, content, err := g.getTitleAndContent1(url)
-	if err == nil {
-		return title, content, nil
+func init() {
+	RegisterFetcher(func() ArticleFetcher {
+		return &articleMarkdownFetcher{
+			densityExtractor:         &articleDensityExtractor{},
+			extractionRuleRepository: repositories.GetExtractionRuleRepository(),
+		}
+	})
+}
+
+// PreviewExtraction 은 url 을 markdown fetcher 로 추출한 결과를 돌려준다. rule 이 nil 이면 저장된 rule 중 일치하는 것을 적용한다.
+// 이미지 보관, snapshot 등은 하지 않는다.
+func PreviewExtraction(url string, rule *models.ExtractionRule) (*FetchedArticle, error) {
+	raw, err := getRaw(url)
+	if err != nil {
+		return nil, err
 	}
 
-	return g.getTitleAndContent2(url)
+	fetcher := &articleMarkdownFetcher{
+		densityExtractor:         &articleDensityExtractor{},
+		extractionRuleRepository: repositories.GetExtractionRuleRepository(),
+	}
+	if rule == nil {
+		return fetcher.Extract(raw)
+	}
+	return fetcher.extract(raw, rule)
 }
 
-func (g *articleMarkdownFetcher) IsFetchable(url string) bool {
-	return true
+func (g *articleMarkdownFetcher) Kind() string {
+	return models.KindMarkdown
 }
 
-func (g *articleMarkdownFetcher) getTitleAndContent1(url string) (string, string, error) {
-	title, htmlContent, err := g.extractReadable(url)
-	if err != nil {
-		return "", "", errors.Wrap(err, "failed to extract readable")
-	}
+func (g *articleMarkdownFetcher) Priority() int {
+	return PriorityFallback
+}
 
-	markdownContent, err := markdown.ConvertFromHtml(htmlContent)
+func (g *articleMarkdownFetcher) Fetch(url string) (*FetchedArticle, error) {
+	raw, err := g.FetchRaw(url)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to convert html to markdown")
+		return nil, err
 	}
 
-	return title, markdownContent, nil
+	return g.Extract(raw)
 }
 
-func (g *articleMarkdownFetcher) extractReadable(url string) (string, string, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", "", errors.Wrap(err, "failed to request url")
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return "", "", fmt.Errorf("response code is not success: %d", resp.StatusCode)
+func (g *articleMarkdownFetcher) FetchRaw(url string) (*RawResponse, error) {
+	return getRaw(url)
+}
+
+func (g *articleMarkdownFetcher) Extract(raw *RawResponse) (*FetchedArticle, error) {
+	if !isTextContent(raw) {
+		return nil, errors.Wrapf(ErrUnsupportedContent, "content type %s", raw.ContentType())
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	rule, err := g.findRule(raw.URL)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to parse response body")
+		logrus.Warnf("failed to find extraction rule for %s: %s", raw.URL, err.Error())
 	}
+	return g.extract(raw, rule)
+}
 
-	// 코드 블럭의 랭기지 타입은 주로 class 에 `language-go` 와 같은 형태로 지정되어 있는 경우가 많은데
-	// readability 는 class 정보를 전부 날리기 때문에 랭기지 정보를 나중에는 확인할 수 없다.
-	// 따라서 readability 가 날리지 않도록 별도의 `data-lang` attr 에 따로 박아넣는다.
-	prefix := "language-"
-	doc.Find("pre code").Each(func(i int, selection *goquery.Selection) {
-		var classes []string
-		classes = append(classes, strings.Split(selection.AttrOr("class", ""), " ")...)
-		classes = append(classes, strings.Split(selection.Parent().AttrOr("class", ""), " ")...)
-
-		for _, class := range classes {
-			if strings.HasPrefix(class, prefix) {
-				lang := class[len(prefix):]
-				selection.SetAttr("data-lang", lang)
-				return
+func (g *articleMarkdownFetcher) extract(raw *RawResponse, rule *models.ExtractionRule) (*FetchedArticle, error) {
+	readable := raw
+	var ruleTitle string
+	// 앞선 출처의 값이 우선한다: rule > JSON-LD > OpenGraph/meta > readability
+	var metadata ArticleMetadata
+	if rule != nil {
+		extracted, err := extractByRule(raw, rule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to extract by rule for %s", rule.Domain)
+		}
+		ruleTitle = extracted.title
+		metadata.Author = extracted.author
+		metadata.merge(extractMetadata(raw))
+
+		if extracted.content != "" {
+			title := ruleTitle
+			if title == "" {
+				title = getTitleFromHtmlOrURL(extracted.html, raw.URL)
 			}
+			return &FetchedArticle{
+				Title:           title,
+				Content:         extracted.content,
+				ArticleMetadata: metadata,
+				Raw:             raw,
+				Extractor:       ExtractorRule,
+				CanonicalURL:    extractCanonicalURL(raw),
+			}, nil
 		}
-	})
 
-	html, err := doc.Html()
+		// content selector 가 없으면 불필요한 요소만 지운 html 을 readability 에 넘긴다
+		readable = &RawResponse{
+			URL:        raw.URL,
+			StatusCode: raw.StatusCode,
+			Header:     raw.Header,
+			Body:       []byte(extracted.html),
+		}
+	} else {
+		metadata = extractMetadata(raw)
+	}
+
+	extractor := ExtractorReadability
+	title, content, readableMetadata, err := g.getTitleAndContent1(readable)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to get html from goquery")
+		logrus.Warnf("failed to extract %s with readability, fall back to text density: %s", raw.URL, err.Error())
+
+		extractor = ExtractorTextDensity
+		title, content, err = g.densityExtractor.Extract(readable)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to extract by text density")
+		}
+	}
+	if ruleTitle != "" {
+		title = ruleTitle
+	}
+	metadata.merge(readableMetadata)
+
+	return &FetchedArticle{
+		Title:           title,
+		Content:         content,
+		ArticleMetadata: metadata,
+		Raw:             raw,
+		Extractor:       extractor,
+		CanonicalURL:    extractCanonicalURL(raw),
+	}, nil
+}
+
+func (g *articleMarkdownFetcher) findRule(url string) (*models.ExtractionRule, error) {
+	if g.extractionRuleRepository == nil {
+		return nil, nil
 	}
 
-	result, err := readability.FromReader(strings.NewReader(html), url)
+	u, err := netUrl.Parse(url)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to execute readability module")
+		return nil, errors.Wrap(err, "failed to parse url")
 	}
-	return result.Title, result.Content, nil
+
+	rules, err := g.extractionRuleRepository.FindByDomains(models.DomainCandidates(u.Hostname()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find extraction rules")
+	}
+	return rules.MostSpecificFor(u.Hostname()), nil
+}
+
+func (g *articleMarkdownFetcher) IsFetchable(url string) bool {
+	return true
 }
 
-func (g *articleMarkdownFetcher) getTitleAndContent2(url string) (string, string, error) {
-	resp, err := http.Get(url)
+func (g *articleMarkdownFetcher) getTitleAndContent1(raw *RawResponse) (string, string, ArticleMetadata, error) {
+	result, err := g.extractReadable(raw)
 	if err != nil {
-		return "", "", err
+		return "", "", ArticleMetadata{}, errors.Wrap(err, "failed to extract readable")
 	}
-	defer resp.Body.Close()
 
-	htmlByte, err := ioutil.ReadAll(resp.Body)
-	html := string(htmlByte)
+	markdownContent, err := markdown.ConvertFromHtml(result.Content)
+	if err != nil {
+		return "", "", ArticleMetadata{}, errors.Wrap(err, "failed to convert html to markdown")
+	} else if strings.TrimSpace(markdownContent) == "" {
+		return "", "", ArticleMetadata{}, errors.New("empty content")
+	}
 
-	title, err := getTitleFromHtml(html)
-	if err != nil || title == "" {
-		title = url
+	metadata := ArticleMetadata{
+		Author:    strings.TrimSpace(result.Byline),
+		SiteName:  strings.TrimSpace(result.SiteName),
+		Excerpt:   strings.TrimSpace(result.Excerpt),
+		LeadImage: result.Image,
 	}
+	return result.Title, markdownContent, metadata, nil
+}
 
-	f, err := ioutil.TempFile("", "")
+func (g *articleMarkdownFetcher) extractReadable(raw *RawResponse) (*readability.Article, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw.Body))
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to create temp file")
+		return nil, errors.Wrap(err, "failed to parse response body")
 	}
-	defer os.Remove(f.Name())
 
-	if err := ioutil.WriteFile(f.Name(), htmlByte, 0644); err != nil {
-		return "", "", errors.Wrap(err, "failed to write html file")
+	markCodeLanguage(doc)
+
+	html, err := doc.Html()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get html from goquery")
 	}
 
-	out, err := exec.Command("./bin/html2text.py", "-b", "0", "-d", f.Name()).Output()
+	result, err := readability.FromReader(strings.NewReader(html), raw.URL)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to execute html2text")
+		return nil, errors.Wrap(err, "failed to execute readability module")
 	}
-	content := string(out)
+	return &result, nil
+}
 
-	return title, content, nil
+// 코드 블럭의 랭기지 타입은 주로 class 에 `language-go` 와 같은 형태로 지정되어 있는 경우가 많은데
+// readability 는 class 정보를 전부 날리기 때문에 랭기지 정보를 나중에는 확인할 수 없다.
+// 따라서 readability 가 날리지 않도록 별도의 `data-lang` attr 에 따로 박아넣는다.
+func markCodeLanguage(doc *goquery.Document) {
+	prefix := "language-"
+	doc.Find("pre code").Each(func(i int, selection *goquery.Selection) {
+		var classes []string
+		classes = append(classes, strings.Split(selection.AttrOr("class", ""), " ")...)
+		classes = append(classes, strings.Split(selection.Parent().AttrOr("class", ""), " ")...)
+
+		for _, class := range classes {
+			if strings.HasPrefix(class, prefix) {
+				lang := class[len(prefix):]
+				selection.SetAttr("data-lang", lang)
+				return
+			}
+		}
+	})
+}
+
+func getTitleFromHtmlOrURL(html, url string) string {
+	title, err := getTitleFromHtml(html)
+	if err != nil || strings.TrimSpace(title) == "" {
+		return url
+	}
+	return strings.TrimSpace(title)
 }
 
 func getTitleFromHtml(html string) (string, error) {
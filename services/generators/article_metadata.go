@@ -0,0 +1,274 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/PuerkitoBio/goquery"
+	netUrl "net/url"
+	"strings"
+	"time"
+)
+
+// ArticleMetadata 는 본문 외에 페이지에서 찾은 부가 정보다.
+type ArticleMetadata struct {
+	Author    string
+	Published *time.Time
+	SiteName  string
+	Excerpt   string
+	// 대표 이미지 url
+	LeadImage string
+	// e.g. `en`, `ko`
+	Language string
+}
+
+// merge 는 비어있는 필드를 other 의 값으로 채운다. 즉 먼저 채워진 값이 우선한다.
+func (m *ArticleMetadata) merge(other ArticleMetadata) {
+	if m.Author == "" {
+		m.Author = other.Author
+	}
+	if m.Published == nil {
+		m.Published = other.Published
+	}
+	if m.SiteName == "" {
+		m.SiteName = other.SiteName
+	}
+	if m.Excerpt == "" {
+		m.Excerpt = other.Excerpt
+	}
+	if m.LeadImage == "" {
+		m.LeadImage = other.LeadImage
+	}
+	if m.Language == "" {
+		m.Language = other.Language
+	}
+}
+
+// extractMetadata 는 JSON-LD, OpenGraph, 일반 meta 태그 순서로 메타데이터를 찾는다.
+func extractMetadata(raw *RawResponse) ArticleMetadata {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw.Body))
+	if err != nil {
+		return ArticleMetadata{}
+	}
+
+	metadata := extractJsonLdMetadata(doc)
+	metadata.merge(extractMetaTagMetadata(doc))
+
+	if metadata.LeadImage != "" {
+		if leadImage, err := resolveURL(raw.URL, metadata.LeadImage); err == nil {
+			metadata.LeadImage = leadImage
+		} else {
+			metadata.LeadImage = ""
+		}
+	}
+	metadata.Language = normalizeLanguage(metadata.Language)
+	return metadata
+}
+
+// extractCanonicalURL 은 `<link rel="canonical">` 이 가리키는 url 을 돌려준다. AMP 페이지도 원래 페이지를 canonical 로 가리킨다.
+// 모든 페이지의 canonical 을 첫 페이지로 지정하는 사이트가 있어서, 그런 경우는 무시한다.
+func extractCanonicalURL(raw *RawResponse) string {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw.Body))
+	if err != nil {
+		return ""
+	}
+
+	href := strings.TrimSpace(doc.Find(`link[rel~="canonical"]`).First().AttrOr("href", ""))
+	if href == "" {
+		return ""
+	}
+	canonicalURL, err := resolveURL(raw.URL, href)
+	if err != nil {
+		return ""
+	}
+
+	canonical, err := netUrl.Parse(canonicalURL)
+	if err != nil {
+		return ""
+	}
+	if original, err := netUrl.Parse(raw.URL); err == nil && strings.Trim(canonical.Path, "/") == "" && strings.Trim(original.Path, "/") != "" {
+		return ""
+	}
+	return canonicalURL
+}
+
+func extractMetaTagMetadata(doc *goquery.Document) ArticleMetadata {
+	meta := func(keys ...string) string {
+		for _, key := range keys {
+			var value string
+			doc.Find("meta").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+				name := s.AttrOr("property", s.AttrOr("name", s.AttrOr("itemprop", s.AttrOr("http-equiv", ""))))
+				if strings.EqualFold(name, key) {
+					value = strings.TrimSpace(s.AttrOr("content", ""))
+				}
+				return value == ""
+			})
+			if value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	metadata := ArticleMetadata{
+		Author:    meta("author", "article:author", "twitter:creator", "dc.creator"),
+		SiteName:  meta("og:site_name", "application-name", "twitter:site"),
+		Excerpt:   meta("og:description", "description", "twitter:description"),
+		LeadImage: meta("og:image", "og:image:url", "twitter:image", "twitter:image:src"),
+		Language:  strings.TrimSpace(doc.Find("html").AttrOr("lang", "")),
+	}
+	if metadata.Language == "" {
+		metadata.Language = meta("og:locale", "content-language", "dc.language")
+	}
+	// 작성자 자리에 프로필 url 을 넣는 사이트가 많다
+	if strings.HasPrefix(metadata.Author, "http://") || strings.HasPrefix(metadata.Author, "https://") {
+		metadata.Author = ""
+	}
+
+	published := meta("article:published_time", "datePublished", "date", "pubdate", "publish_date", "dc.date", "dc.date.issued")
+	if published == "" {
+		published = doc.Find("time[datetime]").First().AttrOr("datetime", "")
+	}
+	metadata.Published = parsePublished(published)
+
+	return metadata
+}
+
+func extractJsonLdMetadata(doc *goquery.Document) ArticleMetadata {
+	var metadata ArticleMetadata
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return
+		}
+		for _, node := range jsonLdNodes(data) {
+			if !isJsonLdArticle(node) {
+				continue
+			}
+			metadata.merge(ArticleMetadata{
+				Author:    jsonLdName(node["author"]),
+				Published: parsePublished(jsonLdString(node["datePublished"])),
+				SiteName:  jsonLdName(node["publisher"]),
+				Excerpt:   jsonLdString(node["description"]),
+				LeadImage: jsonLdURL(node["image"]),
+				Language:  jsonLdString(node["inLanguage"]),
+			})
+		}
+	})
+	return metadata
+}
+
+// jsonLdNodes 는 최상위 배열과 `@graph` 를 풀어 node 목록으로 만든다.
+func jsonLdNodes(data interface{}) []map[string]interface{} {
+	var nodes []map[string]interface{}
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			nodes = append(nodes, jsonLdNodes(item)...)
+		}
+	case map[string]interface{}:
+		nodes = append(nodes, v)
+		if graph, ok := v["@graph"]; ok {
+			nodes = append(nodes, jsonLdNodes(graph)...)
+		}
+	}
+	return nodes
+}
+
+func isJsonLdArticle(node map[string]interface{}) bool {
+	var types []string
+	switch v := node["@type"].(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	for _, t := range types {
+		if strings.HasSuffix(t, "Article") || strings.HasSuffix(t, "Posting") || t == "WebPage" || t == "Report" {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonLdString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return ""
+}
+
+// jsonLdName 은 `"author": "name"`, `"author": {"name": ...}`, `"author": [{"name": ...}]` 를 모두 받는다.
+func jsonLdName(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]interface{}:
+		return jsonLdString(value["name"])
+	case []interface{}:
+		var names []string
+		for _, item := range value {
+			if name := jsonLdName(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+func jsonLdURL(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]interface{}:
+		return jsonLdString(value["url"])
+	case []interface{}:
+		if len(value) > 0 {
+			return jsonLdURL(value[0])
+		}
+	}
+	return ""
+}
+
+var publishedLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+func parsePublished(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range publishedLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// normalizeLanguage 는 `en-US`, `en_US` 와 같은 값에서 주 언어 코드만 남긴다.
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if idx := strings.IndexAny(language, "-_,; "); idx >= 0 {
+		language = language[:idx]
+	}
+	if len(language) < 2 || len(language) > 3 {
+		return ""
+	}
+	return language
+}
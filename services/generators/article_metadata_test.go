@@ -0,0 +1,34 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	body := `<html lang="en-US">
+<head>
+	<meta property="og:site_name" content="Example Blog">
+	<meta property="og:description" content="OpenGraph description">
+	<meta property="og:image" content="/images/lead.png">
+	<meta name="author" content="Meta Author">
+	<script type="application/ld+json">
+	{"@context": "https://schema.org", "@graph": [
+		{"@type": "WebSite", "name": "ignored"},
+		{"@type": "BlogPosting", "author": [{"@type": "Person", "name": "Jane Doe"}], "datePublished": "2021-03-04T05:06:07+09:00"}
+	]}
+	</script>
+</head>
+<body></body>
+</html>`
+
+	metadata := extractMetadata(&RawResponse{URL: "https://example.com/posts/1", Body: []byte(body)})
+	require.Equal(t, "Jane Doe", metadata.Author)
+	require.Equal(t, "Example Blog", metadata.SiteName)
+	require.Equal(t, "OpenGraph description", metadata.Excerpt)
+	require.Equal(t, "https://example.com/images/lead.png", metadata.LeadImage)
+	require.Equal(t, "en", metadata.Language)
+	require.NotNil(t, metadata.Published)
+	require.True(t, time.Date(2021, 3, 3, 20, 6, 7, 0, time.UTC).Equal(*metadata.Published))
+}
@@ -0,0 +1,237 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/jaeyo/personal-archive/common/sanitize"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	netUrl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	oEmbedMaxWidth  = 800
+	oEmbedMaxHeight = 800
+)
+
+type oEmbedProvider struct {
+	name     string
+	urlRegex *regexp.Regexp
+	endpoint string
+	// iframe src 로 허용할 host
+	iframeHosts []string
+}
+
+var oEmbedProviders = []*oEmbedProvider{
+	{
+		name:        "SlideShare",
+		urlRegex:    regexp.MustCompile(`^https?://(?:www\.|[a-z]{2}\.)?slideshare\.net/[^/?#]+/[^/?#]+`),
+		endpoint:    "https://www.slideshare.net/api/oembed/2",
+		iframeHosts: []string{"slideshare.net"},
+	},
+	{
+		name:        "Vimeo",
+		urlRegex:    regexp.MustCompile(`^https?://(?:www\.|player\.)?vimeo\.com/(?:video/|channels/[^/]+/|groups/[^/]+/videos/)?[0-9]+`),
+		endpoint:    "https://vimeo.com/api/oembed.json",
+		iframeHosts: []string{"player.vimeo.com"},
+	},
+	{
+		name:        "SoundCloud",
+		urlRegex:    regexp.MustCompile(`^https?://(?:www\.|m\.)?soundcloud\.com/[^/?#]+/[^/?#]+`),
+		endpoint:    "https://soundcloud.com/oembed",
+		iframeHosts: []string{"w.soundcloud.com"},
+	},
+	{
+		name:        "Speaker Deck",
+		urlRegex:    regexp.MustCompile(`^https?://speakerdeck\.com/[^/?#]+/[^/?#]+`),
+		endpoint:    "https://speakerdeck.com/oembed.json",
+		iframeHosts: []string{"speakerdeck.com"},
+	},
+	{
+		name:        "Spotify",
+		urlRegex:    regexp.MustCompile(`^https?://open\.spotify\.com/(?:track|album|playlist|episode|show)/[A-Za-z0-9]+`),
+		endpoint:    "https://open.spotify.com/oembed",
+		iframeHosts: []string{"open.spotify.com"},
+	},
+	{
+		name:        "CodePen",
+		urlRegex:    regexp.MustCompile(`^https?://codepen\.io/[^/?#]+/pen/[A-Za-z0-9]+`),
+		endpoint:    "https://codepen.io/api/oembed",
+		iframeHosts: []string{"codepen.io"},
+	},
+}
+
+// oEmbed html 에서 iframe 외에 남겨둘 element
+var oEmbedPolicyElements = []string{"div", "p", "span", "strong", "em", "a"}
+
+type articleOEmbedFetcher struct {
+	providers []*oEmbedProvider
+}
+
+func init() {
+	RegisterFetcher(func() ArticleFetcher {
+		return &articleOEmbedFetcher{providers: oEmbedProviders}
+	})
+}
+
+func (g *articleOEmbedFetcher) Kind() string {
+	return models.KindEmbed
+}
+
+func (g *articleOEmbedFetcher) Priority() int {
+	return PrioritySite
+}
+
+func (g *articleOEmbedFetcher) IsFetchable(url string) bool {
+	return g.findProvider(url) != nil
+}
+
+func (g *articleOEmbedFetcher) Fetch(url string) (*FetchedArticle, error) {
+	provider := g.findProvider(url)
+	if provider == nil {
+		return nil, fmt.Errorf("no oembed provider for %s", url)
+	}
+
+	query := netUrl.Values{}
+	query.Set("url", url)
+	query.Set("format", "json")
+	query.Set("maxwidth", strconv.Itoa(oEmbedMaxWidth))
+	query.Set("maxheight", strconv.Itoa(oEmbedMaxHeight))
+
+	resp, err := fetch.Get(provider.endpoint + "?" + query.Encode())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request oEmbed")
+	} else if err := fetch.CheckStatus(resp); err != nil {
+		return nil, err
+	}
+	if !gjson.ValidBytes(resp.Body) {
+		return nil, errors.New("invalid oEmbed response")
+	}
+
+	return provider.toFetchedArticle(url, gjson.ParseBytes(resp.Body))
+}
+
+func (g *articleOEmbedFetcher) findProvider(url string) *oEmbedProvider {
+	for _, provider := range g.providers {
+		if provider.urlRegex.MatchString(url) {
+			return provider
+		}
+	}
+	return nil
+}
+
+func (p *oEmbedProvider) policy() *sanitize.Policy {
+	return sanitize.NewPolicy().
+		AllowElements([]string{"href", "title"}, oEmbedPolicyElements...).
+		AllowIframes(p.iframeHosts...)
+}
+
+func (p *oEmbedProvider) toFetchedArticle(url string, data gjson.Result) (*FetchedArticle, error) {
+	embed, err := p.parseEmbedHtml(data.Get("html").String())
+	if err != nil {
+		return nil, err
+	}
+	if embed.Width == 0 {
+		embed.Width = int(data.Get("width").Int())
+	}
+	if embed.Height == 0 {
+		embed.Height = int(data.Get("height").Int())
+	}
+
+	title := strings.TrimSpace(data.Get("title").String())
+	if title == "" {
+		title = url
+	}
+	authorName := strings.TrimSpace(data.Get("author_name").String())
+	authorURL, _ := resolveURL(url, data.Get("author_url").String())
+	thumbnail, _ := resolveURL(url, data.Get("thumbnail_url").String())
+	// description 은 표준 필드가 아니며 provider 에 따라 html 이 섞여 있다
+	description := sanitize.Text(data.Get("description").String())
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("**Provider**: [%s](%s)  \n", p.name, url))
+	if authorName != "" {
+		if authorURL != "" {
+			content.WriteString(fmt.Sprintf("**Author**: [%s](%s)  \n", escapeMarkdownText(authorName), authorURL))
+		} else {
+			content.WriteString(fmt.Sprintf("**Author**: %s  \n", escapeMarkdownText(authorName)))
+		}
+	}
+	if description != "" {
+		content.WriteString("\n")
+		content.WriteString(plainTextToMarkdown(description))
+		content.WriteString("\n")
+	}
+	if thumbnail != "" {
+		content.WriteString(fmt.Sprintf("\n![](%s)\n", thumbnail))
+	}
+
+	return &FetchedArticle{
+		Title:   title,
+		Content: content.String(),
+		ArticleMetadata: ArticleMetadata{
+			Author:    authorName,
+			SiteName:  p.name,
+			Excerpt:   truncateText(description, 200),
+			LeadImage: thumbnail,
+		},
+		Embed: embed,
+	}, nil
+}
+
+// parseEmbedHtml 은 provider 가 준 html 을 sanitize 한 뒤 남은 iframe 의 주소와 크기를 얻는다.
+func (p *oEmbedProvider) parseEmbedHtml(embedHtml string) (*models.ArticleEmbed, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(p.policy().Sanitize(embedHtml)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse embed html")
+	}
+
+	iframe := doc.Find("iframe").First()
+	src := iframe.AttrOr("src", "")
+	if src == "" {
+		return nil, fmt.Errorf("no allowed iframe in %s embed html", p.name)
+	}
+
+	width, _ := strconv.Atoi(iframe.AttrOr("width", ""))
+	height, _ := strconv.Atoi(iframe.AttrOr("height", ""))
+	return &models.ArticleEmbed{
+		Provider: p.name,
+		Src:      src,
+		Width:    width,
+		Height:   height,
+	}, nil
+}
+
+// MigrateLegacySlideShare 는 oEmbed html 을 그대로 content 에 저장했던 예전 SlideShare article 을 KindEmbed 로 옮긴다.
+func MigrateLegacySlideShare(article *models.Article) error {
+	var provider *oEmbedProvider
+	for _, p := range oEmbedProviders {
+		if p.name == "SlideShare" {
+			provider = p
+		}
+	}
+
+	embed, err := provider.parseEmbedHtml(article.Content)
+	if err != nil {
+		return err
+	}
+
+	// iframe 아래에 붙어있던 제목, 작성자 링크만 markdown 으로 남긴다
+	content, err := markdown.ConvertFromHtml(provider.policy().Sanitize(article.Content))
+	if err != nil {
+		return errors.Wrap(err, "failed to convert html to markdown")
+	}
+
+	article.Kind = models.KindEmbed
+	article.Embed = *embed
+	article.Content = content
+	article.SiteName = provider.name
+	article.UpdateStatistics()
+	return nil
+}
@@ -0,0 +1,77 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestIsFetchableOEmbed(t *testing.T) {
+	gen := &articleOEmbedFetcher{providers: oEmbedProviders}
+	require.True(t, gen.IsFetchable("https://www.slideshare.net/sanggi/ss-72845788"))
+	require.True(t, gen.IsFetchable("https://vimeo.com/76979871"))
+	require.True(t, gen.IsFetchable("https://soundcloud.com/forss/flickermood"))
+	require.True(t, gen.IsFetchable("https://speakerdeck.com/gopher/go-in-production"))
+	require.False(t, gen.IsFetchable("https://www.slideshare.net/sanggi"))
+	require.False(t, gen.IsFetchable("https://example.com/76979871"))
+}
+
+func TestFetchOEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "https://vimeo.com/76979871", r.URL.Query().Get("url"))
+		fmt.Fprint(w, `{"type":"video","title":"The New Vimeo Player","author_name":"Vimeo Staff","author_url":"https://vimeo.com/staff",
+			"description":"<p>It may look <b>the same</b></p><script>alert(1)</script>","thumbnail_url":"https://i.vimeocdn.com/video/452001751.jpg",
+			"width":800,"height":450,
+			"html":"<iframe src=\"https://player.vimeo.com/video/76979871\" width=\"640\" height=\"360\" onload=\"alert(1)\"></iframe><script>alert(1)</script>"}`)
+	}))
+	defer server.Close()
+
+	gen := &articleOEmbedFetcher{providers: []*oEmbedProvider{{
+		name:        "Vimeo",
+		urlRegex:    regexp.MustCompile(`^https://vimeo\.com/[0-9]+`),
+		endpoint:    server.URL,
+		iframeHosts: []string{"player.vimeo.com"},
+	}}}
+	article, err := gen.Fetch("https://vimeo.com/76979871")
+	require.Nil(t, err)
+
+	require.Equal(t, "The New Vimeo Player", article.Title)
+	require.Equal(t, &models.ArticleEmbed{Provider: "Vimeo", Src: "https://player.vimeo.com/video/76979871", Width: 640, Height: 360}, article.Embed)
+	require.Equal(t, "Vimeo Staff", article.Author)
+	require.Equal(t, "https://i.vimeocdn.com/video/452001751.jpg", article.LeadImage)
+	require.Contains(t, article.Content, "It may look the same")
+	require.NotContains(t, article.Content, "alert")
+}
+
+func TestFetchOEmbedRejectsForeignIframe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"title":"x","html":"<iframe src=\"https://evil.example.com/\"></iframe>"}`)
+	}))
+	defer server.Close()
+
+	gen := &articleOEmbedFetcher{providers: []*oEmbedProvider{{
+		name:        "SlideShare",
+		urlRegex:    regexp.MustCompile(`.*`),
+		endpoint:    server.URL,
+		iframeHosts: []string{"slideshare.net"},
+	}}}
+	_, err := gen.Fetch("https://www.slideshare.net/sanggi/ss-72845788")
+	require.NotNil(t, err)
+}
+
+func TestMigrateLegacySlideShare(t *testing.T) {
+	article := &models.Article{
+		Kind:    models.KindSlideShare,
+		Content: "<iframe src=\"https://www.slideshare.net/slideshow/embed_code/key/1rjaIdJKRVdQrw\" width=\"800\" height=\"638\" frameborder=\"0\" allowfullscreen> </iframe> <div style=\"margin-bottom:5px\"> <strong> <a href=\"https://www.slideshare.net/hatemogi/devon2013-git\" title=\"devon2013\" target=\"_blank\">devon2013</a> </strong> from <strong><a href=\"https://www.slideshare.net/hatemogi\" target=\"_blank\">Daehyun Kim</a></strong> </div><script>alert(1)</script>",
+	}
+	require.Nil(t, MigrateLegacySlideShare(article))
+
+	require.Equal(t, models.KindEmbed, article.Kind)
+	require.Equal(t, models.ArticleEmbed{Provider: "SlideShare", Src: "https://www.slideshare.net/slideshow/embed_code/key/1rjaIdJKRVdQrw", Width: 800, Height: 638}, article.Embed)
+	require.Contains(t, article.Content, "[Daehyun Kim](https://www.slideshare.net/hatemogi)")
+	require.NotContains(t, article.Content, "alert")
+}
@@ -0,0 +1,310 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/ledongthuc/pdf"
+	"github.com/pkg/errors"
+	"math"
+	netUrl "net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const pdfContentType = "application/pdf"
+
+var pdfMagic = []byte("%PDF-")
+
+func isPdfResponse(raw *RawResponse) bool {
+	return isPdfContentType(raw.Header.Get("Content-Type")) || bytes.HasPrefix(raw.Body, pdfMagic)
+}
+
+type articlePdfFetcher struct {
+	assetStore internal.AssetStore
+}
+
+func init() {
+	RegisterFetcher(func() ArticleFetcher {
+		return &articlePdfFetcher{
+			assetStore: internal.GetAssetStore(),
+		}
+	})
+}
+
+func (g *articlePdfFetcher) Kind() string {
+	return models.KindPDF
+}
+
+// 일반 웹페이지보다는 먼저, 사이트 전용 fetcher 보다는 나중에 시도한다
+func (g *articlePdfFetcher) Priority() int {
+	return PriorityDefault
+}
+
+// IsFetchable 은 확장자가 .pdf 인 url 만 받는다. 확장자가 없는 pdf 는 CanExtract 로 내려받은 응답을 보고 알아낸다.
+func (g *articlePdfFetcher) IsFetchable(url string) bool {
+	u, err := netUrl.Parse(url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return strings.EqualFold(path.Ext(u.Path), ".pdf")
+}
+
+func (g *articlePdfFetcher) Fetch(url string) (*FetchedArticle, error) {
+	raw, err := getRaw(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Extract(raw)
+}
+
+func (g *articlePdfFetcher) CanExtract(raw *RawResponse) bool {
+	return isPdfResponse(raw)
+}
+
+func (g *articlePdfFetcher) Extract(raw *RawResponse) (*FetchedArticle, error) {
+	if !isPdfResponse(raw) {
+		return nil, fmt.Errorf("not a pdf: %s", raw.ContentType())
+	}
+
+	title, content, err := convertPdfToMarkdown(raw.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert pdf to markdown")
+	}
+
+	fileName := pdfFileName(raw.URL)
+	if title == "" {
+		title = fileName
+	}
+
+	hash, err := g.assetStore.Put(raw.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store original pdf")
+	}
+	attachment := &models.Asset{
+		Kind:        models.AssetKindAttachment,
+		Hash:        hash,
+		ContentType: pdfContentType,
+		OriginURL:   raw.URL,
+		Size:        int64(len(raw.Body)),
+	}
+
+	content = fmt.Sprintf("[%s](%s)\n\n%s", fileName, models.AssetPath(hash), content)
+
+	// 원본은 attachment 로 보관했으므로 snapshot 으로 한 번 더 보관하지 않는다
+	return &FetchedArticle{
+		Title:        title,
+		Content:      content,
+		Assets:       models.Assets{attachment},
+		CanonicalURL: raw.URL,
+	}, nil
+}
+
+func isPdfContentType(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), pdfContentType)
+}
+
+func pdfFileName(url string) string {
+	u, err := netUrl.Parse(url)
+	if err != nil {
+		return "original.pdf"
+	}
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" {
+		return "original.pdf"
+	}
+	return name
+}
+
+type pdfLine struct {
+	text     string
+	y        float64
+	fontSize float64
+}
+
+type pdfHeading struct {
+	title string
+	depth int
+}
+
+// convertPdfToMarkdown 은 pdf 의 텍스트를 줄 단위로 모은 뒤, 줄 간격으로 문단을 나누고
+// outline 에 있거나 본문보다 큰 글자의 줄을 heading 으로 바꾼다.
+func convertPdfToMarkdown(data []byte) (title, content string, err error) {
+	defer func() {
+		// pdf 라이브러리는 깨진 파일에서 panic 을 낸다
+		if r := recover(); r != nil {
+			title, content, err = "", "", fmt.Errorf("failed to parse pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to open pdf")
+	}
+
+	headings := map[string]int{}
+	for _, heading := range flattenPdfOutline(reader.Outline(), 0) {
+		key := normalizePdfText(heading.title)
+		if _, ok := headings[key]; !ok {
+			headings[key] = heading.depth
+		}
+	}
+
+	var pages [][]pdfLine
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		pages = append(pages, extractPdfLines(page.Content().Text))
+	}
+
+	bodySize := pdfBodyFontSize(pages)
+	title = strings.TrimSpace(reader.Trailer().Key("Info").Key("Title").Text())
+
+	var blocks []string
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+
+	for _, lines := range pages {
+		for i, line := range lines {
+			if depth, ok := headings[normalizePdfText(line.text)]; ok {
+				flush()
+				blocks = append(blocks, strings.Repeat("#", minInt(depth+2, 6))+" "+line.text)
+				continue
+			} else if bodySize > 0 && line.fontSize >= bodySize*1.3 && len(line.text) < 120 {
+				flush()
+				blocks = append(blocks, "## "+line.text)
+				if title == "" {
+					title = line.text
+				}
+				continue
+			}
+
+			if i > 0 && len(paragraph) > 0 {
+				gap := lines[i-1].y - line.y
+				if gap > line.fontSize*1.8 || gap < 0 {
+					flush()
+				}
+			}
+			paragraph = appendPdfLine(paragraph, line.text)
+		}
+		flush()
+	}
+
+	return title, strings.Join(blocks, "\n\n"), nil
+}
+
+func flattenPdfOutline(outline pdf.Outline, depth int) []pdfHeading {
+	var headings []pdfHeading
+	for _, child := range outline.Child {
+		if strings.TrimSpace(child.Title) != "" {
+			headings = append(headings, pdfHeading{title: child.Title, depth: depth})
+		}
+		headings = append(headings, flattenPdfOutline(child, depth+1)...)
+	}
+	return headings
+}
+
+// extractPdfLines 는 글자 단위의 텍스트를 y 좌표 기준으로 줄로 묶는다.
+func extractPdfLines(texts []pdf.Text) []pdfLine {
+	var lines []pdfLine
+	var builder strings.Builder
+	var current *pdfLine
+	var lastEnd float64
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if text := strings.Join(strings.Fields(builder.String()), " "); text != "" {
+			current.text = text
+			lines = append(lines, *current)
+		}
+		builder.Reset()
+		current = nil
+	}
+
+	for _, text := range texts {
+		if current == nil || math.Abs(text.Y-current.y) > math.Max(text.FontSize, 1)*0.5 {
+			flush()
+			current = &pdfLine{y: text.Y, fontSize: text.FontSize}
+		} else if text.X-lastEnd > text.FontSize*0.2 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(text.S)
+		lastEnd = text.X + text.W
+		if text.FontSize > current.fontSize {
+			current.fontSize = text.FontSize
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// pdfBodyFontSize 는 가장 많은 글자가 쓰인 font size 를 본문 크기로 본다.
+func pdfBodyFontSize(pages [][]pdfLine) float64 {
+	counts := map[float64]int{}
+	for _, lines := range pages {
+		for _, line := range lines {
+			counts[math.Round(line.fontSize)] += len(line.text)
+		}
+	}
+
+	sizes := make([]float64, 0, len(counts))
+	for size := range counts {
+		sizes = append(sizes, size)
+	}
+	sort.Float64s(sizes)
+
+	var bodySize float64
+	for _, size := range sizes {
+		if counts[size] > counts[bodySize] {
+			bodySize = size
+		}
+	}
+	return bodySize
+}
+
+func appendPdfLine(paragraph []string, text string) []string {
+	if len(paragraph) == 0 {
+		return append(paragraph, text)
+	}
+
+	// 줄 끝에서 하이픈으로 나뉜 단어를 다시 붙인다
+	last := paragraph[len(paragraph)-1]
+	first, _ := firstRune(text)
+	if strings.HasSuffix(last, "-") && unicode.IsLower(first) {
+		paragraph[len(paragraph)-1] = strings.TrimSuffix(last, "-") + text
+		return paragraph
+	}
+	return append(paragraph, text)
+}
+
+func normalizePdfText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+func firstRune(s string) (rune, bool) {
+	for _, r := range s {
+		return r, true
+	}
+	return 0, false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,111 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractPdf(t *testing.T) {
+	var stored []byte
+	g := &articlePdfFetcher{
+		assetStore: &internal.AssetStoreMock{
+			OnPut: func(data []byte) (string, error) {
+				stored = data
+				return "pdfhash", nil
+			},
+		},
+	}
+
+	data := newTestPdf([]string{
+		"BT /F1 24 Tf 72 720 Td (Introduction) Tj ET",
+		"BT /F1 12 Tf 72 690 Td (Personal archive keeps pdf docu-) Tj 0 -14 Td (ments as markdown.) Tj 0 -40 Td (Second paragraph.) Tj ET",
+		"BT /F1 20 Tf 72 600 Td (Large Heading) Tj ET",
+	})
+	fetched, err := g.Extract(&RawResponse{
+		URL:        "https://example.com/papers/archive.pdf",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       data,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Test Document", fetched.Title)
+	require.Equal(t, "[archive.pdf](/apis/assets/pdfhash)\n\n"+
+		"## Introduction\n\n"+
+		"Personal archive keeps pdf documents as markdown.\n\n"+
+		"Second paragraph.\n\n"+
+		"## Large Heading", fetched.Content)
+	require.Equal(t, data, stored)
+	require.Len(t, fetched.Assets, 1)
+	require.Equal(t, models.AssetKindAttachment, fetched.Assets[0].Kind)
+	// 원본은 attachment 로만 보관한다
+	require.Nil(t, fetched.Raw)
+
+	_, err = g.Extract(&RawResponse{Header: http.Header{}, Body: []byte("<html></html>")})
+	require.Error(t, err)
+}
+
+func TestFetchPdfWithoutExtension(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", pdfContentType)
+		w.Write(newTestPdf([]string{"BT /F1 12 Tf 72 720 Td (Body) Tj ET"}))
+	}))
+	defer server.Close()
+
+	assetStore := &internal.AssetStoreMock{
+		OnPut: func(data []byte) (string, error) { return "pdfhash", nil },
+	}
+	gen := &articleGenerator{
+		fetchers: []ArticleFetcher{
+			&articlePdfFetcher{assetStore: assetStore},
+			&articleMarkdownFetcher{densityExtractor: &articleDensityExtractor{}},
+		},
+	}
+
+	// HEAD 로 미리 확인하지 않고, 내려받은 응답의 Content-Type 으로 pdf 임을 안다
+	require.False(t, gen.fetchers[0].IsFetchable(server.URL+"/download?id=1"))
+	fetched, kind, err := gen.fetch(server.URL + "/download?id=1")
+	require.NoError(t, err)
+	require.Equal(t, models.KindPDF, kind)
+	require.Equal(t, "Test Document", fetched.Title)
+	require.Equal(t, []string{http.MethodGet}, methods)
+}
+
+// newTestPdf 는 Helvetica 폰트와 outline("Introduction") 을 가진 한 페이지짜리 pdf 를 만든다.
+func newTestPdf(contents []string) []byte {
+	stream := strings.Join(contents, "\n")
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 6 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Outlines /First 7 0 R /Last 7 0 R /Count 1 >>",
+		"<< /Title (Introduction) /Parent 6 0 R >>",
+		"<< /Title (Test Document) >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	return buf.Bytes()
+}
@@ -0,0 +1,65 @@
+package generators
+
+import (
+	"bytes"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common/markdown"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type ruleExtracted struct {
+	// 불필요한 요소를 지운 html
+	html   string
+	title  string
+	author string
+	// content selector 로 찾은 본문의 markdown, content selector 가 없거나 일치하는 요소가 없으면 비어있다
+	content string
+}
+
+// extractByRule 은 rule 의 remove selector 에 해당하는 요소를 지우고 title, author, 본문을 찾는다.
+func extractByRule(raw *RawResponse, rule *models.ExtractionRule) (*ruleExtracted, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw.Body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse response body")
+	}
+	markCodeLanguage(doc)
+
+	if rule.RemoveSelector != "" {
+		doc.Find(rule.RemoveSelector).Remove()
+	}
+
+	result := &ruleExtracted{}
+	if rule.TitleSelector != "" {
+		result.title = strings.TrimSpace(doc.Find(rule.TitleSelector).First().Text())
+	}
+	if rule.AuthorSelector != "" {
+		result.author = strings.TrimSpace(doc.Find(rule.AuthorSelector).First().Text())
+	}
+
+	if result.html, err = doc.Html(); err != nil {
+		return nil, errors.Wrap(err, "failed to get html from goquery")
+	}
+
+	if rule.ContentSelector == "" {
+		return result, nil
+	}
+
+	var contentHtml strings.Builder
+	doc.Find(rule.ContentSelector).Each(func(_ int, s *goquery.Selection) {
+		if html, err := goquery.OuterHtml(s); err == nil {
+			contentHtml.WriteString(html)
+		}
+	})
+	if contentHtml.Len() == 0 {
+		return result, nil
+	}
+
+	content, err := markdown.ConvertFromHtml(contentHtml.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert html to markdown")
+	}
+	result.content = strings.TrimSpace(content)
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package generators
+
+import (
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExtractByRule(t *testing.T) {
+	body := `<html>
+<head><title>Page title</title></head>
+<body>
+	<h1 class="doc-title">Rule title</h1>
+	<span class="byline">Jane Doe</span>
+	<div class="doc">
+		<p>Main document text.</p>
+		<div class="feedback">Was this page helpful?</div>
+	</div>
+	<div class="doc"><p>Second part.</p></div>
+</body>
+</html>`
+
+	var queried []string
+	fetcher := &articleMarkdownFetcher{
+		densityExtractor: &articleDensityExtractor{},
+		extractionRuleRepository: &mock.ExtractionRuleRepositoryMock{
+			OnFindByDomains: func(domains []string) (models.ExtractionRules, error) {
+				queried = domains
+				return models.ExtractionRules{
+					{Domain: "example.com", ContentSelector: "body"},
+					{
+						Domain:          "docs.example.com",
+						ContentSelector: ".doc",
+						RemoveSelector:  ".feedback",
+						TitleSelector:   ".doc-title",
+						AuthorSelector:  ".byline",
+					},
+				}, nil
+			},
+		},
+	}
+
+	fetched, err := fetcher.Extract(&RawResponse{
+		URL:  "https://docs.example.com/guide",
+		Body: []byte(body),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs.example.com", "example.com", "com"}, queried)
+	require.Equal(t, ExtractorRule, fetched.Extractor)
+	require.Equal(t, "Rule title", fetched.Title)
+	require.Equal(t, "Jane Doe", fetched.Author)
+	require.Contains(t, fetched.Content, "Main document text.")
+	require.Contains(t, fetched.Content, "Second part.")
+	require.NotContains(t, fetched.Content, "helpful")
+	require.NotContains(t, fetched.Content, "Jane Doe")
+}
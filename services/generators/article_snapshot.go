@@ -0,0 +1,81 @@
+package generators
+
+import (
+	"bytes"
+	"github.com/jaeyo/personal-archive/common/warc"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	SnapshotFormatHTML = "html"
+	SnapshotFormatWARC = "warc"
+)
+
+type articleSnapshotter struct {
+	format     string
+	assetStore internal.AssetStore
+}
+
+// Snapshot 은 원본 응답을 asset store 에 보관한다. warc 형식이면 응답 헤더까지 WARC 레코드로 감싸서 보관한다.
+func (s *articleSnapshotter) Snapshot(raw *RawResponse) (*models.Asset, error) {
+	data := raw.Body
+	contentType := raw.ContentType()
+
+	if s.format == SnapshotFormatWARC {
+		var buf bytes.Buffer
+		if err := warc.WriteResponse(&buf, raw.URL, time.Now(), raw.StatusCode, raw.Header, raw.Body); err != nil {
+			return nil, errors.Wrap(err, "failed to write warc record")
+		}
+		data = buf.Bytes()
+		contentType = warc.ContentType
+	}
+
+	hash, err := s.assetStore.Put(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to store snapshot")
+	}
+
+	return &models.Asset{
+		Kind:        models.AssetKindSnapshot,
+		Hash:        hash,
+		ContentType: contentType,
+		OriginURL:   raw.URL,
+		Size:        int64(len(data)),
+	}, nil
+}
+
+// ReadSnapshot 은 보관된 snapshot 을 원본 응답 형태로 되돌린다.
+func ReadSnapshot(asset *models.Asset, r io.Reader) (*RawResponse, error) {
+	if asset.ContentType == warc.ContentType {
+		record, err := warc.ReadResponse(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read warc record")
+		}
+		return &RawResponse{
+			URL:        record.TargetURI,
+			StatusCode: record.Response.StatusCode,
+			Header:     record.Response.Header,
+			Body:       record.Body,
+		}, nil
+	}
+
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot")
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", asset.ContentType)
+	return &RawResponse{
+		URL:        asset.OriginURL,
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       body,
+	}, nil
+}
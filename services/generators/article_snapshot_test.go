@@ -0,0 +1,53 @@
+package generators
+
+import (
+	"bytes"
+	"github.com/jaeyo/personal-archive/common/warc"
+	"github.com/jaeyo/personal-archive/internal"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	raw := &RawResponse{
+		URL:        "https://example.com/post",
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       []byte("<html><body><p>hello</p></body></html>"),
+	}
+
+	for _, format := range []string{SnapshotFormatHTML, SnapshotFormatWARC} {
+		t.Run(format, func(t *testing.T) {
+			var stored []byte
+			snapshotter := &articleSnapshotter{
+				format: format,
+				assetStore: &internal.AssetStoreMock{
+					OnPut: func(data []byte) (string, error) {
+						stored = data
+						return "hash", nil
+					},
+				},
+			}
+
+			asset, err := snapshotter.Snapshot(raw)
+			require.NoError(t, err)
+			require.Equal(t, models.AssetKindSnapshot, asset.Kind)
+			require.Equal(t, raw.URL, asset.OriginURL)
+			if format == SnapshotFormatWARC {
+				require.Equal(t, warc.ContentType, asset.ContentType)
+			} else {
+				require.Equal(t, "text/html; charset=utf-8", asset.ContentType)
+			}
+
+			restored, err := ReadSnapshot(asset, bytes.NewReader(stored))
+			require.NoError(t, err)
+			require.Equal(t, raw.URL, restored.URL)
+			require.Equal(t, raw.Body, restored.Body)
+			require.Equal(t, "text/html; charset=utf-8", restored.ContentType())
+		})
+	}
+}
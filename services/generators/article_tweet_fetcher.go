@@ -1,32 +1,397 @@
 package generators
 
 import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/models"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"html"
+	"math"
+	netUrl "net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
-const TweetUrlRegex = "https://twitter.com/.*/status/([0-9]+)"
+// twitter.com, x.com 의 `/:user/status/:id` 형태의 url
+var tweetUrlRegex = regexp.MustCompile(`^https?://(?:(?:www|mobile)\.)?(?:twitter|x)\.com/([A-Za-z0-9_]+)/status(?:es)?/([0-9]+)`)
+
+// 스레드로 가져올 최대 tweet 수
+const tweetMaxThreadLength = 50
 
 type articleTweetFetcher struct {
+	syndicationURL string
+	oEmbedURL      string
+}
+
+type tweet struct {
+	id         string
+	name       string
+	screenName string
+	text       string
+	created    *time.Time
+	photos     []string
+	videos     []tweetVideo
+	// 같은 작성자의 tweet 에 단 답글일 때의 원 tweet id
+	parentID string
+	// syndication 응답에 함께 온 원 tweet
+	parent *tweet
+}
+
+type tweetVideo struct {
+	poster string
+	url    string
+}
+
+func init() {
+	RegisterFetcher(func() ArticleFetcher {
+		return &articleTweetFetcher{
+			syndicationURL: common.TweetSyndicationURL(),
+			oEmbedURL:      common.TweetOEmbedURL(),
+		}
+	})
+}
+
+func (g *articleTweetFetcher) Kind() string {
+	return models.KindTweet
 }
 
-func (g *articleTweetFetcher) Fetch(url string) (string, string, error) {
-	tweetID, err := g.extractTweetID(url)
+func (g *articleTweetFetcher) Priority() int {
+	return PrioritySite
+}
+
+func (g *articleTweetFetcher) Fetch(url string) (*FetchedArticle, error) {
+	screenName, tweetID, ok := parseTweetURL(url)
+	if !ok {
+		return nil, fmt.Errorf("not a tweet url: %s", url)
+	}
+
+	thread, err := g.getThread(tweetID)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to extract tweet id")
+		logrus.Warnf("failed to get tweet %s from syndication, fall back to oembed: %s", tweetID, err.Error())
+
+		t, err := g.getOEmbedTweet(tweetURL(screenName, tweetID))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get tweet from oembed")
+		}
+		thread = []*tweet{t}
+	}
+
+	last := thread[len(thread)-1]
+	author := last.name
+	if last.screenName != "" {
+		author = fmt.Sprintf("%s (@%s)", last.name, last.screenName)
+	}
+	metadata := ArticleMetadata{
+		Author:    strings.TrimSpace(author),
+		Published: thread[0].created,
+		SiteName:  "Twitter",
+		Excerpt:   truncateText(thread[0].text, 200),
+	}
+	if len(thread[0].photos) > 0 {
+		metadata.LeadImage = thread[0].photos[0]
 	}
 
-	return url, tweetID, nil
+	return &FetchedArticle{
+		Title:           tweetTitle(thread[0]),
+		Content:         tweetThreadToMarkdown(thread),
+		ArticleMetadata: metadata,
+		// x.com 과 twitter.com 은 같은 tweet 이다
+		CanonicalURL: tweetURL(last.screenName, last.id),
+	}, nil
 }
 
 func (g *articleTweetFetcher) IsFetchable(url string) bool {
-	return regexp.MustCompile(TweetUrlRegex).MatchString(url)
+	_, _, ok := parseTweetURL(url)
+	return ok
+}
+
+// getThread 는 같은 작성자의 답글을 따라 스레드의 처음까지 거슬러 올라가 오래된 순으로 돌려준다.
+func (g *articleTweetFetcher) getThread(tweetID string) ([]*tweet, error) {
+	t, err := g.getSyndicationTweet(tweetID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := []*tweet{t}
+	for len(thread) < tweetMaxThreadLength && thread[0].parentID != "" {
+		parent := thread[0].parent
+		if parent == nil || parent.id != thread[0].parentID {
+			if parent, err = g.getSyndicationTweet(thread[0].parentID); err != nil {
+				// 지워진 tweet 등으로 끊긴 경우 가져온 데까지만 보관한다
+				logrus.Warnf("failed to get parent tweet %s: %s", thread[0].parentID, err.Error())
+				break
+			}
+		}
+		if !strings.EqualFold(parent.screenName, t.screenName) {
+			break
+		}
+		thread = append([]*tweet{parent}, thread...)
+	}
+	return thread, nil
+}
+
+func (g *articleTweetFetcher) getSyndicationTweet(tweetID string) (*tweet, error) {
+	query := netUrl.Values{}
+	query.Set("id", tweetID)
+	query.Set("lang", "en")
+	query.Set("token", syndicationToken(tweetID))
+
+	resp, err := fetch.Get(strings.TrimRight(g.syndicationURL, "/") + "/tweet-result?" + query.Encode())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request tweet")
+	} else if err := fetch.CheckStatus(resp); err != nil {
+		return nil, err
+	}
+
+	if !gjson.ValidBytes(resp.Body) {
+		return nil, errors.New("invalid tweet response")
+	}
+	return parseSyndicationTweet(gjson.ParseBytes(resp.Body))
+}
+
+func parseSyndicationTweet(data gjson.Result) (*tweet, error) {
+	if typename := data.Get("__typename").String(); typename == "TweetTombstone" {
+		return nil, errors.New("tweet is unavailable")
+	}
+
+	t := &tweet{
+		id:         data.Get("id_str").String(),
+		name:       data.Get("user.name").String(),
+		screenName: data.Get("user.screen_name").String(),
+		text:       syndicationTweetText(data),
+	}
+	if t.id == "" {
+		return nil, errors.New("tweet not found")
+	}
+	if created, err := time.Parse(time.RFC3339, data.Get("created_at").String()); err == nil {
+		t.created = &created
+	}
+	if strings.EqualFold(data.Get("in_reply_to_screen_name").String(), t.screenName) {
+		t.parentID = data.Get("in_reply_to_status_id_str").String()
+		if parent := data.Get("parent"); parent.Exists() {
+			t.parent, _ = parseSyndicationTweet(parent)
+		}
+	}
+
+	data.Get("mediaDetails").ForEach(func(_, media gjson.Result) bool {
+		switch media.Get("type").String() {
+		case "photo":
+			t.photos = append(t.photos, media.Get("media_url_https").String())
+		case "video", "animated_gif":
+			t.videos = append(t.videos, tweetVideo{
+				poster: media.Get("media_url_https").String(),
+				url:    bestTweetVideoVariant(media.Get("video_info.variants")),
+			})
+		}
+		return true
+	})
+
+	return t, nil
+}
+
+// syndicationTweetText 는 t.co 로 줄여진 링크를 원래 url 로 되돌리고, 미디어 링크는 지운다.
+func syndicationTweetText(data gjson.Result) string {
+	text := data.Get("text").String()
+
+	data.Get("entities.urls").ForEach(func(_, u gjson.Result) bool {
+		if short, expanded := u.Get("url").String(), u.Get("expanded_url").String(); short != "" && expanded != "" {
+			text = strings.ReplaceAll(text, short, expanded)
+		}
+		return true
+	})
+	data.Get("entities.media").ForEach(func(_, m gjson.Result) bool {
+		if short := m.Get("url").String(); short != "" {
+			text = strings.ReplaceAll(text, short, "")
+		}
+		return true
+	})
+
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
+func bestTweetVideoVariant(variants gjson.Result) string {
+	var best string
+	var bestBitrate int64 = -1
+	variants.ForEach(func(_, variant gjson.Result) bool {
+		contentType := variant.Get("content_type").String()
+		if contentType != "" && contentType != "video/mp4" {
+			return true
+		}
+		if bitrate := variant.Get("bitrate").Int(); bitrate > bestBitrate {
+			best, bestBitrate = variant.Get("url").String(), bitrate
+		}
+		return true
+	})
+	return best
+}
+
+// getOEmbedTweet 은 oEmbed 의 blockquote 에서 작성자와 본문, 작성일을 얻는다. 미디어와 스레드는 얻을 수 없다.
+func (g *articleTweetFetcher) getOEmbedTweet(url string) (*tweet, error) {
+	query := netUrl.Values{}
+	query.Set("url", url)
+	query.Set("omit_script", "true")
+	query.Set("dnt", "true")
+
+	resp, err := fetch.Get(strings.TrimRight(g.oEmbedURL, "/") + "/oembed?" + query.Encode())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request oembed")
+	} else if err := fetch.CheckStatus(resp); err != nil {
+		return nil, err
+	}
+
+	data := gjson.ParseBytes(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(data.Get("html").String()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse oembed html")
+	}
+
+	paragraph := doc.Find("blockquote p").First()
+	paragraph.Find("br").ReplaceWithHtml("\n")
+	paragraph.Find("a").Each(func(_ int, s *goquery.Selection) {
+		// pic.twitter.com 등의 링크 텍스트는 줄여져 있으므로 href 로 바꾼다
+		if href := s.AttrOr("href", ""); strings.HasPrefix(href, "http") && !strings.HasPrefix(s.Text(), "#") && !strings.HasPrefix(s.Text(), "@") {
+			s.ReplaceWithHtml(html.EscapeString(href))
+		}
+	})
+
+	screenName, tweetID, _ := parseTweetURL(url)
+	t := &tweet{
+		id:         tweetID,
+		name:       data.Get("author_name").String(),
+		screenName: screenName,
+		text:       strings.TrimSpace(paragraph.Text()),
+	}
+	if authorURL := data.Get("author_url").String(); authorURL != "" {
+		t.screenName = authorURL[strings.LastIndex(authorURL, "/")+1:]
+	}
+	if created, err := time.Parse("January 2, 2006", strings.TrimSpace(doc.Find("blockquote > a").Last().Text())); err == nil {
+		t.created = &created
+	}
+	if t.text == "" && t.name == "" {
+		return nil, errors.New("empty oembed response")
+	}
+	return t, nil
+}
+
+func tweetThreadToMarkdown(thread []*tweet) string {
+	var parts []string
+	for _, t := range thread {
+		parts = append(parts, tweetToMarkdown(t))
+	}
+	return strings.Join(parts, "\n\n---\n\n") + "\n"
+}
+
+func tweetToMarkdown(t *tweet) string {
+	var content strings.Builder
+
+	header := fmt.Sprintf("**%s**", escapeMarkdownText(t.name))
+	if t.screenName != "" {
+		header += fmt.Sprintf(" [@%s](https://twitter.com/%s)", t.screenName, t.screenName)
+	}
+	if t.created != nil {
+		header += fmt.Sprintf(" · [%s](%s)", t.created.UTC().Format("2006-01-02 15:04"), tweetURL(t.screenName, t.id))
+	}
+	content.WriteString(header)
+	content.WriteString("\n\n")
+
+	if t.text != "" {
+		content.WriteString(plainTextToMarkdown(t.text))
+		content.WriteString("\n")
+	}
+	for _, photo := range t.photos {
+		content.WriteString(fmt.Sprintf("\n![](%s)\n", photo))
+	}
+	for _, video := range t.videos {
+		if video.poster != "" {
+			content.WriteString(fmt.Sprintf("\n![](%s)\n", video.poster))
+		}
+		if video.url != "" {
+			content.WriteString(fmt.Sprintf("\n[Video](%s)\n", video.url))
+		}
+	}
+
+	return strings.TrimSpace(content.String())
+}
+
+func tweetTitle(t *tweet) string {
+	text := truncateText(firstLine(t.text), 80)
+	if text == "" {
+		return tweetURL(t.screenName, t.id)
+	}
+	return fmt.Sprintf("%s: %s", t.name, text)
+}
+
+func parseTweetURL(url string) (screenName, tweetID string, ok bool) {
+	matched := tweetUrlRegex.FindStringSubmatch(url)
+	if len(matched) != 3 {
+		return "", "", false
+	}
+	return matched[1], matched[2], true
+}
+
+func tweetURL(screenName, tweetID string) string {
+	if screenName == "" {
+		screenName = "i"
+	}
+	return fmt.Sprintf("https://twitter.com/%s/status/%s", screenName, tweetID)
+}
+
+// syndicationToken 은 embed 위젯의 `((id / 1e15) * PI).toString(36)` 에서 0 과 . 을 지운 token 이다.
+func syndicationToken(tweetID string) string {
+	id, err := strconv.ParseFloat(tweetID, 64)
+	if err != nil {
+		return ""
+	}
+
+	const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	value := id / 1e15 * math.Pi
+	integer := math.Floor(value)
+	fraction := value - integer
+	delta := math.Max(0.5*(math.Nextafter(value, math.Inf(1))-value), math.SmallestNonzeroFloat64)
+
+	var fractionDigits []int
+	for fraction >= delta {
+		fraction *= 36
+		delta *= 36
+		digit := int(fraction)
+		fractionDigits = append(fractionDigits, digit)
+		fraction -= float64(digit)
+		if (fraction > 0.5 || (fraction == 0.5 && digit&1 == 1)) && fraction+delta > 1 {
+			// 반올림하며 끝낸다
+			for {
+				last := len(fractionDigits) - 1
+				if last < 0 {
+					integer++
+					break
+				}
+				if fractionDigits[last]+1 < 36 {
+					fractionDigits[last]++
+					break
+				}
+				fractionDigits = fractionDigits[:last]
+			}
+			break
+		}
+	}
+
+	token := strconv.FormatInt(int64(integer), 36)
+	for _, digit := range fractionDigits {
+		token += string(digits[digit])
+	}
+	return strings.ReplaceAll(token, "0", "")
 }
 
-func (g *articleTweetFetcher) extractTweetID(url string) (string, error) {
-	extracted := regexp.MustCompile(TweetUrlRegex).FindStringSubmatch(url)
-	if len(extracted) > 2 {
-		return "", errors.New("invalid tweet url")
+func truncateText(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
 	}
-	return extracted[1], nil
+	return strings.TrimSpace(string(runes[:max])) + "…"
 }
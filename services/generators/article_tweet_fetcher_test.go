@@ -0,0 +1,108 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsFetchableTweet(t *testing.T) {
+	gen := &articleTweetFetcher{}
+	require.True(t, gen.IsFetchable("https://twitter.com/golang/status/1234567890"))
+	require.True(t, gen.IsFetchable("https://x.com/golang/status/1234567890?s=20"))
+	require.True(t, gen.IsFetchable("https://mobile.twitter.com/golang/status/1234567890"))
+	require.False(t, gen.IsFetchable("https://twitter.com/golang"))
+	require.False(t, gen.IsFetchable("https://example.com/golang/status/1234567890"))
+}
+
+func TestFetchTweetThread(t *testing.T) {
+	tweets := map[string]string{
+		"1": `{"__typename":"Tweet","id_str":"1","text":"first &amp; foremost https://t.co/abc","created_at":"2021-01-02T03:04:05.000Z",
+			"user":{"name":"Gopher","screen_name":"gopher"},
+			"entities":{"urls":[{"url":"https://t.co/abc","expanded_url":"https://go.dev"}]}}`,
+		"2": `{"__typename":"Tweet","id_str":"2","text":"second https://t.co/pic","created_at":"2021-01-02T03:05:00.000Z",
+			"user":{"name":"Gopher","screen_name":"gopher"},
+			"in_reply_to_screen_name":"gopher","in_reply_to_status_id_str":"1",
+			"entities":{"media":[{"url":"https://t.co/pic"}]},
+			"mediaDetails":[{"type":"photo","media_url_https":"https://pbs.twimg.com/media/a.jpg"},
+				{"type":"video","media_url_https":"https://pbs.twimg.com/media/b.jpg","video_info":{"variants":[
+					{"content_type":"application/x-mpegURL","url":"https://video.twimg.com/b.m3u8"},
+					{"content_type":"video/mp4","bitrate":320000,"url":"https://video.twimg.com/low.mp4"},
+					{"content_type":"video/mp4","bitrate":2176000,"url":"https://video.twimg.com/high.mp4"}]}}]}`,
+		"3": `{"__typename":"Tweet","id_str":"3","text":"third","user":{"name":"Gopher","screen_name":"gopher"},
+			"in_reply_to_screen_name":"gopher","in_reply_to_status_id_str":"2",
+			"parent":{"__typename":"Tweet","id_str":"2","text":"second","user":{"name":"Gopher","screen_name":"gopher"},
+				"in_reply_to_screen_name":"gopher","in_reply_to_status_id_str":"1"}}`,
+	}
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/tweet-result", r.URL.Path)
+		require.NotEmpty(t, r.URL.Query().Get("token"))
+		requested = append(requested, r.URL.Query().Get("id"))
+		body, ok := tweets[r.URL.Query().Get("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	gen := &articleTweetFetcher{syndicationURL: server.URL, oEmbedURL: server.URL}
+	article, err := gen.Fetch("https://x.com/gopher/status/2")
+	require.Nil(t, err)
+
+	require.Equal(t, "Gopher: first & foremost https://go.dev", article.Title)
+	require.Equal(t, "Gopher (@gopher)", article.Author)
+	require.Equal(t, "Twitter", article.SiteName)
+	require.Equal(t, "2021-01-02T03:04:05Z", article.Published.Format("2006-01-02T15:04:05Z07:00"))
+
+	require.Equal(t, "https://twitter.com/gopher/status/2", article.CanonicalURL)
+
+	// 거슬러 올라간 tweet
+	parts := strings.Split(article.Content, "\n---\n")
+	require.Len(t, parts, 2)
+	require.Contains(t, parts[0], "first & foremost https://go.dev")
+	require.Contains(t, parts[0], "[2021-01-02 03:04](https://twitter.com/gopher/status/1)")
+	require.Contains(t, parts[1], "![](https://pbs.twimg.com/media/a.jpg)")
+	require.Contains(t, parts[1], "[Video](https://video.twimg.com/high.mp4)")
+	require.NotContains(t, parts[1], "https://t.co/pic")
+
+	// 응답에 함께 온 원 tweet 은 다시 요청하지 않는다
+	requested = nil
+	article, err = gen.Fetch("https://twitter.com/gopher/status/3")
+	require.Nil(t, err)
+	require.Len(t, strings.Split(article.Content, "\n---\n"), 3)
+	require.Equal(t, []string{"3", "1"}, requested)
+}
+
+func TestFetchTweetFallbackToOEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oembed" {
+			http.NotFound(w, r)
+			return
+		}
+		require.Equal(t, "https://twitter.com/gopher/status/3", r.URL.Query().Get("url"))
+		fmt.Fprint(w, `{"author_name":"Gopher","author_url":"https://twitter.com/gopher",
+			"html":"<blockquote class=\"twitter-tweet\"><p lang=\"en\" dir=\"ltr\">hello<br>world <a href=\"https://t.co/x\">#go</a></p>&mdash; Gopher (@gopher) <a href=\"https://twitter.com/gopher/status/3\">March 4, 2021</a></blockquote>"}`)
+	}))
+	defer server.Close()
+
+	gen := &articleTweetFetcher{syndicationURL: server.URL, oEmbedURL: server.URL}
+	article, err := gen.Fetch("https://twitter.com/gopher/status/3")
+	require.Nil(t, err)
+
+	require.Equal(t, "Gopher: hello", article.Title)
+	require.Contains(t, article.Content, "hello  \nworld #go")
+	require.Equal(t, "2021-03-04", article.Published.Format("2006-01-02"))
+}
+
+func TestSyndicationToken(t *testing.T) {
+	require.Equal(t, "2zqic77uqyk", syndicationToken("1234567890123456789"))
+	require.Equal(t, "3vmjqiybmlu", syndicationToken("1600000000000000000"))
+	require.Equal(t, "6dq1a2xwd93", syndicationToken("20"))
+}
@@ -1,77 +1,461 @@
 package generators
 
 import (
+	"encoding/xml"
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/models"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"html"
 	netUrl "net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
+var youtubeVideoIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// transcript 를 이 간격마다 문단으로 나누고 시작 시각을 붙인다
+const youtubeTranscriptParagraphSeconds = 60
+
 type articleYoutubeFetcher struct {
 }
 
-func (g *articleYoutubeFetcher) Fetch(url string) (string, string, error) {
-	title, err := g.getTitle(url)
-	if err != nil {
-		return "", "", errors.Wrap(err, "failed to get title")
+func init() {
+	RegisterFetcher(func() ArticleFetcher {
+		return &articleYoutubeFetcher{}
+	})
+}
+
+func (g *articleYoutubeFetcher) Kind() string {
+	return models.KindYoutube
+}
+
+func (g *articleYoutubeFetcher) Priority() int {
+	return PrioritySite
+}
+
+func (g *articleYoutubeFetcher) Fetch(url string) (*FetchedArticle, error) {
+	videoID, playlistID, ok := parseYoutubeURL(url)
+	if !ok {
+		return nil, fmt.Errorf("not a youtube url: %s", url)
 	}
 
-	videoID, err := g.getVideoID(url)
+	pageURL := youtubeWatchURL(videoID)
+	if videoID == "" {
+		pageURL = youtubePlaylistURL(playlistID)
+	}
+	raw, err := getRaw(pageURL)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to get video id")
+		return nil, err
 	}
 
-	return title, videoID, nil
+	// 동의 페이지 등으로 redirect 되더라도 원래의 id 로 추출한다
+	raw.URL = pageURL
+	return g.Extract(raw)
 }
 
 func (g *articleYoutubeFetcher) IsFetchable(url string) bool {
-	u, err := netUrl.Parse(url)
-	if err != nil {
-		return false
+	_, _, ok := parseYoutubeURL(url)
+	return ok
+}
+
+func (g *articleYoutubeFetcher) Extract(raw *RawResponse) (*FetchedArticle, error) {
+	videoID, playlistID, ok := parseYoutubeURL(raw.URL)
+	if !ok {
+		return nil, fmt.Errorf("not a youtube url: %s", raw.URL)
 	}
 
-	cond := u.Scheme == "https"
-	cond = cond && u.Host == "www.youtube.com"
-	cond = cond && u.Path == "/watch"
+	if videoID != "" {
+		return g.extractVideo(raw, videoID)
+	}
+	return g.extractPlaylist(raw, playlistID)
+}
 
-	queryParams, err := netUrl.ParseQuery(u.RawQuery)
-	if err != nil {
-		return false
+func (g *articleYoutubeFetcher) extractVideo(raw *RawResponse, videoID string) (*FetchedArticle, error) {
+	playerResponse, ok := findJsonAssignment(string(raw.Body), "ytInitialPlayerResponse")
+	if !ok {
+		return nil, errors.New("player response not found")
+	}
+
+	player := gjson.Parse(playerResponse)
+	if status := player.Get("playabilityStatus.status").String(); status != "" && status != "OK" {
+		logrus.Warnf("youtube video %s is not playable: %s", videoID, status)
+	}
+
+	details := player.Get("videoDetails")
+	microformat := player.Get("microformat.playerMicroformatRenderer")
+
+	title := details.Get("title").String()
+	if title == "" {
+		title = getTitleFromHtmlOrURL(string(raw.Body), raw.URL)
+	}
+	channel := details.Get("author").String()
+	channelURL := microformat.Get("ownerProfileUrl").String()
+	if channelURL == "" && details.Get("channelId").String() != "" {
+		channelURL = "https://www.youtube.com/channel/" + details.Get("channelId").String()
+	}
+	description := details.Get("shortDescription").String()
+
+	var content strings.Builder
+	if channel != "" {
+		if channelURL != "" {
+			content.WriteString(fmt.Sprintf("**Channel**: [%s](%s)  \n", escapeMarkdownText(channel), channelURL))
+		} else {
+			content.WriteString(fmt.Sprintf("**Channel**: %s  \n", escapeMarkdownText(channel)))
+		}
+	}
+	if seconds := details.Get("lengthSeconds").Int(); seconds > 0 {
+		content.WriteString(fmt.Sprintf("**Duration**: %s  \n", formatDuration(seconds)))
+	}
+	if description != "" {
+		content.WriteString("\n## Description\n\n")
+		content.WriteString(plainTextToMarkdown(description))
+		content.WriteString("\n")
+	}
+
+	if transcript, err := g.getTranscript(player, videoID); err != nil {
+		logrus.Warnf("failed to get transcript of youtube video %s: %s", videoID, err.Error())
+	} else if transcript != "" {
+		content.WriteString("\n## Transcript\n\n")
+		content.WriteString(transcript)
+	}
+
+	metadata := ArticleMetadata{
+		Author:    channel,
+		Published: parsePublished(microformat.Get("publishDate").String()),
+		SiteName:  "YouTube",
+		Excerpt:   firstLine(description),
+		LeadImage: details.Get("thumbnail.thumbnails.@reverse.0.url").String(),
 	}
-	_, ok := queryParams["v"]
-	cond = cond && ok
 
-	return cond
+	return &FetchedArticle{
+		Title:           title,
+		Content:         strings.TrimSpace(content.String()),
+		ArticleMetadata: metadata,
+		Raw:             raw,
+	}, nil
 }
 
-func (g *articleYoutubeFetcher) getTitle(url string) (string, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to request url")
+func (g *articleYoutubeFetcher) extractPlaylist(raw *RawResponse, playlistID string) (*FetchedArticle, error) {
+	initialData, ok := findJsonAssignment(string(raw.Body), "ytInitialData")
+	if !ok {
+		return nil, errors.New("initial data not found")
 	}
-	defer resp.Body.Close()
 
-	htmlByte, err := ioutil.ReadAll(resp.Body)
-	html := string(htmlByte)
+	data := gjson.Parse(initialData)
+	header := data.Get("metadata.playlistMetadataRenderer")
+	title := header.Get("title").String()
+	if title == "" {
+		title = getTitleFromHtmlOrURL(string(raw.Body), raw.URL)
+	}
+	description := header.Get("description").String()
 
-	return getTitleFromHtml(html)
+	var content strings.Builder
+	if description != "" {
+		content.WriteString(plainTextToMarkdown(description))
+		content.WriteString("\n\n")
+	}
+	content.WriteString("## Videos\n\n")
+	for i, video := range findYoutubePlaylistVideos(data) {
+		content.WriteString(fmt.Sprintf("%d. [%s](%s)\n", i+1, escapeMarkdownText(video.title), youtubeWatchURL(video.videoID)))
+	}
+
+	return &FetchedArticle{
+		Title:   title,
+		Content: strings.TrimSpace(content.String()),
+		ArticleMetadata: ArticleMetadata{
+			SiteName: "YouTube",
+			Excerpt:  firstLine(description),
+		},
+		Raw: raw,
+	}, nil
 }
 
-func (g *articleYoutubeFetcher) getVideoID(url string) (string, error) {
-	u, err := netUrl.Parse(url)
+// getTranscript 는 자막을 시간 순서의 markdown 문단으로 만든다. 사람이 만든 자막을 자동 생성 자막보다 우선한다.
+func (g *articleYoutubeFetcher) getTranscript(player gjson.Result, videoID string) (string, error) {
+	tracks := player.Get("captions.playerCaptionsTracklistRenderer.captionTracks").Array()
+	if len(tracks) == 0 {
+		return "", nil
+	}
+
+	track := tracks[0]
+	for _, t := range tracks {
+		if t.Get("kind").String() != "asr" {
+			track = t
+			break
+		}
+	}
+
+	baseURL := track.Get("baseUrl").String()
+	if baseURL == "" {
+		return "", errors.New("caption url not found")
+	}
+
+	resp, err := fetch.Get(baseURL)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse url")
+		return "", errors.Wrap(err, "failed to request caption")
+	} else if err := fetch.CheckStatus(resp); err != nil {
+		return "", err
 	}
 
-	queryParams, err := netUrl.ParseQuery(u.RawQuery)
+	cues, err := parseYoutubeCaption(resp.Body)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse raw query")
+		return "", errors.Wrap(err, "failed to parse caption")
 	}
+	return youtubeTranscriptToMarkdown(cues, videoID), nil
+}
 
-	videoID, ok := queryParams["v"]
-	if !ok {
-		return "", errors.New("video id not exists")
+type youtubeCaptionCue struct {
+	start float64
+	text  string
+}
+
+// parseYoutubeCaption 은 timedtext 의 두 가지 형식 `<transcript><text start="초">` 과 `<timedtext><body><p t="밀리초">` 를 읽는다.
+func parseYoutubeCaption(data []byte) ([]youtubeCaptionCue, error) {
+	var caption struct {
+		Texts []struct {
+			Start float64 `xml:"start,attr"`
+			Text  string  `xml:",chardata"`
+		} `xml:"text"`
+		Paragraphs []struct {
+			T        int64  `xml:"t,attr"`
+			Text     string `xml:",chardata"`
+			Segments []struct {
+				Text string `xml:",chardata"`
+			} `xml:"s"`
+		} `xml:"body>p"`
+	}
+	if err := xml.Unmarshal(data, &caption); err != nil {
+		return nil, err
+	}
+
+	var cues []youtubeCaptionCue
+	for _, text := range caption.Texts {
+		cues = append(cues, youtubeCaptionCue{start: text.Start, text: text.Text})
 	}
+	for _, p := range caption.Paragraphs {
+		text := p.Text
+		for _, segment := range p.Segments {
+			text += segment.Text
+		}
+		cues = append(cues, youtubeCaptionCue{start: float64(p.T) / 1000, text: text})
+	}
+
+	for i := range cues {
+		// 자막 내용이 한 번 더 escape 되어 있는 경우가 있다 (e.g. `&amp;#39;`)
+		cues[i].text = strings.Join(strings.Fields(html.UnescapeString(cues[i].text)), " ")
+	}
+	return cues, nil
+}
+
+func youtubeTranscriptToMarkdown(cues []youtubeCaptionCue, videoID string) string {
+	var paragraphs []string
+	var current []string
+	var paragraphStart float64
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		seconds := int64(paragraphStart)
+		timestamp := fmt.Sprintf("[%s](%s&t=%ds)", formatDuration(seconds), youtubeWatchURL(videoID), seconds)
+		paragraphs = append(paragraphs, timestamp+" "+escapeMarkdownText(strings.Join(current, " ")))
+		current = nil
+	}
+
+	for _, cue := range cues {
+		if cue.text == "" {
+			continue
+		}
+		if len(current) > 0 && cue.start-paragraphStart >= youtubeTranscriptParagraphSeconds {
+			flush()
+		}
+		if len(current) == 0 {
+			paragraphStart = cue.start
+		}
+		current = append(current, cue.text)
+	}
+	flush()
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+type youtubePlaylistVideo struct {
+	videoID string
+	title   string
+}
+
+// findYoutubePlaylistVideos 는 ytInitialData 를 순서대로 훑어 재생목록의 영상들을 찾는다.
+func findYoutubePlaylistVideos(data gjson.Result) []youtubePlaylistVideo {
+	var videos []youtubePlaylistVideo
+	if !data.IsObject() && !data.IsArray() {
+		return videos
+	}
+
+	if renderer := data.Get("playlistVideoRenderer"); renderer.Exists() {
+		title := renderer.Get("title.runs.0.text").String()
+		if title == "" {
+			title = renderer.Get("title.simpleText").String()
+		}
+		return append(videos, youtubePlaylistVideo{
+			videoID: renderer.Get("videoId").String(),
+			title:   title,
+		})
+	}
+
+	data.ForEach(func(_, value gjson.Result) bool {
+		videos = append(videos, findYoutubePlaylistVideos(value)...)
+		return true
+	})
+	return videos
+}
+
+// parseYoutubeURL 은 watch, youtu.be, shorts, embed, live, playlist 형태의 url 에서 영상 혹은 재생목록 id 를 찾는다.
+func parseYoutubeURL(url string) (videoID, playlistID string, ok bool) {
+	u, err := netUrl.Parse(url)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	query := u.Query()
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch host {
+	case "youtu.be":
+		videoID = segments[0]
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		switch {
+		case u.Path == "/watch":
+			videoID = query.Get("v")
+		case len(segments) == 2 && (segments[0] == "shorts" || segments[0] == "embed" || segments[0] == "live" || segments[0] == "v"):
+			videoID = segments[1]
+		case u.Path == "/playlist":
+			playlistID = query.Get("list")
+		}
+	default:
+		return "", "", false
+	}
+
+	if videoID != "" {
+		if !youtubeVideoIDRegex.MatchString(videoID) {
+			return "", "", false
+		}
+		return videoID, "", true
+	}
+	if playlistID != "" {
+		return "", playlistID, true
+	}
+	return "", "", false
+}
+
+func youtubeWatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
+
+func youtubePlaylistURL(playlistID string) string {
+	return "https://www.youtube.com/playlist?list=" + netUrl.QueryEscape(playlistID)
+}
+
+// findJsonAssignment 는 html 의 스크립트에서 `name = {...};` 형태로 할당된 json 객체를 찾는다.
+func findJsonAssignment(html, name string) (string, bool) {
+	idx := strings.Index(html, name)
+	for idx >= 0 {
+		rest := html[idx+len(name):]
+		trimmed := strings.TrimLeft(rest, " \t\n\r")
+		if strings.HasPrefix(trimmed, "=") {
+			trimmed = strings.TrimLeft(trimmed[1:], " \t\n\r")
+			if object, ok := cutJsonObject(trimmed); ok {
+				return object, true
+			}
+		}
 
-	return videoID[0], nil
+		next := strings.Index(rest, name)
+		if next < 0 {
+			break
+		}
+		idx += len(name) + next
+	}
+	return "", false
+}
+
+// cutJsonObject 는 s 의 맨 앞에 있는 json 객체를 괄호 짝을 맞춰 잘라낸다.
+func cutJsonObject(s string) (string, bool) {
+	if !strings.HasPrefix(s, "{") {
+		return "", false
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[:i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+func formatDuration(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	h, m, s := int64(d.Hours()), int64(d.Minutes())%60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+var (
+	markdownSpecialCharRegex = regexp.MustCompile("([\\\\`*\\[\\]<>])")
+	markdownLineStartRegex   = regexp.MustCompile(`(?m)^(\s*)([#>+-]|\d+\.)(\s)`)
+)
+
+// escapeMarkdownText 는 일반 텍스트가 markdown 문법으로 해석되지 않도록 한다. url 이 깨지지 않도록 `_` 는 그대로 둔다.
+func escapeMarkdownText(text string) string {
+	text = markdownSpecialCharRegex.ReplaceAllString(text, `\$1`)
+	return markdownLineStartRegex.ReplaceAllString(text, `$1\$2$3`)
+}
+
+// plainTextToMarkdown 은 줄바꿈이 그대로 보이도록 일반 텍스트를 markdown 으로 옮긴다.
+func plainTextToMarkdown(text string) string {
+	var paragraphs []string
+	for _, paragraph := range regexp.MustCompile(`\n\s*\n`).Split(strings.TrimSpace(text), -1) {
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = escapeMarkdownText(strings.TrimSpace(line))
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, "  \n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.Index(text, "\n"); idx >= 0 {
+		return strings.TrimSpace(text[:idx])
+	}
+	return text
 }
@@ -1,7 +1,10 @@
 package generators
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -10,3 +13,81 @@ func TestIsFetchableYoutube(t *testing.T) {
 	require.True(t, g.IsFetchable("https://www.youtube.com/watch?v=6bCiSCkxI90&ab_channel=%EC%A4%80%ED%94%8C%EB%A6%AC"))
 	require.False(t, g.IsFetchable("https://www.youtube.com/watch?vv=6bCiSCkxI90&ab_channel=%EC%A4%80%ED%94%8C%EB%A6%AC"))
 }
+
+func TestParseYoutubeURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		videoID    string
+		playlistID string
+		ok         bool
+	}{
+		{url: "https://www.youtube.com/watch?v=6bCiSCkxI90&list=PL123", videoID: "6bCiSCkxI90", ok: true},
+		{url: "https://m.youtube.com/watch?v=6bCiSCkxI90", videoID: "6bCiSCkxI90", ok: true},
+		{url: "https://youtu.be/6bCiSCkxI90?t=10", videoID: "6bCiSCkxI90", ok: true},
+		{url: "https://www.youtube.com/shorts/6bCiSCkxI90", videoID: "6bCiSCkxI90", ok: true},
+		{url: "https://www.youtube.com/embed/6bCiSCkxI90", videoID: "6bCiSCkxI90", ok: true},
+		{url: "https://www.youtube-nocookie.com/embed/6bCiSCkxI90", videoID: "6bCiSCkxI90", ok: true},
+		{url: "https://www.youtube.com/playlist?list=PLabc_DEF-1", playlistID: "PLabc_DEF-1", ok: true},
+		{url: "https://www.youtube.com/channel/UC123", ok: false},
+		{url: "https://youtu.be/short", ok: false},
+		{url: "https://example.com/watch?v=6bCiSCkxI90", ok: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			videoID, playlistID, ok := parseYoutubeURL(tc.url)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.videoID, videoID)
+			require.Equal(t, tc.playlistID, playlistID)
+		})
+	}
+}
+
+func TestExtractYoutubeVideo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8" ?><transcript>` +
+			`<text start="0.5" dur="2">Hello &amp;amp; welcome</text>` +
+			`<text start="30" dur="2">to the show</text>` +
+			`<text start="75" dur="2">second part</text>` +
+			`</transcript>`))
+	}))
+	defer server.Close()
+
+	body := fmt.Sprintf(`<html><script>var ytInitialPlayerResponse = {"videoDetails": {"videoId": "6bCiSCkxI90", "title": "Video {title}",
+		"lengthSeconds": "3725", "author": "Some Channel", "channelId": "UC123", "shortDescription": "Line one\nLine two"},
+		"captions": {"playerCaptionsTracklistRenderer": {"captionTracks": [
+			{"baseUrl": "%s/asr", "kind": "asr"}, {"baseUrl": "%s/manual"}]}},
+		"microformat": {"playerMicroformatRenderer": {"publishDate": "2021-01-02"}}};var meta = {};</script></html>`, server.URL, server.URL)
+
+	fetched, err := (&articleYoutubeFetcher{}).Extract(&RawResponse{
+		URL:  "https://www.youtube.com/watch?v=6bCiSCkxI90",
+		Body: []byte(body),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Video {title}", fetched.Title)
+	require.Equal(t, "Some Channel", fetched.Author)
+	require.NotNil(t, fetched.Published)
+	require.Equal(t, "**Channel**: [Some Channel](https://www.youtube.com/channel/UC123)  \n"+
+		"**Duration**: 1:02:05  \n\n"+
+		"## Description\n\n"+
+		"Line one  \nLine two\n\n"+
+		"## Transcript\n\n"+
+		"[0:00](https://www.youtube.com/watch?v=6bCiSCkxI90&t=0s) Hello & welcome to the show\n\n"+
+		"[1:15](https://www.youtube.com/watch?v=6bCiSCkxI90&t=75s) second part", fetched.Content)
+}
+
+func TestExtractYoutubePlaylist(t *testing.T) {
+	body := `<html><script>var ytInitialData = {"metadata": {"playlistMetadataRenderer": {"title": "My list", "description": ""}},
+		"contents": {"b": [{"playlistVideoRenderer": {"videoId": "bbbbbbbbbbb", "title": {"runs": [{"text": "Second"}]}}}],
+			"a": [{"playlistVideoRenderer": {"videoId": "aaaaaaaaaaa", "title": {"runs": [{"text": "Third"}]}}}]}};</script></html>`
+
+	fetched, err := (&articleYoutubeFetcher{}).Extract(&RawResponse{
+		URL:  "https://www.youtube.com/playlist?list=PL123",
+		Body: []byte(body),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "My list", fetched.Title)
+	require.Equal(t, "## Videos\n\n"+
+		"1. [Second](https://www.youtube.com/watch?v=bbbbbbbbbbb)\n"+
+		"2. [Third](https://www.youtube.com/watch?v=aaaaaaaaaaa)", fetched.Content)
+}
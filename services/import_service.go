@@ -0,0 +1,421 @@
+package services
+
+import (
+	"fmt"
+	"github.com/jaeyo/personal-archive/common/canonical"
+	"github.com/jaeyo/personal-archive/common/imports"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	netUrl "net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+const importMaxItems = 5000
+
+// ImportResult.Outcome 의 값들
+const (
+	ImportQueued        = "queued"
+	ImportAlreadyQueued = "already-queued"
+	// 내려받지 않고 article 을 만들었다
+	ImportCreated = "created"
+	// 같은 문서의 article 이 이미 있다
+	ImportExists = "exists"
+	// 이미 있는 노트에 새로운 paragraph 를 더했다
+	ImportUpdated = "updated"
+	// 같은 파일 안에서 앞서 나온 url 과 같은 문서다
+	ImportDuplicate = "duplicate"
+	ImportInvalid   = "invalid"
+	ImportFailed    = "failed"
+)
+
+// ImportReport 는 가져오기 결과로, Results 는 항목 순서대로다.
+type ImportReport struct {
+	Total   int             `json:"total"`
+	Queued  int             `json:"queued"`
+	Created int             `json:"created"`
+	Updated int             `json:"updated"`
+	Skipped int             `json:"skipped"`
+	Failed  int             `json:"failed"`
+	Results []*ImportResult `json:"results"`
+}
+
+// ImportResult 는 항목 하나의 결과로, 노트를 가져올 때는 URL 대신 Path 를 채운다.
+type ImportResult struct {
+	URL       string `json:"url,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Outcome   string `json:"outcome"`
+	JobID     *int64 `json:"jobId,omitempty"`
+	ArticleID *int64 `json:"articleId,omitempty"`
+	NoteID    *int64 `json:"noteId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (r *ImportReport) add(result *ImportResult) {
+	r.Total++
+	switch result.Outcome {
+	case ImportQueued:
+		r.Queued++
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportInvalid, ImportFailed:
+		r.Failed++
+	default:
+		r.Skipped++
+	}
+	r.Results = append(r.Results, result)
+}
+
+type ImportService interface {
+	ImportURLs(items []*imports.Item, tags []string, fetch bool) (*ImportReport, error)
+	ImportNotes(notes []*imports.Note, tags []string) (*ImportReport, error)
+	ImportBooks(books []*imports.Book, tags []string, linkArticle bool) (*ImportReport, error)
+}
+
+type importService struct {
+	articleRepository repositories.ArticleRepository
+	noteRepository    repositories.NoteRepository
+	articleService    ArticleService
+	ingestionService  IngestionService
+}
+
+var GetImportService = func() func() ImportService {
+	var once sync.Once
+	var instance ImportService
+	return func() ImportService {
+		once.Do(func() {
+			instance = &importService{
+				articleRepository: repositories.GetArticleRepository(),
+				noteRepository:    repositories.GetNoteRepository(),
+				articleService:    GetArticleService(),
+				ingestionService:  GetIngestionService(),
+			}
+		})
+		return instance
+	}
+}()
+
+// ImportURLs 는 items 의 url 들을 ingestion queue 에 넣고, fetch 가 아니거나 본문이 있는 항목은 바로 article 로 만든다.
+func (s *importService) ImportURLs(items []*imports.Item, tags []string, fetch bool) (*ImportReport, error) {
+	if len(items) > importMaxItems {
+		return nil, fmt.Errorf("too many items: %d > %d", len(items), importMaxItems)
+	}
+
+	report := &ImportReport{Results: []*ImportResult{}}
+	seen := map[string]bool{}
+	for _, item := range items {
+		result, err := s.importURL(item, tags, fetch, seen)
+		if err != nil {
+			return nil, err
+		}
+		report.add(result)
+	}
+	return report, nil
+}
+
+// importURL 은 항목 하나를 가져오고, 항목의 문제는 에러 대신 result 에 남긴다.
+func (s *importService) importURL(item *imports.Item, tags []string, fetch bool, seen map[string]bool) (*ImportResult, error) {
+	result := &ImportResult{URL: item.URL}
+
+	canonicalURL, err := validateImportURL(item.URL)
+	if err != nil {
+		result.Outcome, result.Error = ImportInvalid, err.Error()
+		return result, nil
+	}
+
+	itemTags := mergeImportTags(tags, item.Tags)
+	if err := models.ValidateTags(itemTags); err != nil {
+		result.Outcome, result.Error = ImportInvalid, err.Error()
+		return result, nil
+	}
+
+	if seen[canonicalURL] {
+		result.Outcome = ImportDuplicate
+		return result, nil
+	}
+	seen[canonicalURL] = true
+
+	job := models.NewIngestionJob(item.URL, itemTags, models.IngestionSourceImport)
+	job.Title = item.Title
+	job.ArticleCreated = item.Created
+	job.Favorite = item.Favorite
+	job.Archived = item.Archived
+
+	if _, err := s.articleRepository.GetByCanonicalURL(canonicalURL); err == nil {
+		existing, err := s.articleService.CreateByJob(job)
+		if err != nil {
+			result.Outcome, result.Error = ImportFailed, err.Error()
+			return result, nil
+		}
+		result.Outcome, result.ArticleID = ImportExists, &existing.ID
+		return result, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get article by canonical url")
+	}
+
+	// 본문을 함께 받았으면 내려받을 필요가 없다
+	if !fetch || item.Content != "" {
+		article, err := s.articleService.CreateStub(job, item.Content)
+		if err != nil {
+			result.Outcome, result.Error = ImportFailed, err.Error()
+			return result, nil
+		}
+		result.Outcome, result.ArticleID = ImportCreated, &article.ID
+		return result, nil
+	}
+
+	queued, err := s.ingestionService.EnqueueJob(job)
+	if err != nil {
+		result.Outcome, result.Error = ImportFailed, err.Error()
+		return result, nil
+	}
+
+	result.JobID = &queued.ID
+	if queued == job {
+		result.Outcome = ImportQueued
+	} else {
+		result.Outcome = ImportAlreadyQueued
+	}
+	return result, nil
+}
+
+// ImportNotes 는 notes 를 노트로 만들며, 이미 있는 제목의 노트는 건너뛴다.
+func (s *importService) ImportNotes(notes []*imports.Note, tags []string) (*ImportReport, error) {
+	if len(notes) > importMaxItems {
+		return nil, fmt.Errorf("too many notes: %d > %d", len(notes), importMaxItems)
+	}
+	return s.importNotes(notes, tags, false)
+}
+
+// importNotes 는 notes 를 노트로 만들고, appendExisting 이면 이미 있는 노트에 없던 paragraph 를 더한다.
+func (s *importService) importNotes(notes []*imports.Note, tags []string, appendExisting bool) (*ImportReport, error) {
+	report := &ImportReport{Results: []*ImportResult{}}
+	titles := map[string]bool{}
+	articleIDs := map[string]*int64{}
+	for _, note := range notes {
+		result, err := s.importNote(note, tags, titles, articleIDs, appendExisting)
+		if err != nil {
+			return nil, err
+		}
+		report.add(result)
+	}
+	return report, nil
+}
+
+// importNote 는 노트 하나를 가져오고, articleIDs 에 url 별로 찾은 article id 를 모아둔다.
+func (s *importService) importNote(note *imports.Note, tags []string, titles map[string]bool, articleIDs map[string]*int64, appendExisting bool) (*ImportResult, error) {
+	result := &ImportResult{Path: note.Path}
+
+	// vault 안의 다른 폴더에 같은 이름의 파일이 있으면 경로를 제목으로 쓴다
+	title := note.Title
+	if titles[title] {
+		title = strings.TrimSuffix(note.Path, path.Ext(note.Path))
+	}
+	if title == "" || len(title) > 256 {
+		result.Outcome, result.Error = ImportInvalid, "invalid title"
+		return result, nil
+	} else if titles[title] {
+		result.Outcome = ImportDuplicate
+		return result, nil
+	}
+	titles[title] = true
+
+	if exist, err := s.noteRepository.ExistByTitle(title); err != nil {
+		return nil, errors.Wrap(err, "failed to check exist by title")
+	} else if exist && appendExisting {
+		return s.appendNote(result, title, note, articleIDs)
+	} else if exist {
+		result.Outcome = ImportExists
+		return result, nil
+	}
+
+	paragraphs := models.Paragraphs{}
+	for i, p := range note.Paragraphs {
+		paragraph, err := s.newParagraph(i, p, articleIDs)
+		if err != nil {
+			return nil, err
+		}
+		paragraphs = append(paragraphs, paragraph)
+	}
+	if len(paragraphs) == 0 {
+		paragraphs = append(paragraphs, &models.Paragraph{Seq: 0})
+	}
+
+	// 노트 전체가 참고한 url 은 첫 paragraph 에 둔다
+	first := paragraphs[0]
+	for _, url := range note.URLs {
+		articleID, err := s.articleIDByURL(url, articleIDs)
+		if err != nil {
+			return nil, err
+		}
+		if articleID == nil {
+			if !first.ReferenceWebs.ContainURL(url) {
+				first.ReferenceWebs = append(first.ReferenceWebs, &models.ReferenceWeb{URL: url})
+			}
+		} else if !first.ReferenceArticles.ContainArticleID(*articleID) {
+			first.ReferenceArticles = append(first.ReferenceArticles, &models.ReferenceArticle{ArticleID: *articleID})
+		}
+	}
+
+	n := &models.Note{
+		Title:      title,
+		Tags:       mergeImportTags(tags, note.Tags),
+		Paragraphs: paragraphs,
+	}
+	if note.Created != nil {
+		n.Created = *note.Created
+	}
+	if err := s.noteRepository.Save(n); err != nil {
+		result.Outcome, result.Error = ImportFailed, err.Error()
+		return result, nil
+	}
+	result.Outcome, result.NoteID = ImportCreated, &n.ID
+	return result, nil
+}
+
+// appendNote 는 title 의 노트에 내용과 위치가 같은 paragraph 가 없는 것만 더한다.
+func (s *importService) appendNote(result *ImportResult, title string, note *imports.Note, articleIDs map[string]*int64) (*ImportResult, error) {
+	existing, err := s.noteRepository.GetByTitle(title)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get note by title")
+	}
+	result.NoteID = &existing.ID
+
+	keys := map[string]bool{}
+	for _, paragraph := range existing.Paragraphs {
+		keys[imports.HighlightKey(paragraph.Content)] = true
+	}
+
+	appended := false
+	for _, p := range note.Paragraphs {
+		key := imports.HighlightKey(p.Content)
+		if keys[key] {
+			continue
+		}
+		keys[key] = true
+
+		paragraph, err := s.newParagraph(existing.Paragraphs.MaxSeq()+1, p, articleIDs)
+		if err != nil {
+			return nil, err
+		}
+		existing.Paragraphs = append(existing.Paragraphs, paragraph)
+		appended = true
+	}
+	if !appended {
+		result.Outcome = ImportExists
+		return result, nil
+	}
+
+	if err := s.noteRepository.Save(existing); err != nil {
+		result.Outcome, result.Error = ImportFailed, err.Error()
+		return result, nil
+	}
+	result.Outcome = ImportUpdated
+	return result, nil
+}
+
+// newParagraph 는 p 로 seq 번째 paragraph 를 만들고, 본문의 저장된 article url 을 reference 로 남긴다.
+func (s *importService) newParagraph(seq int, p *imports.NoteParagraph, articleIDs map[string]*int64) (*models.Paragraph, error) {
+	paragraph := &models.Paragraph{Seq: seq, Content: p.Content}
+	if p.Created != nil {
+		paragraph.Created = *p.Created
+	}
+	for _, link := range p.Links {
+		articleID, err := s.articleIDByURL(link, articleIDs)
+		if err != nil {
+			return nil, err
+		}
+		if articleID != nil && !paragraph.ReferenceArticles.ContainArticleID(*articleID) {
+			paragraph.ReferenceArticles = append(paragraph.ReferenceArticles, &models.ReferenceArticle{ArticleID: *articleID})
+		}
+	}
+	return paragraph, nil
+}
+
+// ImportBooks 는 Kindle 의 책마다 highlight 를 paragraph 로 가진 노트를 만들고, linkArticle 이면 책의 article 을 참조하게 한다.
+func (s *importService) ImportBooks(books []*imports.Book, tags []string, linkArticle bool) (*ImportReport, error) {
+	if len(books) > importMaxItems {
+		return nil, fmt.Errorf("too many books: %d > %d", len(books), importMaxItems)
+	}
+
+	notes := make([]*imports.Note, 0, len(books))
+	for _, book := range books {
+		note := book.ToNote()
+		if linkArticle {
+			job := models.NewIngestionJob(bookURL(book), mergeImportTags(tags, []string{"kindle"}), models.IngestionSourceImport)
+			job.Title = book.Title
+			article, err := s.articleService.CreateStub(job, "")
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create article for book %s", book.Title)
+			}
+			for _, paragraph := range note.Paragraphs {
+				paragraph.Links = append(paragraph.Links, article.URL)
+			}
+		}
+		notes = append(notes, note)
+	}
+	return s.importNotes(notes, tags, true)
+}
+
+// bookURL 은 같은 책이면 같은 article 을 쓰도록 제목과 저자로 만든 url 이다.
+func bookURL(book *imports.Book) string {
+	id := book.Title
+	if book.Author != "" {
+		id += " (" + book.Author + ")"
+	}
+	return "kindle://book/" + netUrl.PathEscape(id)
+}
+
+// articleIDByURL 은 url 과 같은 문서의 article id 를 찾는다. 없으면 nil 이다.
+func (s *importService) articleIDByURL(url string, articleIDs map[string]*int64) (*int64, error) {
+	canonicalURL, err := canonical.Normalize(url)
+	if err != nil {
+		return nil, nil
+	}
+	if articleID, ok := articleIDs[canonicalURL]; ok {
+		return articleID, nil
+	}
+
+	var articleID *int64
+	if article, err := s.articleRepository.GetByCanonicalURL(canonicalURL); err == nil {
+		articleID = &article.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get article by canonical url")
+	}
+	articleIDs[canonicalURL] = articleID
+	return articleID, nil
+}
+
+// validateImportURL 은 가져올 수 있는 http(s) url 인지 확인하고 중복 확인에 쓸 canonical url 을 돌려준다.
+func validateImportURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", fmt.Errorf("empty url")
+	} else if len(rawURL) > 1024 {
+		return "", fmt.Errorf("url too long")
+	}
+
+	u, err := netUrl.Parse(rawURL)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid url")
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	return canonical.Normalize(rawURL)
+}
+
+func mergeImportTags(tags, itemTags []string) []string {
+	merged := []string{}
+	seen := map[string]bool{}
+	for _, tag := range append(append([]string{}, tags...), itemTags...) {
+		if !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
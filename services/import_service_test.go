@@ -0,0 +1,294 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/common/canonical"
+	"github.com/jaeyo/personal-archive/common/imports"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestImportURLs(t *testing.T) {
+	var saved []*models.IngestionJob
+	existing := &models.Article{ID: 3}
+	articleRepository := &mock.ArticleRepositoryMock{
+		OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) {
+			if canonicalURL == "https://example.com/exists" {
+				return existing, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+		OnSave: func(article *models.Article) error { return nil },
+	}
+	svc := &importService{
+		articleRepository: articleRepository,
+		articleService:    &articleService{articleRepository: articleRepository},
+		ingestionService: &ingestionService{
+			ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+				OnGetActiveByURL: func(url string) (*models.IngestionJob, error) {
+					if url == "https://example.com/queued" {
+						return &models.IngestionJob{ID: 9, URL: url, State: models.IngestionJobQueued}, nil
+					}
+					return nil, gorm.ErrRecordNotFound
+				},
+				OnUpdateQueued: func(job *models.IngestionJob) (bool, error) { return true, nil },
+				OnSave: func(job *models.IngestionJob) error {
+					job.ID = int64(len(saved) + 1)
+					saved = append(saved, job)
+					return nil
+				},
+			},
+			wakeup: make(chan struct{}, 1),
+		},
+	}
+
+	report, err := svc.ImportURLs([]*imports.Item{
+		{URL: "https://example.com/a", Title: "A", Tags: []string{"go"}},
+		{URL: "https://example.com/a?utm_source=x"},
+		{URL: "https://example.com/exists", Tags: []string{"web"}, Favorite: true},
+		{URL: "https://example.com/queued"},
+		{URL: "ftp://example.com/file"},
+		{URL: "https://example.com/b", Tags: []string{"all"}},
+	}, []string{"imported"}, true)
+	require.NoError(t, err)
+
+	var outcomes []string
+	for _, result := range report.Results {
+		outcomes = append(outcomes, result.Outcome)
+	}
+	require.Equal(t, []string{ImportQueued, ImportDuplicate, ImportExists, ImportAlreadyQueued, ImportInvalid, ImportInvalid}, outcomes)
+	require.Equal(t, 6, report.Total)
+	require.Equal(t, 1, report.Queued)
+	require.Equal(t, 3, report.Skipped)
+	require.Equal(t, 2, report.Failed)
+
+	// 이미 있는 문서에는 tag 와 즐겨찾기를 더한다
+	require.True(t, existing.Tags.ContainTag("imported"))
+	require.True(t, existing.Tags.ContainTag("web"))
+	require.True(t, existing.Favorite)
+
+	require.Len(t, saved, 1)
+	require.Equal(t, "A", saved[0].Title)
+	require.Equal(t, models.StringList{"imported", "go"}, saved[0].Tags)
+	require.Equal(t, models.IngestionSourceImport, saved[0].Source)
+}
+
+func TestImportURLsWithoutFetch(t *testing.T) {
+	var saved *models.Article
+	articleRepository := &mock.ArticleRepositoryMock{
+		OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+		OnSave: func(article *models.Article) error {
+			article.ID = 5
+			saved = article
+			return nil
+		},
+	}
+	svc := &importService{
+		articleRepository: articleRepository,
+		articleService: &articleService{
+			articleRepository: articleRepository,
+			articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+				OnSave: func(revision *models.ArticleRevision) error { return nil },
+			},
+			articleGenerator: &generators.ArticleGeneratorMock{
+				OnNewStub: func(url, title, content string, tags []string) (*models.Article, error) {
+					article := models.NewArticle(models.KindMarkdown, url, content, title, tags)
+					article.CanonicalURL = &url
+					return article, nil
+				},
+			},
+		},
+	}
+
+	created := time.Unix(1600000000, 0)
+	report, err := svc.ImportURLs([]*imports.Item{
+		{URL: "https://example.com/a", Title: "A", Tags: []string{"go"}, Created: &created},
+	}, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, ImportCreated, report.Results[0].Outcome)
+	require.Equal(t, int64(5), *report.Results[0].ArticleID)
+	require.Equal(t, "A", saved.Title)
+	require.Empty(t, saved.Content)
+	require.Equal(t, created, saved.Created)
+}
+
+func TestImportURLsWithContent(t *testing.T) {
+	var saved *models.Article
+	articleRepository := &mock.ArticleRepositoryMock{
+		OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+		OnSave: func(article *models.Article) error {
+			saved = article
+			return nil
+		},
+	}
+	svc := &importService{
+		articleRepository: articleRepository,
+		articleService: &articleService{
+			articleRepository: articleRepository,
+			articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+				OnSave: func(revision *models.ArticleRevision) error { return nil },
+			},
+			articleGenerator: &generators.ArticleGeneratorMock{
+				OnNewStub: func(url, title, content string, tags []string) (*models.Article, error) {
+					article := models.NewArticle(models.KindMarkdown, url, content, title, tags)
+					article.CanonicalURL = &url
+					return article, nil
+				},
+			},
+		},
+		// 본문이 있는 항목은 fetch 이더라도 queue 에 넣지 않는다
+		ingestionService: &ingestionService{},
+	}
+
+	report, err := svc.ImportURLs([]*imports.Item{
+		{URL: "https://example.com/a", Title: "A", Content: "hello world", Favorite: true, Archived: true},
+	}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, "hello world", saved.Content)
+	require.Equal(t, 2, saved.WordCount)
+	require.True(t, saved.Favorite)
+	require.True(t, saved.Archived)
+}
+
+func TestImportNotes(t *testing.T) {
+	var saved []*models.Note
+	svc := &importService{
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) {
+				if canonicalURL == "https://example.com/a" {
+					return &models.Article{ID: 7}, nil
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+		},
+		noteRepository: &mock.NoteRepositoryMock{
+			OnExistByTitle: func(title string) (bool, error) { return title == "exists", nil },
+			OnSave: func(note *models.Note) error {
+				saved = append(saved, note)
+				return nil
+			},
+		},
+	}
+
+	report, err := svc.ImportNotes([]*imports.Note{
+		{
+			Path:  "vault/memo.md",
+			Title: "memo",
+			Tags:  []string{"go"},
+			URLs:  []string{"https://example.com/a", "https://example.com/web"},
+			Paragraphs: []*imports.NoteParagraph{
+				{Content: "# A", Links: []string{"https://example.com/a?utm_source=x", "https://example.com/b"}},
+				{Content: "# B"},
+			},
+		},
+		{Path: "vault/sub/memo.md", Title: "memo"},
+		{Path: "vault/exists.md", Title: "exists"},
+	}, []string{"imported"})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Created)
+	require.Equal(t, 1, report.Skipped)
+	require.Len(t, saved, 2)
+
+	require.Equal(t, models.StringList{"imported", "go"}, saved[0].Tags)
+	require.Len(t, saved[0].Paragraphs, 2)
+	require.Equal(t, []int64{7}, saved[0].Paragraphs[0].ReferenceArticles.ExtractArticleIDs())
+	require.Len(t, saved[0].Paragraphs[0].ReferenceWebs, 1)
+	require.Equal(t, "https://example.com/web", saved[0].Paragraphs[0].ReferenceWebs[0].URL)
+
+	// 같은 이름의 파일은 경로를 제목으로 쓴다
+	require.Equal(t, "vault/sub/memo", saved[1].Title)
+	require.Len(t, saved[1].Paragraphs, 1)
+}
+
+func TestImportBooks(t *testing.T) {
+	var savedArticle *models.Article
+	var savedNote *models.Note
+	articleRepository := &mock.ArticleRepositoryMock{
+		OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) {
+			if savedArticle != nil && canonicalURL == *savedArticle.CanonicalURL {
+				return savedArticle, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+		OnSave: func(article *models.Article) error {
+			article.ID = 3
+			savedArticle = article
+			return nil
+		},
+	}
+	noteRepository := &mock.NoteRepositoryMock{
+		OnExistByTitle: func(title string) (bool, error) { return false, nil },
+		OnSave: func(note *models.Note) error {
+			savedNote = note
+			return nil
+		},
+	}
+	svc := &importService{
+		articleRepository: articleRepository,
+		noteRepository:    noteRepository,
+		articleService: &articleService{
+			articleRepository: articleRepository,
+			articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+				OnSave: func(revision *models.ArticleRevision) error { return nil },
+			},
+			articleGenerator: &generators.ArticleGeneratorMock{
+				OnNewStub: func(url, title, content string, tags []string) (*models.Article, error) {
+					canonicalURL, err := canonical.Normalize(url)
+					require.NoError(t, err)
+					article := models.NewArticle(models.KindMarkdown, url, content, title, tags)
+					article.CanonicalURL = &canonicalURL
+					return article, nil
+				},
+			},
+		},
+	}
+
+	report, err := svc.ImportBooks([]*imports.Book{{
+		Title:      "The Go Programming Language",
+		Author:     "Donovan",
+		Highlights: []*imports.Highlight{{Content: "a", Location: "1"}, {Content: "b", Location: "2"}},
+	}}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Created)
+
+	require.Equal(t, "kindle://book/The%20Go%20Programming%20Language%20%28Donovan%29", savedArticle.URL)
+	require.True(t, savedArticle.Tags.ContainTag("kindle"))
+	require.Len(t, savedNote.Paragraphs, 2)
+	for _, paragraph := range savedNote.Paragraphs {
+		require.Equal(t, []int64{3}, paragraph.ReferenceArticles.ExtractArticleIDs())
+	}
+
+	// 다시 가져오면 노트에 없던 highlight 만 더한다, 시각이 없어도 내용과 위치가 같으면 같은 highlight 다
+	savedNote.ID = 5
+	noteRepository.OnExistByTitle = func(title string) (bool, error) { return true, nil }
+	noteRepository.OnGetByTitle = func(title string) (*models.Note, error) { return savedNote, nil }
+	created := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	report, err = svc.ImportBooks([]*imports.Book{{
+		Title:      "The Go Programming Language",
+		Author:     "Donovan",
+		Highlights: []*imports.Highlight{{Content: "a", Location: "1", Created: &created}, {Content: "c", Location: "3"}, {Content: "a", Location: "9"}},
+	}}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, int64(5), *report.Results[0].NoteID)
+	require.Len(t, savedNote.Paragraphs, 4)
+	require.Equal(t, "> c\n\n— Location 3", savedNote.Paragraphs[2].Content)
+	require.Equal(t, 3, savedNote.Paragraphs[3].Seq)
+	require.Equal(t, []int64{3}, savedNote.Paragraphs[2].ReferenceArticles.ExtractArticleIDs())
+
+	// 더할 highlight 가 없으면 건너뛴다
+	report, err = svc.ImportBooks([]*imports.Book{{
+		Title:      "The Go Programming Language",
+		Author:     "Donovan",
+		Highlights: []*imports.Highlight{{Content: "c", Location: "3"}},
+	}}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Skipped)
+	require.Equal(t, ImportExists, report.Results[0].Outcome)
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/common"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+const (
+	ingestionWorkers      = 3
+	ingestionMaxAttempts  = 5
+	ingestionRetryBackoff = 30 * time.Second
+	ingestionPollInterval = 5 * time.Second
+)
+
+var ErrInvalidIngestionJobState = errors.New("invalid ingestion job state")
+
+type IngestionService interface {
+	Start()
+	Enqueue(url string, tags []string, source string) (*models.IngestionJob, error)
+	EnqueueJob(job *models.IngestionJob) (*models.IngestionJob, error)
+	Retry(id int64) (*models.IngestionJob, error)
+	Cancel(id int64) (*models.IngestionJob, error)
+}
+
+type ingestionService struct {
+	ingestionJobRepository repositories.IngestionJobRepository
+	articleService         ArticleService
+	wakeup                 chan struct{}
+	// worker 가 처리하고 있는 job 의 id, 실행 중에 취소된 job 도 worker 가 끝날 때까지 남아있다
+	mutex   sync.Mutex
+	running map[int64]bool
+}
+
+var GetIngestionService = func() func() IngestionService {
+	var once sync.Once
+	var instance IngestionService
+	return func() IngestionService {
+		once.Do(func() {
+			instance = &ingestionService{
+				ingestionJobRepository: repositories.GetIngestionJobRepository(),
+				articleService:         GetArticleService(),
+				wakeup:                 make(chan struct{}, 1),
+				running:                map[int64]bool{},
+			}
+		})
+		return instance
+	}
+}()
+
+func (s *ingestionService) Start() {
+	if err := s.ingestionJobRepository.ResetRunning(); err != nil {
+		logrus.Errorf("failed to reset running ingestion jobs: %s", err.Error())
+	}
+
+	jobs := make(chan *models.IngestionJob)
+	for i := 0; i < ingestionWorkers; i++ {
+		go func() {
+			for job := range jobs {
+				s.run(job)
+			}
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(ingestionPollInterval)
+		for {
+			s.dispatch(jobs)
+			select {
+			case <-ticker.C:
+			case <-s.wakeup:
+			}
+		}
+	}()
+}
+
+// dispatch 는 실행할 때가 된 job 을 running 으로 바꾸어 worker 에 넘긴다. 모든 worker 가 바쁘면 기다린다.
+func (s *ingestionService) dispatch(jobs chan<- *models.IngestionJob) {
+	for {
+		runnable, err := s.ingestionJobRepository.FindRunnable(time.Now(), ingestionWorkers)
+		if err != nil {
+			logrus.Errorf("failed to find runnable ingestion jobs: %s", err.Error())
+			return
+		} else if len(runnable) == 0 {
+			return
+		}
+
+		for _, job := range runnable {
+			job.State = models.IngestionJobRunning
+			if ok, err := s.ingestionJobRepository.Transition(job, models.IngestionJobQueued); err != nil {
+				logrus.Errorf("failed to start ingestion job %d: %s", job.ID, err.Error())
+				return
+			} else if !ok {
+				continue
+			}
+			jobs <- job
+		}
+	}
+}
+
+func (s *ingestionService) run(job *models.IngestionJob) {
+	s.setRunning(job.ID, true)
+	defer s.setRunning(job.ID, false)
+
+	job.Attempts++
+	article, err := s.articleService.CreateByJob(job)
+	if err != nil {
+		logrus.Errorf("failed to ingest %s (attempt %d): %s", job.URL, job.Attempts, err.Error())
+		job.LastError = err.Error()
+		if job.Attempts >= ingestionMaxAttempts || isPermanentIngestionError(err) {
+			job.State = models.IngestionJobFailed
+		} else {
+			job.State = models.IngestionJobQueued
+			job.NextAttempt = time.Now().Add(ingestionBackoff(job.Attempts))
+		}
+	} else {
+		job.State = models.IngestionJobSucceeded
+		job.LastError = ""
+		job.ArticleID = &article.ID
+	}
+
+	if ok, err := s.ingestionJobRepository.Transition(job, models.IngestionJobRunning); err != nil {
+		logrus.Errorf("failed to save ingestion job %d: %s", job.ID, err.Error())
+	} else if !ok {
+		logrus.Infof("ingestion job %d was canceled while running", job.ID)
+	}
+}
+
+func (s *ingestionService) setRunning(id int64, running bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if running {
+		s.running[id] = true
+	} else {
+		delete(s.running, id)
+	}
+}
+
+func (s *ingestionService) isRunning(id int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.running[id]
+}
+
+// isPermanentIngestionError 는 다시 시도해도 실패할 에러인지 여부다.
+func isPermanentIngestionError(err error) bool {
+	for _, target := range []error{
+		generators.ErrPageNotFound,
+		generators.ErrUnsupportedContent,
+		generators.ErrInvalidURL,
+		ErrNotFetchable,
+		ErrTitleExists,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ingestionBackoff 는 attempts 번 실패한 job 이 다시 시도하기까지 기다릴 시간이다.
+func ingestionBackoff(attempts int) time.Duration {
+	return ingestionRetryBackoff << uint(attempts-1)
+}
+
+// Enqueue 는 url 의 job 을 queue 에 넣는다. 같은 url 의 job 이 이미 대기 중이거나 실행 중이면 그 job 을 돌려준다.
+func (s *ingestionService) Enqueue(url string, tags []string, source string) (*models.IngestionJob, error) {
+	return s.EnqueueJob(models.NewIngestionJob(url, tags, source))
+}
+
+// EnqueueJob 은 url 외의 정보가 채워진 job 을 넣고, 같은 url 의 job 이 대기 중이면 그 job 에 더한다.
+func (s *ingestionService) EnqueueJob(job *models.IngestionJob) (*models.IngestionJob, error) {
+	active, err := s.ingestionJobRepository.GetActiveByURL(job.URL)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get active ingestion job")
+	} else if err == nil && active.State == models.IngestionJobQueued {
+		mergeIngestionJob(active, job)
+		if ok, err := s.ingestionJobRepository.UpdateQueued(active); err != nil {
+			return nil, errors.Wrap(err, "failed to save ingestion job")
+		} else if ok {
+			return active, nil
+		}
+	}
+
+	if err := s.ingestionJobRepository.Save(job); err != nil {
+		return nil, errors.Wrap(err, "failed to save ingestion job")
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// mergeIngestionJob 은 같은 url 로 다시 들어온 job 의 tag, 즐겨찾기, 보관 여부, 제목, 저장한 시각을 대기 중인 active 에 더한다.
+func mergeIngestionJob(active, job *models.IngestionJob) {
+	tags := common.Strings(active.Tags)
+	for _, tag := range job.Tags {
+		if !tags.Contain(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	active.Tags = models.StringList(tags)
+
+	active.Favorite = active.Favorite || job.Favorite
+	active.Archived = active.Archived || job.Archived
+	if active.Title == "" {
+		active.Title = job.Title
+	}
+	if active.FallbackTitle == "" {
+		active.FallbackTitle = job.FallbackTitle
+	}
+	if job.ArticleCreated != nil && (active.ArticleCreated == nil || job.ArticleCreated.Before(*active.ArticleCreated)) {
+		active.ArticleCreated = job.ArticleCreated
+	}
+}
+
+// Retry 는 실패했거나 취소된 job 을 처음부터 다시 시도하며, 실행 중에 취소된 job 은 worker 가 끝난 뒤에만 다시 시도할 수 있다.
+func (s *ingestionService) Retry(id int64) (*models.IngestionJob, error) {
+	job, err := s.ingestionJobRepository.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ingestion job")
+	} else if !job.IsRetryable() {
+		return nil, errors.Wrapf(ErrInvalidIngestionJobState, "%s job can not be retried", job.State)
+	} else if s.isRunning(job.ID) {
+		return nil, errors.Wrap(ErrInvalidIngestionJobState, "canceled job is still running")
+	}
+
+	from := job.State
+	job.State = models.IngestionJobQueued
+	job.Attempts = 0
+	job.NextAttempt = time.Now()
+	if err := s.transition(job, from); err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// Cancel 은 대기 중이거나 실행 중인 job 을 취소한다. 이미 내려받고 있던 article 은 만들어질 수 있다.
+func (s *ingestionService) Cancel(id int64) (*models.IngestionJob, error) {
+	job, err := s.ingestionJobRepository.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ingestion job")
+	} else if !job.IsCancelable() {
+		return nil, errors.Wrapf(ErrInvalidIngestionJobState, "%s job can not be canceled", job.State)
+	}
+
+	from := job.State
+	job.State = models.IngestionJobCanceled
+	if err := s.transition(job, from); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *ingestionService) transition(job *models.IngestionJob, from string) error {
+	if ok, err := s.ingestionJobRepository.Transition(job, from); err != nil {
+		return errors.Wrap(err, "failed to save ingestion job")
+	} else if !ok {
+		return errors.Wrapf(ErrInvalidIngestionJobState, "job is no longer %s", from)
+	}
+	return nil
+}
+
+func (s *ingestionService) notify() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestIngestionRun(t *testing.T) {
+	var fetchErr error
+	var transitioned *models.IngestionJob
+	svc := &ingestionService{
+		ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+			OnTransition: func(job *models.IngestionJob, from string) (bool, error) {
+				require.Equal(t, models.IngestionJobRunning, from)
+				transitioned = job
+				return true, nil
+			},
+		},
+		articleService: &articleService{
+			articleRepository: &mock.ArticleRepositoryMock{
+				OnGetByCanonicalURL: func(canonicalURL string) (*models.Article, error) { return nil, gorm.ErrRecordNotFound },
+				OnSave: func(article *models.Article) error {
+					article.ID = 7
+					return nil
+				},
+			},
+			articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+				OnSave: func(revision *models.ArticleRevision) error { return nil },
+			},
+			articleGenerator: &generators.ArticleGeneratorMock{
+				OnNewArticle: func(url string, tags []string) (*models.Article, error) {
+					if fetchErr != nil {
+						return nil, fetchErr
+					}
+					return &models.Article{URL: url}, nil
+				},
+			},
+		},
+		running: map[int64]bool{},
+	}
+
+	// case 1: 실패하면 backoff 후 다시 시도한다
+	fetchErr = errors.New("timeout")
+	job := &models.IngestionJob{ID: 1, URL: "https://example.com/post", State: models.IngestionJobRunning}
+	svc.run(job)
+	require.Equal(t, models.IngestionJobQueued, transitioned.State)
+	require.Equal(t, 1, transitioned.Attempts)
+	require.Contains(t, transitioned.LastError, "timeout")
+	require.WithinDuration(t, time.Now().Add(ingestionRetryBackoff), transitioned.NextAttempt, time.Second)
+
+	// case 2: 마지막 시도까지 실패하면 failed
+	job.Attempts = ingestionMaxAttempts - 1
+	svc.run(job)
+	require.Equal(t, models.IngestionJobFailed, transitioned.State)
+
+	// case 3: 다시 시도해도 실패할 에러면 바로 failed
+	fetchErr = errors.Wrap(generators.ErrPageNotFound, "response code is 404")
+	job = &models.IngestionJob{ID: 3, URL: "https://example.com/gone", State: models.IngestionJobRunning}
+	svc.run(job)
+	require.Equal(t, models.IngestionJobFailed, transitioned.State)
+	require.Equal(t, 1, transitioned.Attempts)
+
+	// case 4: 성공
+	fetchErr = nil
+	job = &models.IngestionJob{ID: 2, URL: "https://example.com/post", State: models.IngestionJobRunning}
+	svc.run(job)
+	require.Equal(t, models.IngestionJobSucceeded, transitioned.State)
+	require.Equal(t, int64(7), *transitioned.ArticleID)
+	require.Empty(t, transitioned.LastError)
+}
+
+func TestIngestionBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, ingestionBackoff(1))
+	require.Equal(t, 2*time.Minute, ingestionBackoff(3))
+}
+
+func TestIngestionCancel(t *testing.T) {
+	state := models.IngestionJobSucceeded
+	svc := &ingestionService{
+		ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+			OnGetByID: func(id int64) (*models.IngestionJob, error) {
+				return &models.IngestionJob{ID: id, State: state}, nil
+			},
+			OnTransition: func(job *models.IngestionJob, from string) (bool, error) {
+				return from == state, nil
+			},
+		},
+	}
+
+	// case 1: 끝난 job 은 취소할 수 없다
+	_, err := svc.Cancel(1)
+	require.True(t, errors.Is(err, ErrInvalidIngestionJobState))
+
+	// case 2: 대기 중인 job
+	state = models.IngestionJobQueued
+	job, err := svc.Cancel(1)
+	require.NoError(t, err)
+	require.Equal(t, models.IngestionJobCanceled, job.State)
+}
+
+func TestIngestionEnqueueJob(t *testing.T) {
+	active := &models.IngestionJob{ID: 1, URL: "https://example.com/post", Tags: models.StringList{"go"}, State: models.IngestionJobQueued}
+	var saved []*models.IngestionJob
+	svc := &ingestionService{
+		ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+			OnGetActiveByURL: func(url string) (*models.IngestionJob, error) { return active, nil },
+			OnUpdateQueued: func(job *models.IngestionJob) (bool, error) {
+				return job.State == models.IngestionJobQueued, nil
+			},
+			OnSave: func(job *models.IngestionJob) error {
+				saved = append(saved, job)
+				return nil
+			},
+		},
+		wakeup: make(chan struct{}, 1),
+	}
+
+	// case 1: 대기 중인 job 에 tag, 즐겨찾기, 제목을 더한다
+	duplicate := models.NewIngestionJob("https://example.com/post", []string{"web", "go"}, models.IngestionSourcePocket)
+	duplicate.Favorite = true
+	duplicate.FallbackTitle = "pocket title"
+	job, err := svc.EnqueueJob(duplicate)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), job.ID)
+	require.Equal(t, models.StringList{"go", "web"}, job.Tags)
+	require.True(t, job.Favorite)
+	require.False(t, job.Archived)
+	require.Equal(t, "pocket title", job.FallbackTitle)
+	require.Empty(t, saved)
+
+	// case 2: 실행 중인 job 이 있으면 따로 넣는다
+	active.State = models.IngestionJobRunning
+	job, err = svc.Enqueue("https://example.com/post", []string{"later"}, models.IngestionSourceAPI)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, models.StringList{"later"}, job.Tags)
+}
+
+func TestIngestionRetry(t *testing.T) {
+	svc := &ingestionService{
+		ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+			OnGetByID: func(id int64) (*models.IngestionJob, error) {
+				return &models.IngestionJob{ID: id, State: models.IngestionJobCanceled}, nil
+			},
+			OnTransition: func(job *models.IngestionJob, from string) (bool, error) { return true, nil },
+		},
+		wakeup:  make(chan struct{}, 1),
+		running: map[int64]bool{},
+	}
+
+	// case 1: 실행 중에 취소된 job 은 worker 가 끝날 때까지 다시 시도할 수 없다
+	svc.setRunning(1, true)
+	_, err := svc.Retry(1)
+	require.True(t, errors.Is(err, ErrInvalidIngestionJobState))
+
+	// case 2: worker 가 끝난 뒤
+	svc.setRunning(1, false)
+	job, err := svc.Retry(1)
+	require.NoError(t, err)
+	require.Equal(t, models.IngestionJobQueued, job.State)
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"bytes"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jaeyo/personal-archive/common/canonical"
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
+	"github.com/jaeyo/personal-archive/services/generators"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	netUrl "net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	linkCheckPollInterval = time.Hour
+	// 한 article 의 원본 url 을 다시 확인하기까지의 간격
+	linkCheckInterval  = 7 * 24 * time.Hour
+	linkCheckBatchSize = 200
+	// 동시에 확인하는 host 수, 한 host 의 url 들은 차례로 확인한다
+	linkCheckWorkers = 4
+	// 같은 host 로 보내는 요청 사이의 최소 간격
+	linkCheckHostDelay   = 5 * time.Second
+	linkCheckTimeout     = 20 * time.Second
+	linkCheckMaxBodySize = 5 * 1024 * 1024
+	// 페이지 제목에 article 제목의 단어가 이 비율보다 적게 남아 있으면 다른 문서로 본다
+	linkCheckTitleSimilarity = 0.5
+)
+
+type LinkCheckService interface {
+	Start()
+	Check(id int64) (*models.LinkCheck, error)
+}
+
+type linkCheckService struct {
+	articleRepository         repositories.ArticleRepository
+	articleRevisionRepository repositories.ArticleRevisionRepository
+	linkCheckRepository       repositories.LinkCheckRepository
+	client                    fetch.Client
+	throttle                  *hostThrottle
+}
+
+var GetLinkCheckService = func() func() LinkCheckService {
+	var once sync.Once
+	var instance LinkCheckService
+	return func() LinkCheckService {
+		once.Do(func() {
+			instance = &linkCheckService{
+				articleRepository:         repositories.GetArticleRepository(),
+				articleRevisionRepository: repositories.GetArticleRevisionRepository(),
+				linkCheckRepository:       repositories.GetLinkCheckRepository(),
+				client:                    newLinkCheckClient(),
+				throttle:                  newHostThrottle(linkCheckHostDelay),
+			}
+		})
+		return instance
+	}
+}()
+
+// newLinkCheckClient 는 재시도하지 않는 client 를 만든다. 실패한 url 은 다음 주기에 다시 확인한다.
+func newLinkCheckClient() fetch.Client {
+	config := fetch.ConfigFromEnv()
+	config.Timeout = linkCheckTimeout
+	config.MaxBodySize = linkCheckMaxBodySize
+	config.Retries = 0
+	return fetch.NewClient(config)
+}
+
+func (s *linkCheckService) Start() {
+	go func() {
+		ticker := time.NewTicker(linkCheckPollInterval)
+		for {
+			s.checkDue()
+			<-ticker.C
+		}
+	}()
+}
+
+// checkDue 는 확인할 때가 된 article 들을 host 별로 묶어, host 마다 하나의 worker 가 차례로 확인하게 한다.
+func (s *linkCheckService) checkDue() {
+	articles, err := s.articleRepository.FindLinkCheckDue(time.Now().Add(-linkCheckInterval), linkCheckBatchSize)
+	if err != nil {
+		logrus.Errorf("failed to find articles to check link: %s", err.Error())
+		return
+	} else if len(articles) == 0 {
+		return
+	}
+
+	byHost := map[string]models.Articles{}
+	for _, article := range articles {
+		host := hostOf(article.URL)
+		byHost[host] = append(byHost[host], article)
+	}
+
+	groups := make(chan models.Articles)
+	var wg sync.WaitGroup
+	for i := 0; i < linkCheckWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range groups {
+				for _, article := range group {
+					if _, err := s.record(article, s.checkLink(article)); err != nil {
+						logrus.Errorf("failed to record link check of article %d: %s", article.ID, err.Error())
+					}
+				}
+			}
+		}()
+	}
+	for _, group := range byHost {
+		groups <- group
+	}
+	close(groups)
+	wg.Wait()
+
+	logrus.Infof("checked links of %d articles", len(articles))
+}
+
+// Check 는 article 의 원본 url 을 바로 확인한다.
+func (s *linkCheckService) Check(id int64) (*models.LinkCheck, error) {
+	article, err := s.articleRepository.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get article")
+	}
+	return s.record(article, s.checkLink(article))
+}
+
+// record 는 article 의 link status 를 갱신하고, 상태가 바뀌었으면 기록을 남긴다.
+func (s *linkCheckService) record(article *models.Article, check *models.LinkCheck) (*models.LinkCheck, error) {
+	check.Created = time.Now()
+	if check.Status != article.LinkStatus {
+		if err := s.linkCheckRepository.Save(check); err != nil {
+			return nil, errors.Wrap(err, "failed to save link check")
+		}
+	}
+
+	if err := s.articleRepository.UpdateLinkStatus(article.ID, check.Status, check.Created); err != nil {
+		return nil, errors.Wrap(err, "failed to update link status")
+	}
+	article.LinkStatus = check.Status
+	article.LinkChecked = &check.Created
+	return check, nil
+}
+
+// checkLink 는 제목을 비교할 markdown article 은 GET 으로, 나머지는 HEAD 로 먼저 확인한다.
+func (s *linkCheckService) checkLink(article *models.Article) *models.LinkCheck {
+	check := &models.LinkCheck{ArticleID: article.ID}
+	host := hostOf(article.URL)
+
+	var resp *fetch.Response
+	var err error
+	s.throttle.wait(host)
+	if article.Kind == models.KindMarkdown {
+		resp, err = s.client.Get(article.URL)
+	} else if resp, err = s.client.Head(article.URL); err != nil || resp.StatusCode >= http.StatusBadRequest {
+		s.throttle.wait(host)
+		resp, err = s.client.Get(article.URL)
+	}
+
+	if err != nil {
+		check.Status = models.LinkError
+		if isHostNotFound(err) {
+			check.Status = models.LinkGone
+		}
+		check.Error = err.Error()
+		return check
+	}
+
+	check.StatusCode = resp.StatusCode
+	check.FinalURL = resp.URL
+	check.Status = classifyLink(article, resp, s.titlesOf(article))
+	return check
+}
+
+// titlesOf 는 페이지 제목과 비교할 지금 제목과 처음 저장할 때의 제목이다.
+func (s *linkCheckService) titlesOf(article *models.Article) []string {
+	titles := []string{generators.TrimUniqueTitleSuffix(article.Title)}
+	if article.Kind != models.KindMarkdown {
+		return titles
+	}
+
+	revisions, err := s.articleRevisionRepository.FindByArticleID(article.ID)
+	if err != nil {
+		logrus.Warnf("failed to find revisions of article %d: %s", article.ID, err.Error())
+	} else if len(revisions) > 0 {
+		// 최근 revision 부터 정렬되어 있다
+		titles = append(titles, generators.TrimUniqueTitleSuffix(revisions[len(revisions)-1].Title))
+	}
+	return titles
+}
+
+func classifyLink(article *models.Article, resp *fetch.Response, titles []string) string {
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusUnavailableForLegalReasons:
+		return models.LinkGone
+	case resp.StatusCode >= http.StatusBadRequest:
+		return models.LinkError
+	}
+
+	if finalKey := linkKey(resp.URL); finalKey != linkKey(article.URL) && (article.CanonicalURL == nil || finalKey != linkKey(*article.CanonicalURL)) {
+		// 없어진 문서를 사이트 첫 페이지로 보내는 경우
+		if isRootURL(resp.URL) && !isRootURL(article.URL) {
+			return models.LinkGone
+		}
+		return models.LinkRedirected
+	}
+
+	if article.Kind == models.KindMarkdown && isHTMLResponse(resp) && len(resp.Body) > 0 {
+		if title := pageTitle(resp.Body); title != "" && !isSimilarTitle(titles, title) {
+			return models.LinkChanged
+		}
+	}
+	return models.LinkAlive
+}
+
+func isSimilarTitle(titles []string, pageTitle string) bool {
+	for _, title := range titles {
+		if titleSimilarity(title, pageTitle) >= linkCheckTitleSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTMLResponse(resp *fetch.Response) bool {
+	return strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "html")
+}
+
+func isHostNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func hostOf(rawURL string) string {
+	u, err := netUrl.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// linkKey 는 scheme, `www.`, 끝의 `/` 처럼 문서가 같은지와 상관없는 차이를 지운 url 이다.
+func linkKey(rawURL string) string {
+	normalized, err := canonical.Normalize(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u, err := netUrl.Parse(normalized)
+	if err != nil {
+		return normalized
+	}
+	return strings.TrimPrefix(u.Host, "www.") + strings.TrimSuffix(u.Path, "/") + "?" + u.RawQuery
+}
+
+func isRootURL(rawURL string) bool {
+	u, err := netUrl.Parse(rawURL)
+	return err == nil && strings.Trim(u.Path, "/") == "" && u.RawQuery == ""
+}
+
+func pageTitle(body []byte) string {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	if title, ok := doc.Find(`meta[property="og:title"]`).Attr("content"); ok && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
+
+// titleSimilarity 는 article 제목의 단어 중 페이지 제목에도 있는 단어의 비율이다.
+func titleSimilarity(articleTitle, pageTitle string) float64 {
+	words := titleWords(articleTitle)
+	if len(words) == 0 {
+		return 1
+	}
+
+	pageWords := map[string]bool{}
+	for _, word := range titleWords(pageTitle) {
+		pageWords[word] = true
+	}
+
+	matched := 0
+	for _, word := range words {
+		if pageWords[word] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(words))
+}
+
+func titleWords(title string) []string {
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// hostThrottle 은 같은 host 로 보내는 요청 사이에 최소 간격을 둔다.
+type hostThrottle struct {
+	mutex sync.Mutex
+	delay time.Duration
+	next  map[string]time.Time
+}
+
+func newHostThrottle(delay time.Duration) *hostThrottle {
+	return &hostThrottle{
+		delay: delay,
+		next:  map[string]time.Time{},
+	}
+}
+
+// wait 는 host 에 요청을 보내도 될 때까지 기다린다.
+func (t *hostThrottle) wait(host string) {
+	t.mutex.Lock()
+	now := time.Now()
+	for h, at := range t.next {
+		if at.Before(now) {
+			delete(t.next, h)
+		}
+	}
+	at := t.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	t.next[host] = at.Add(t.delay)
+	t.mutex.Unlock()
+
+	time.Sleep(at.Sub(now))
+}
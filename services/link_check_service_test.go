@@ -0,0 +1,94 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/common/fetch"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckLink(t *testing.T) {
+	methods := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods[r.Method] = true
+		switch r.URL.Path {
+		case "/post", "/new-post":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>Hello Link Rot | Blog</title></head></html>`))
+		case "/replaced":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>Buy cheap domains</title></head></html>`))
+		case "/moved":
+			http.Redirect(w, r, "/new-post", http.StatusMovedPermanently)
+		case "/removed":
+			http.Redirect(w, r, "/", http.StatusFound)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "application/pdf")
+		case "/":
+			w.Write([]byte("home"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	svc := &linkCheckService{
+		articleRevisionRepository: &mock.ArticleRevisionRepositoryMock{
+			OnFindByArticleID: func(articleID int64) (models.ArticleRevisions, error) {
+				return models.ArticleRevisions{{Title: "Go notes"}, {Title: "Hello link rot(1)"}}, nil
+			},
+		},
+		client:   fetch.NewClient(fetch.Config{Timeout: time.Second}),
+		throttle: newHostThrottle(0),
+	}
+
+	for path, expected := range map[string]string{
+		"/post":     models.LinkAlive,
+		"/replaced": models.LinkChanged,
+		"/moved":    models.LinkRedirected,
+		"/removed":  models.LinkGone,
+		"/deleted":  models.LinkGone,
+		"/no-head":  models.LinkAlive,
+	} {
+		article := &models.Article{Kind: models.KindMarkdown, URL: server.URL + path, Title: "Hello link rot"}
+		check := svc.checkLink(article)
+		require.Equal(t, expected, check.Status, path)
+	}
+	// markdown article 은 GET 으로만 확인한다
+	require.Equal(t, map[string]bool{http.MethodGet: true}, methods)
+
+	// case 2: 겹치지 않도록 붙인 `(1)` 이나 사용자가 바꾼 제목은 처음 제목과 비교한다
+	for _, title := range []string{"Hello link rot(1)(1)", "Go notes"} {
+		check := svc.checkLink(&models.Article{Kind: models.KindMarkdown, URL: server.URL + "/post", Title: title})
+		require.Equal(t, models.LinkAlive, check.Status, title)
+	}
+
+	// case 3: markdown 이 아니면 HEAD 로 먼저 확인한다
+	check := svc.checkLink(&models.Article{Kind: models.KindPDF, URL: server.URL + "/replaced", Title: "Hello link rot"})
+	require.Equal(t, models.LinkAlive, check.Status)
+	require.True(t, methods[http.MethodHead])
+}
+
+func TestHostThrottle(t *testing.T) {
+	throttle := newHostThrottle(50 * time.Millisecond)
+	start := time.Now()
+	throttle.wait("example.com")
+	throttle.wait("example.org")
+	require.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	throttle.wait("example.com")
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	// 지난 host 는 남겨두지 않는다
+	time.Sleep(100 * time.Millisecond)
+	throttle.wait("example.net")
+	require.Len(t, throttle.next, 1)
+}
@@ -227,7 +227,7 @@ func (s *noteService) DeleteByIDs(ids []int64) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to find notes")
 	} else if len(ids) != len(notes) {
-		fmt.Errorf("invalid ids: %v", ids)
+		return fmt.Errorf("invalid ids: %v", ids)
 	}
 
 	paragraphs := notes.ExtractParagraphs()
@@ -29,3 +29,23 @@ func TestSwapParagraphs(t *testing.T) {
 	require.Equal(t, 101, paragraphA.Seq)
 	require.Equal(t, 100, paragraphB.Seq)
 }
+
+func TestDeleteNotesWithInvalidIDs(t *testing.T) {
+	deleted := false
+	svc := &noteService{
+		noteRepository: &mock.NoteRepositoryMock{
+			OnFindByIDs: func(ids []int64) (models.Notes, error) {
+				return models.Notes{{ID: 1}}, nil
+			},
+			OnDeleteByIDs: func(ids []int64) error {
+				deleted = true
+				return nil
+			},
+		},
+	}
+
+	// 없는 노트가 섞여 있으면 아무것도 지우지 않는다
+	err := svc.DeleteByIDs([]int64{1, 2})
+	require.EqualError(t, err, "invalid ids: [1 2]")
+	require.False(t, deleted)
+}
@@ -16,10 +16,27 @@ const (
 	PocketAccessToken  = "pocket.access_token"
 	PocketUsername     = "pocket.username"
 	PocketSync         = "pocket.sync"
-	PocketLastOffset   = "pocket.last_offset"
 	PocketLastSyncTime = "pocket.last_sync_time"
+	// 마지막으로 가져온 응답의 since
+	PocketSince = "pocket.since"
+	// 처음 전체 sync 중에 다음으로 가져올 offset, 전체 sync 를 마치면 지운다
+	PocketInitialOffset = "pocket.initial_offset"
+	// 예전의 offset 으로 가져오던 sync 가 남긴 값, Unauth 할 때 함께 지운다
+	PocketLastOffset = "pocket.last_offset"
 )
 
+// PocketSyncCursor 는 pocket 의 항목을 어디까지 가져왔는지다.
+type PocketSyncCursor struct {
+	// 다음에 바뀐 항목만 가져올 때 넘길 since, 0 이면 아직 처음 전체 sync 를 시작하지 않았다
+	Since int64
+	// 처음 전체 sync 중이면 다음으로 가져올 offset, 전체 sync 를 마쳤으면 -1
+	InitialOffset int
+}
+
+func (c *PocketSyncCursor) IsInitial() bool {
+	return c.InitialOffset >= 0
+}
+
 type PocketService interface {
 	ObtainRequestToken(consumerKey, redirectURI string) (string, error)
 	Auth() (bool, error)
@@ -30,8 +47,8 @@ type PocketService interface {
 	SetLastSyncTime(tm time.Time) error
 	SetSyncable(isSyncable bool) error
 	GetSyncable() (bool, error)
-	GetLastOffset() (int, error)
-	SetLastOffset(offset int) error
+	GetSyncCursor() (*PocketSyncCursor, error)
+	SetSyncCursor(cursor *PocketSyncCursor) error
 	GetConsumerKey() (string, error)
 	GetAccessToken() (string, error)
 }
@@ -106,8 +123,10 @@ func (s *pocketService) Unauth() error {
 		PocketAccessToken,
 		PocketUsername,
 		PocketSync,
-		PocketLastOffset,
 		PocketLastSyncTime,
+		PocketSince,
+		PocketInitialOffset,
+		PocketLastOffset,
 	}); err != nil {
 		return errors.Wrap(err, "failed to delete by keys")
 	}
@@ -170,19 +189,35 @@ func (s *pocketService) SetLastSyncTime(tm time.Time) error {
 	return nil
 }
 
-func (s *pocketService) GetLastOffset() (int, error) {
-	value, err := s.miscRepository.GetValue(PocketLastOffset)
+func (s *pocketService) GetSyncCursor() (*PocketSyncCursor, error) {
+	since, err := s.getInt(PocketSince)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get since")
+	} else if since < 0 {
+		return &PocketSyncCursor{Since: 0, InitialOffset: 0}, nil
+	}
+
+	offset, err := s.getInt(PocketInitialOffset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get initial offset")
+	}
+	return &PocketSyncCursor{Since: since, InitialOffset: int(offset)}, nil
+}
+
+// getInt 는 key 의 값을 숫자로 읽는다. 값이 없으면 -1 이다.
+func (s *pocketService) getInt(key string) (int64, error) {
+	value, err := s.miscRepository.GetValue(key)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, nil
+			return -1, nil
 		}
 		return -1, errors.Wrap(err, "failed to get value")
 	}
-	offset, err := strconv.Atoi(value)
+	n, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return -1, errors.Wrap(err, "failed to convert last offset")
+		return -1, errors.Wrapf(err, "failed to parse %s", key)
 	}
-	return offset, nil
+	return n, nil
 }
 
 func (s *pocketService) SetSyncable(isSyncable bool) error {
@@ -208,8 +243,21 @@ func (s *pocketService) GetSyncable() (bool, error) {
 	}
 }
 
-func (s *pocketService) SetLastOffset(offset int) error {
-	return s.miscRepository.CreateOrUpdate(PocketLastOffset, strconv.Itoa(offset))
+func (s *pocketService) SetSyncCursor(cursor *PocketSyncCursor) error {
+	if err := s.miscRepository.CreateOrUpdate(PocketSince, strconv.FormatInt(cursor.Since, 10)); err != nil {
+		return errors.Wrap(err, "failed to create/update since")
+	}
+
+	if !cursor.IsInitial() {
+		if err := s.miscRepository.DeleteByKeys([]string{PocketInitialOffset, PocketLastOffset}); err != nil {
+			return errors.Wrap(err, "failed to delete initial offset")
+		}
+		return nil
+	}
+	if err := s.miscRepository.CreateOrUpdate(PocketInitialOffset, strconv.Itoa(cursor.InitialOffset)); err != nil {
+		return errors.Wrap(err, "failed to create/update initial offset")
+	}
+	return nil
 }
 
 func (s *pocketService) GetConsumerKey() (string, error) {
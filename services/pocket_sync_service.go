@@ -1,21 +1,34 @@
 package services
 
 import (
-	"github.com/gammazero/workerpool"
+	"fmt"
+	"github.com/jaeyo/personal-archive/common"
+	"github.com/jaeyo/personal-archive/common/canonical"
 	"github.com/jaeyo/personal-archive/common/pocket"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 	"sync"
 	"time"
 )
 
+// 처음 전체 sync 에서 한 번에 가져오는 항목 수
+const pocketPageSize = 30
+
 type PocketSyncService interface {
 	Start()
 }
 
 type pocketSyncService struct {
-	pocketService  PocketService
-	articleService ArticleService
+	pocketService          PocketService
+	ingestionService       IngestionService
+	pocketItemRepository   repositories.PocketItemRepository
+	ingestionJobRepository repositories.IngestionJobRepository
+	articleRepository      repositories.ArticleRepository
+	tagPrefix              string
+	tagMapping             map[string]string
 }
 
 var GetPocketSyncService = func() func() PocketSyncService {
@@ -24,8 +37,13 @@ var GetPocketSyncService = func() func() PocketSyncService {
 	return func() PocketSyncService {
 		once.Do(func() {
 			instance = &pocketSyncService{
-				pocketService:  GetPocketService(),
-				articleService: GetArticleService(),
+				pocketService:          GetPocketService(),
+				ingestionService:       GetIngestionService(),
+				pocketItemRepository:   repositories.GetPocketItemRepository(),
+				ingestionJobRepository: repositories.GetIngestionJobRepository(),
+				articleRepository:      repositories.GetArticleRepository(),
+				tagPrefix:              common.PocketTagPrefix(),
+				tagMapping:             common.PocketTagMapping(),
 			}
 		})
 		return instance
@@ -51,50 +69,251 @@ func (s *pocketSyncService) sync() {
 	}
 
 	logrus.Info("start to sync pocket")
-	urls, err := s.retrieveURLs()
-	if err != nil {
-		logrus.Errorf("failed to retrieve urls: %s", err.Error())
+	if err := s.syncOnce(); err != nil {
+		logrus.Errorf("failed to sync pocket: %s", err.Error())
 		return
 	}
 
-	pool := workerpool.New(3)
-	for _, url := range urls {
-		url := url
-		pool.Submit(func() {
-			if _, err := s.articleService.CreateByURL(url, []string{"pocket"}); err != nil {
-				logrus.Errorf("failed to create article by url (%s): %s", url, err.Error())
-			}
-		})
-	}
-	pool.StopWait()
-
 	if err := s.pocketService.SetLastSyncTime(time.Now()); err != nil {
 		logrus.Errorf("failed to set last sync time: %s", err.Error())
 	}
 }
 
-func (s *pocketSyncService) retrieveURLs() ([]string, error) {
+// syncOnce 는 처음에는 모든 항목을 한 page 씩, 그 뒤로는 마지막 sync 이후 바뀐 항목만 가져온다.
+func (s *pocketSyncService) syncOnce() error {
 	consumerKey, err := s.pocketService.GetConsumerKey()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get consumer key")
+		return errors.Wrap(err, "failed to get consumer key")
 	}
 	accessToken, err := s.pocketService.GetAccessToken()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get access token")
+		return errors.Wrap(err, "failed to get access token")
+	}
+	cursor, err := s.pocketService.GetSyncCursor()
+	if err != nil {
+		return errors.Wrap(err, "failed to get sync cursor")
+	}
+
+	options := pocket.RetrieveOptions{Since: cursor.Since}
+	if cursor.IsInitial() {
+		options = pocket.RetrieveOptions{Offset: cursor.InitialOffset, Count: pocketPageSize}
+	}
+	result, err := pocket.Retrieve(consumerKey, accessToken, options)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve items")
+	}
+
+	// 반영하지 못한 항목을 다음 sync 에서 다시 가져오도록 cursor 를 옮기지 않는다
+	if err := s.applyItems(result.Items); err != nil {
+		return err
+	}
+	if err := s.pocketService.SetSyncCursor(nextPocketSyncCursor(cursor, result)); err != nil {
+		return errors.Wrap(err, "failed to set sync cursor")
+	}
+
+	return s.applyPending()
+}
+
+// nextPocketSyncCursor 는 cursor 로 result 를 가져온 뒤 다음에 가져올 위치다.
+func nextPocketSyncCursor(cursor *PocketSyncCursor, result *pocket.RetrieveResult) *PocketSyncCursor {
+	next := *cursor
+	if !cursor.IsInitial() {
+		if result.Since > 0 {
+			next.Since = result.Since
+		}
+		return &next
+	}
+
+	if cursor.InitialOffset == 0 {
+		// 전체 sync 의 첫 page 를 가져온 시각, 전체 sync 를 마친 뒤 이때부터 바뀐 항목을 가져온다
+		next.Since = result.Since
+	}
+	if len(result.Items) < pocketPageSize {
+		next.InitialOffset = -1
+	} else {
+		next.InitialOffset += len(result.Items)
+	}
+	return &next
+}
+
+func (s *pocketSyncService) applyItems(items []*pocket.Item) error {
+	failed := 0
+	for _, item := range items {
+		if err := s.apply(item); err != nil {
+			logrus.Errorf("failed to sync pocket item (%s): %s", item.ItemID, err.Error())
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d pocket items", failed)
+	}
+	return nil
+}
+
+// apply 는 처음 보는 항목은 queue 에 넣고, 이미 가져온 항목은 바뀐 상태를 article 에 반영한다.
+func (s *pocketSyncService) apply(item *pocket.Item) error {
+	known, err := s.pocketItemRepository.GetByItemID(item.ItemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		known = nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get pocket item")
+	}
+
+	if known == nil {
+		if item.Status == pocket.StatusDeleted || item.URL() == "" {
+			return nil
+		}
+
+		job := models.NewIngestionJob(item.URL(), s.tagsOf(item), models.IngestionSourcePocket)
+		job.FallbackTitle = item.Title()
+		job.ArticleCreated = item.TimeAdded
+		job.Favorite = item.Favorite
+		job.Archived = item.Status == pocket.StatusArchived
+		job, err = s.ingestionService.EnqueueJob(job)
+		if err != nil {
+			return errors.Wrap(err, "failed to enqueue")
+		}
+		known = &models.PocketItem{ItemID: item.ItemID, URL: item.URL(), Status: item.Status, Favorite: item.Favorite, JobID: &job.ID}
+	} else if known.Status != item.Status || known.Favorite != item.Favorite {
+		known.Status = item.Status
+		known.Favorite = item.Favorite
+		if known.Pending, err = s.updateArticle(known); err != nil {
+			return err
+		}
+	}
+
+	if err := s.pocketItemRepository.Save(known); err != nil {
+		return errors.Wrap(err, "failed to save pocket item")
+	}
+	return nil
+}
+
+// applyPending 은 가져오는 중이라 반영하지 못했던 상태를 반영한다.
+func (s *pocketSyncService) applyPending() error {
+	items, err := s.pocketItemRepository.FindPending()
+	if err != nil {
+		return errors.Wrap(err, "failed to find pending pocket items")
+	}
+
+	for _, known := range items {
+		if pending, err := s.updateArticle(known); err != nil {
+			logrus.Errorf("failed to sync pocket item (%s): %s", known.ItemID, err.Error())
+		} else if !pending {
+			known.Pending = false
+			if err := s.pocketItemRepository.Save(known); err != nil {
+				logrus.Errorf("failed to save pocket item (%s): %s", known.ItemID, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// updateArticle 은 바뀐 상태를 article 에 반영하고, job 이 실행 중이라 반영하지 못했으면 true 를 돌려준다.
+func (s *pocketSyncService) updateArticle(known *models.PocketItem) (bool, error) {
+	job, err := s.findJob(known)
+	if err != nil {
+		return false, err
 	}
-	lastOffset, err := s.pocketService.GetLastOffset()
+	article, err := s.findArticle(known, job)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get last offset")
+		return false, err
+	} else if article == nil {
+		return s.updateJob(known, job)
+	}
+
+	archived, favorite := pocketStateOf(known, article.Favorite)
+	if article.Archived == archived && article.Favorite == favorite {
+		return false, nil
+	}
+
+	article.Archived = archived
+	article.Favorite = favorite
+	if err := s.articleRepository.Save(article); err != nil {
+		return false, errors.Wrap(err, "failed to save article")
 	}
+	return false, nil
+}
 
-	urls, err := pocket.Retrieve(consumerKey, accessToken, lastOffset, 30)
+func (s *pocketSyncService) updateJob(known *models.PocketItem, job *models.IngestionJob) (bool, error) {
+	if job == nil || (job.State != models.IngestionJobQueued && job.State != models.IngestionJobRunning) {
+		// 지워진 article 이거나 가져오지 못한 항목
+		return false, nil
+	} else if job.State == models.IngestionJobRunning {
+		return true, nil
+	}
+
+	job.Archived, job.Favorite = pocketStateOf(known, job.Favorite)
+	ok, err := s.ingestionJobRepository.UpdateQueued(job)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve urls")
+		return false, errors.Wrap(err, "failed to save ingestion job")
 	}
+	// 그 사이 실행되기 시작했으면 끝난 뒤 다시 반영한다
+	return !ok, nil
+}
 
-	if err := s.pocketService.SetLastOffset(lastOffset + len(urls)); err != nil {
-		return nil, errors.Wrap(err, "failed to set last offset")
+// pocketStateOf 는 항목에 맞는 보관, 즐겨찾기 여부로, 지운 항목은 article 을 지우지 않고 favorite 을 그대로 둔다.
+func pocketStateOf(known *models.PocketItem, favorite bool) (bool, bool) {
+	if known.Status != pocket.StatusDeleted {
+		favorite = known.Favorite
 	}
+	return known.Status != pocket.StatusUnread, favorite
+}
+
+// tagsOf 는 "pocket" 과 설정에 따라 이름을 바꾼 pocket 의 tag 들이다.
+func (s *pocketSyncService) tagsOf(item *pocket.Item) []string {
+	tags := common.Strings{"pocket"}
+	for _, tag := range item.Tags {
+		if mapped, ok := s.tagMapping[tag]; ok {
+			tag = mapped
+		} else {
+			tag = s.tagPrefix + tag
+		}
+
+		if tag == "" || tags.Contain(tag) {
+			continue
+		} else if err := models.ValidateTags([]string{tag}); err != nil {
+			logrus.Warnf("skip pocket tag (%s): %s", tag, err.Error())
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
 
-	return urls, nil
+func (s *pocketSyncService) findJob(known *models.PocketItem) (*models.IngestionJob, error) {
+	if known.JobID == nil {
+		return nil, nil
+	}
+
+	job, err := s.ingestionJobRepository.GetByID(*known.JobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get ingestion job")
+	}
+	return job, nil
+}
+
+// findArticle 은 job 이 만든 article, job 이 없으면 url 로 찾은 article 로, 아직 가져오는 중이면 nil 이다.
+func (s *pocketSyncService) findArticle(known *models.PocketItem, job *models.IngestionJob) (*models.Article, error) {
+	if job != nil && job.ArticleID != nil {
+		return articleOrNil(s.articleRepository.GetByID(*job.ArticleID))
+	} else if job != nil && (job.State == models.IngestionJobQueued || job.State == models.IngestionJobRunning) {
+		return nil, nil
+	}
+
+	canonicalURL, err := canonical.Normalize(known.URL)
+	if err != nil {
+		return nil, nil
+	}
+	return articleOrNil(s.articleRepository.GetByCanonicalURL(canonicalURL))
+}
+
+func articleOrNil(article *models.Article, err error) (*models.Article, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get article")
+	}
+	return article, nil
 }
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"github.com/jaeyo/personal-archive/common/pocket"
+	"github.com/jaeyo/personal-archive/models"
+	"github.com/jaeyo/personal-archive/repositories/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextPocketSyncCursor(t *testing.T) {
+	page := &pocket.RetrieveResult{Since: 100}
+	for i := 0; i < pocketPageSize; i++ {
+		page.Items = append(page.Items, &pocket.Item{})
+	}
+
+	// case 1: 전체 sync 의 첫 page 의 since 를 남겨둔다
+	cursor := nextPocketSyncCursor(&PocketSyncCursor{Since: 0, InitialOffset: 0}, page)
+	require.Equal(t, PocketSyncCursor{Since: 100, InitialOffset: pocketPageSize}, *cursor)
+
+	// case 2: 전체 sync 의 마지막 page
+	cursor = nextPocketSyncCursor(cursor, &pocket.RetrieveResult{Since: 200, Items: page.Items[:3]})
+	require.Equal(t, PocketSyncCursor{Since: 100, InitialOffset: -1}, *cursor)
+	require.False(t, cursor.IsInitial())
+
+	// case 3: 바뀐 항목만 가져온다
+	cursor = nextPocketSyncCursor(cursor, &pocket.RetrieveResult{Since: 300})
+	require.Equal(t, PocketSyncCursor{Since: 300, InitialOffset: -1}, *cursor)
+}
+
+func TestApplyPocketItem(t *testing.T) {
+	items := map[string]*models.PocketItem{}
+	var enqueued []*models.IngestionJob
+	article := &models.Article{ID: 7, URL: "https://example.com/a"}
+	articleID := article.ID
+	pendingJob := &models.IngestionJob{ID: 2, State: models.IngestionJobQueued}
+	svc := &pocketSyncService{
+		pocketItemRepository: &mock.PocketItemRepositoryMock{
+			OnGetByItemID: func(itemID string) (*models.PocketItem, error) {
+				if item, ok := items[itemID]; ok {
+					return item, nil
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+			OnSave: func(item *models.PocketItem) error {
+				items[item.ItemID] = item
+				return nil
+			},
+			OnFindPending: func() (models.PocketItems, error) {
+				var pending models.PocketItems
+				for _, item := range items {
+					if item.Pending {
+						pending = append(pending, item)
+					}
+				}
+				return pending, nil
+			},
+		},
+		ingestionService: &ingestionService{
+			ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+				OnGetActiveByURL: func(url string) (*models.IngestionJob, error) { return nil, gorm.ErrRecordNotFound },
+				OnSave: func(job *models.IngestionJob) error {
+					job.ID = int64(len(enqueued) + 1)
+					enqueued = append(enqueued, job)
+					return nil
+				},
+			},
+		},
+		ingestionJobRepository: &mock.IngestionJobRepositoryMock{
+			OnGetByID: func(id int64) (*models.IngestionJob, error) {
+				if id == 2 {
+					return pendingJob, nil
+				}
+				return &models.IngestionJob{ID: id, State: models.IngestionJobSucceeded, ArticleID: &articleID}, nil
+			},
+			OnUpdateQueued: func(job *models.IngestionJob) (bool, error) {
+				return job.State == models.IngestionJobQueued, nil
+			},
+		},
+		articleRepository: &mock.ArticleRepositoryMock{
+			OnGetByID: func(id int64) (*models.Article, error) { return article, nil },
+			OnSave:    func(article *models.Article) error { return nil },
+		},
+		tagPrefix:  "p/",
+		tagMapping: map[string]string{"golang": "go", "later": ""},
+	}
+
+	// case 1: 처음 보는 항목은 queue 에 넣는다, 보관된 항목은 보관된 article 이 된다
+	added := time.Unix(1616000000, 0)
+	require.NoError(t, svc.apply(&pocket.Item{
+		ItemID:      "1",
+		ResolvedURL: "https://example.com/a",
+		Status:      pocket.StatusUnread,
+		GivenTitle:  "Given",
+		Tags:        []string{"golang", "later", "web", "pocket", strings.Repeat("x", 40)},
+		Favorite:    true,
+		TimeAdded:   &added,
+	}))
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "2", GivenURL: "https://example.com/b", Status: pocket.StatusArchived}))
+	require.Len(t, enqueued, 2)
+	require.Equal(t, models.StringList{"pocket", "go", "p/web", "p/pocket"}, enqueued[0].Tags)
+	require.Equal(t, "Given", enqueued[0].FallbackTitle)
+	require.Equal(t, "", enqueued[0].Title)
+	require.Equal(t, added, *enqueued[0].ArticleCreated)
+	require.True(t, enqueued[0].Favorite)
+	require.False(t, enqueued[0].Archived)
+	require.True(t, enqueued[1].Archived)
+	require.Equal(t, models.StringList{"pocket"}, enqueued[1].Tags)
+	require.Equal(t, int64(1), *items["1"].JobID)
+
+	// case 2: 처음 보는 항목이 지워진 경우
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "3", Status: pocket.StatusDeleted}))
+	require.Len(t, enqueued, 2)
+	require.NotContains(t, items, "3")
+
+	// case 3: 보관하거나 지우면 article 을 보관하고, 다시 꺼내면 되돌린다
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "1", ResolvedURL: "https://example.com/a", Status: pocket.StatusArchived, Favorite: true}))
+	require.True(t, article.Archived)
+	require.True(t, article.Favorite)
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "1", ResolvedURL: "https://example.com/a", Status: pocket.StatusUnread}))
+	require.False(t, article.Archived)
+	require.False(t, article.Favorite)
+
+	// case 4: 지운 항목은 즐겨찾기를 그대로 둔다
+	article.Favorite = true
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "1", Status: pocket.StatusDeleted}))
+	require.True(t, article.Archived)
+	require.True(t, article.Favorite)
+	require.Equal(t, pocket.StatusDeleted, items["1"].Status)
+	require.Len(t, enqueued, 2)
+
+	// case 5: 아직 대기 중인 job 은 job 을 바꾼다
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "2", GivenURL: "https://example.com/b", Status: pocket.StatusUnread, Favorite: true}))
+	require.False(t, pendingJob.Archived)
+	require.True(t, pendingJob.Favorite)
+	require.False(t, items["2"].Pending)
+
+	// case 6: 실행 중인 job 이 끝난 뒤 반영한다
+	pendingJob.State = models.IngestionJobRunning
+	require.NoError(t, svc.apply(&pocket.Item{ItemID: "2", GivenURL: "https://example.com/b", Status: pocket.StatusArchived, Favorite: true}))
+	require.True(t, items["2"].Pending)
+	require.Equal(t, pocket.StatusArchived, items["2"].Status)
+
+	article.Archived, article.Favorite = false, false
+	pendingJob.State, pendingJob.ArticleID = models.IngestionJobSucceeded, &articleID
+	require.NoError(t, svc.applyPending())
+	require.True(t, article.Archived)
+	require.True(t, article.Favorite)
+	require.False(t, items["2"].Pending)
+
+	// case 7: 반영하지 못한 항목이 있으면 cursor 를 옮기지 않도록 실패를 돌려준다
+	svc.ingestionService.(*ingestionService).ingestionJobRepository.(*mock.IngestionJobRepositoryMock).OnSave = func(job *models.IngestionJob) error {
+		return gorm.ErrInvalidTransaction
+	}
+	err := svc.applyItems([]*pocket.Item{
+		{ItemID: "4", GivenURL: "https://example.com/d", Status: pocket.StatusUnread},
+		{ItemID: "5", GivenURL: "https://example.com/e", Status: pocket.StatusUnread},
+	})
+	require.EqualError(t, err, "failed to sync 2 pocket items")
+	require.NotContains(t, items, "4")
+}
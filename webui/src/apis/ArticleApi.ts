@@ -1,10 +1,21 @@
 import Article from "../models/Article"
 import { requestDelete, requestGet, requestPost, requestPut } from "./index"
 import { Pagination } from "../common/Types"
+import IngestionJob from "../models/IngestionJob"
+import ArticleRevision from "../models/ArticleRevision"
 
-export const requestCreateArticleByURL = async (url: string, tags: string[]): Promise<Article> => {
+// article 은 바로 만들어지지 않고 ingestion job 으로 queue 에 들어간다
+export const requestCreateArticleByURL = async (url: string, tags: string[]): Promise<IngestionJob> => {
   const resp = await requestPost(`/apis/articles`, {url, tags})
-  return new Article(resp.data.article)
+  return resp.data.job
+}
+
+export const requestCreateArticlesByFile = async (files: File[], tags: string[], splitChapters: boolean): Promise<Article[]> => {
+  const form = new FormData()
+  files.forEach(file => form.append('file', file))
+  const split = splitChapters ? '&split=chapter' : ''
+  const resp = await requestPost(`/apis/articles/files?tags=${encodeURIComponent(tags.join(','))}${split}`, form)
+  return resp.data.articles.map((article: any) => new Article(article))
 }
 
 export const requestGetArticle = async (id: number): Promise<Article> => {
@@ -24,6 +35,31 @@ export const requestUpdateContent = async (id: number, content: string): Promise
   await requestPut(`/apis/articles/${id}/content`, {content})
 }
 
+export const requestRefetchArticle = async (id: number): Promise<Article> => {
+  const resp = await requestPost(`/apis/articles/${id}/refetch`, {})
+  return new Article(resp.data.article)
+}
+
+export const requestFindRevisions = async (id: number): Promise<ArticleRevision[]> => {
+  const resp = await requestGet(`/apis/articles/${id}/revisions`)
+  return resp.data.revisions
+}
+
+export const requestGetRevision = async (id: number, revisionID: number): Promise<ArticleRevision> => {
+  const resp = await requestGet(`/apis/articles/${id}/revisions/${revisionID}`)
+  return resp.data.revision
+}
+
+export const requestDiffRevisions = async (id: number, from: number, to: number): Promise<string> => {
+  const resp = await requestGet(`/apis/articles/${id}/revisions/diff?from=${from}&to=${to}`)
+  return resp.data.diff
+}
+
+export const requestRestoreRevision = async (id: number, revisionID: number): Promise<Article> => {
+  const resp = await requestPost(`/apis/articles/${id}/revisions/${revisionID}/restore`, {})
+  return new Article(resp.data.article)
+}
+
 export const requestFindArticlesByTag = async (tag: string, page: number): Promise<[Article[], Pagination]> => {
   const resp = await requestGet(`/apis/articles/tags/${encodeURIComponent(tag)}?page=${page}`)
   return [
@@ -0,0 +1,63 @@
+import { requestPost } from "./index"
+
+// 노트를 가져올 때는 url 대신 path 가 채워진다
+export interface ImportResult {
+  url?: string
+  path?: string
+  outcome: string
+  jobId?: number
+  articleId?: number
+  noteId?: number
+  error?: string
+}
+
+export interface ImportReport {
+  total: number
+  queued: number
+  created: number
+  skipped: number
+  failed: number
+  results: ImportResult[]
+}
+
+// file 은 한 줄에 url 하나인 text, url/tags/title 컬럼의 csv, 혹은 json 배열
+export const requestImportURLs = async (file: File, tags: string[]): Promise<ImportReport> => {
+  const form = new FormData()
+  form.append('file', file)
+  const resp = await requestPost(`/apis/imports/urls?tags=${encodeURIComponent(tags.join(','))}`, form)
+  return resp.data.report
+}
+
+// fetch 가 아니면 본문 없이 제목과 url 만 가진 article 이 만들어진다
+export const requestImportBookmarks = async (file: File, tags: string[], fetch: boolean): Promise<ImportReport> => {
+  const form = new FormData()
+  form.append('file', file)
+  const resp = await requestPost(`/apis/imports/bookmarks?fetch=${fetch}&tags=${encodeURIComponent(tags.join(','))}`, form)
+  return resp.data.report
+}
+
+export type ImportSource = 'pocket' | 'instapaper' | 'wallabag' | 'raindrop' | 'omnivore' | 'readwise'
+
+// 다른 서비스의 내보내기 파일, 본문이 들어있는 항목은 fetch 와 관계없이 내려받지 않는다
+export const requestImportExport = async (source: ImportSource, file: File, tags: string[], fetch: boolean): Promise<ImportReport> => {
+  const form = new FormData()
+  form.append('file', file)
+  const resp = await requestPost(`/apis/imports/${source}?fetch=${fetch}&tags=${encodeURIComponent(tags.join(','))}`, form)
+  return resp.data.report
+}
+
+// file 은 Obsidian, Logseq vault 혹은 markdown 파일 폴더를 압축한 zip
+export const requestImportNotes = async (file: File, tags: string[]): Promise<ImportReport> => {
+  const form = new FormData()
+  form.append('file', file)
+  const resp = await requestPost(`/apis/imports/notes?tags=${encodeURIComponent(tags.join(','))}`, form)
+  return resp.data.report
+}
+
+// file 은 Kindle 기기의 My Clippings.txt 혹은 Kindle 앱에서 html 로 내보낸 notebook, article 이면 책마다 article 을 만들어 노트와 잇는다
+export const requestImportKindle = async (file: File, tags: string[], article: boolean): Promise<ImportReport> => {
+  const form = new FormData()
+  form.append('file', file)
+  const resp = await requestPost(`/apis/imports/kindle?article=${article}&tags=${encodeURIComponent(tags.join(','))}`, form)
+  return resp.data.report
+}
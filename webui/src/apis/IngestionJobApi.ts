@@ -0,0 +1,28 @@
+import IngestionJob from "../models/IngestionJob"
+import { requestGet, requestPost } from "./index"
+import { Pagination } from "../common/Types"
+
+export const requestEnqueueURLs = async (urls: string[], tags: string[]): Promise<IngestionJob[]> => {
+  const resp = await requestPost(`/apis/ingestion-jobs`, {urls, tags})
+  return resp.data.jobs
+}
+
+export const requestFindIngestionJobs = async (state: string, page: number): Promise<[IngestionJob[], Pagination]> => {
+  const resp = await requestGet(`/apis/ingestion-jobs?state=${encodeURIComponent(state)}&page=${page}`)
+  return [resp.data.jobs, resp.data.pagination]
+}
+
+export const requestGetIngestionJob = async (id: number): Promise<IngestionJob> => {
+  const resp = await requestGet(`/apis/ingestion-jobs/${id}`)
+  return resp.data.job
+}
+
+export const requestRetryIngestionJob = async (id: number): Promise<IngestionJob> => {
+  const resp = await requestPost(`/apis/ingestion-jobs/${id}/retry`, {})
+  return resp.data.job
+}
+
+export const requestCancelIngestionJob = async (id: number): Promise<IngestionJob> => {
+  const resp = await requestPost(`/apis/ingestion-jobs/${id}/cancel`, {})
+  return resp.data.job
+}
@@ -0,0 +1,31 @@
+import Article from "../models/Article"
+import LinkCheck from "../models/LinkCheck"
+import { requestGet, requestPost } from "./index"
+import { Pagination } from "../common/Types"
+
+export interface LinkReport {
+  counts: { [status: string]: number }
+  unchecked: number
+  articles: Article[]
+  pagination: Pagination
+}
+
+export const requestGetLinkReport = async (page: number): Promise<LinkReport> => {
+  const resp = await requestGet(`/apis/link-checks/report?page=${page}`)
+  return {
+    counts: resp.data.counts,
+    unchecked: resp.data.unchecked,
+    articles: resp.data.articles.map((article: any) => new Article(article)),
+    pagination: resp.data.pagination,
+  }
+}
+
+export const requestFindLinkChecks = async (articleID: number): Promise<LinkCheck[]> => {
+  const resp = await requestGet(`/apis/articles/${articleID}/link-checks`)
+  return resp.data.checks
+}
+
+export const requestCheckLink = async (articleID: number): Promise<LinkCheck> => {
+  const resp = await requestPost(`/apis/articles/${articleID}/link-checks`, {})
+  return resp.data.check
+}
@@ -6,15 +6,47 @@ export const Kind = {
   Tweet: 'tweet',
   SlideShare: 'slideshare',
   Youtube: 'youtube',
+  PDF: 'pdf',
+  Embed: 'embed',
+  Email: 'email',
+}
+
+export const LinkStatus = {
+  Alive: 'alive',
+  Redirected: 'redirected',
+  Gone: 'gone',
+  Changed: 'changed',
+  Error: 'error',
+}
+
+export interface ArticleEmbed {
+  provider: string
+  src: string
+  width: number
+  height: number
 }
 
 export default class Article {
   id: number
   kind: string
   url: string
+  canonicalUrl: string | null
   content: string
   title: string
   tags: ArticleTag[]
+  extractor: string
+  author: string
+  published: Date | null
+  siteName: string
+  excerpt: string
+  leadImage: string
+  language: string
+  wordCount: number
+  embed: ArticleEmbed | null
+  linkStatus: string
+  linkChecked: Date | null
+  favorite: boolean
+  archived: boolean
   created: Date
   lastModified: Date
   readingTime: string
@@ -23,11 +55,25 @@ export default class Article {
     this.id = obj.id
     this.kind = obj.kind
     this.url = obj.url
+    this.canonicalUrl = obj.canonicalUrl
     this.content = obj.content
     this.title = obj.title
     this.tags = obj.tags
+    this.extractor = obj.extractor
+    this.author = obj.author
+    this.published = obj.published ? new Date(obj.published) : null
+    this.siteName = obj.siteName
+    this.excerpt = obj.excerpt
+    this.leadImage = obj.leadImage
+    this.language = obj.language
+    this.wordCount = obj.wordCount
+    this.embed = obj.embed && obj.embed.src ? obj.embed : null
+    this.linkStatus = obj.linkStatus
+    this.linkChecked = obj.linkChecked ? new Date(obj.linkChecked) : null
+    this.favorite = obj.favorite
+    this.archived = obj.archived
     this.created = new Date(obj.created)
     this.lastModified = new Date(obj.lastModified)
-    this.readingTime = readingTime(obj.content).text
+    this.readingTime = obj.readingTime > 0 ? `${obj.readingTime} min read` : readingTime(obj.content).text
   }
 }
@@ -0,0 +1,20 @@
+export const ArticleRevisionSource = {
+  Fetch: 'fetch',
+  Initial: 'initial',
+  UserEdit: 'user-edit',
+  Refetch: 'refetch',
+  ReExtract: 're-extract',
+  Import: 'import',
+  Email: 'email',
+  Upload: 'upload',
+  Restore: 'restore',
+}
+
+export default interface ArticleRevision {
+  id: number
+  articleId: number
+  title: string
+  content?: string
+  source: string
+  created: string
+}
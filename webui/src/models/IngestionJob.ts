@@ -0,0 +1,26 @@
+export const IngestionJobState = {
+  Queued: 'queued',
+  Running: 'running',
+  Succeeded: 'succeeded',
+  Failed: 'failed',
+  Canceled: 'canceled',
+}
+
+export default interface IngestionJob {
+  id: number
+  url: string
+  tags: string[]
+  title: string
+  fallbackTitle: string
+  articleCreated: string | null
+  favorite: boolean
+  archived: boolean
+  source: string
+  state: string
+  attempts: number
+  nextAttempt: string
+  lastError: string
+  articleId: number | null
+  created: string
+  lastModified: string
+}
@@ -0,0 +1,9 @@
+export default interface LinkCheck {
+  id: number
+  articleId: number
+  status: string
+  statusCode: number
+  finalUrl: string
+  error: string
+  created: string
+}
@@ -5,6 +5,7 @@ export default interface Note {
   id: number
   title: string
   paragraphs: Paragraph[]
+  tags: string[]
   created: string
   lastModified: string
 }
@@ -1,7 +1,7 @@
 import React, { FC } from "react"
 import Article, { Kind } from "../../models/Article"
 import ArticleContentTweet from "./ArticleContentTweet"
-import ArticleContentSlideShare from "./ArticleContentSlideShare"
+import ArticleContentEmbed from "./ArticleContentEmbed"
 import ArticleContentYoutube from "./ArticleContentYoutube"
 import { useHistory } from "react-router-dom"
 import { Button } from "rsuite"
@@ -17,8 +17,8 @@ const ArticleContent: FC<Props> = ({article}) => {
 
   return article.kind === Kind.Tweet ?
     <ArticleContentTweet article={article}/> :
-    article.kind === Kind.SlideShare ?
-      <ArticleContentSlideShare article={article}/> :
+    article.kind === Kind.Embed || article.kind === Kind.SlideShare ?
+      <ArticleContentEmbed article={article}/> :
       article.kind === Kind.Youtube ?
         <ArticleContentYoutube article={article}/> :
         <>
@@ -0,0 +1,56 @@
+import React, { FC } from "react"
+import styled from "styled-components"
+import Article from "../../models/Article"
+import MarkdownContent from "../../component/common/MarkdownContent"
+
+
+interface Props {
+  article: Article
+}
+
+// provider 의 html 은 렌더링하지 않고, 서버에서 검증된 iframe 주소만 sandbox 안에 띄운다
+const ArticleContentEmbed: FC<Props> = ({ article }) => {
+  const { embed } = article
+  if (!embed) {
+    return <Wrapper><a href={article.url} target="_blank" rel="noopener noreferrer">{article.url}</a></Wrapper>
+  }
+
+  const ratio = embed.width > 0 && embed.height > 0 ? embed.height / embed.width : 9 / 16
+
+  return (
+    <>
+      <Wrapper>
+        <Frame style={{ paddingTop: `${ratio * 100}%` }}>
+          <iframe
+            src={embed.src}
+            title={`${embed.provider}: ${article.title}`}
+            sandbox="allow-scripts allow-same-origin allow-popups allow-presentation"
+            allow="autoplay; fullscreen; encrypted-media; picture-in-picture"
+            allowFullScreen
+            frameBorder={0}
+          />
+        </Frame>
+      </Wrapper>
+      <MarkdownContent content={article.content}/>
+    </>
+  )
+}
+
+const Wrapper = styled.div`
+  margin: 15px 20px;
+`
+
+const Frame = styled.div`
+  position: relative;
+  max-width: 800px;
+
+  iframe {
+    position: absolute;
+    top: 0;
+    left: 0;
+    width: 100%;
+    height: 100%;
+  }
+`
+
+export default ArticleContentEmbed
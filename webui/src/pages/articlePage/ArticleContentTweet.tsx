@@ -2,6 +2,7 @@ import React, { FC, useState } from "react"
 import Article from "../../models/Article"
 import { Tweet } from "react-twitter-widgets"
 import { Loader } from "rsuite"
+import MarkdownContent from "../../component/common/MarkdownContent"
 
 
 interface Props {
@@ -9,13 +10,23 @@ interface Props {
 }
 
 const ArticleContentTweet: FC<Props> = ({article}) => {
+  // 예전에 저장된 article 은 content 에 tweet id 만 있다
+  if (/^[0-9]+$/.test(article.content)) {
+    return <LegacyTweet tweetId={article.content}/>
+  }
+
+  // tweet 이 지워져도 볼 수 있도록 보관된 본문을 보여준다
+  return <MarkdownContent content={article.content}/>
+}
+
+const LegacyTweet: FC<{ tweetId: string }> = ({tweetId}) => {
   const [ isFetching, setFetching ] = useState(true)
 
   return (
     <>
       { isFetching ? <Loader /> : null }
       <Tweet
-        tweetId={article.content}
+        tweetId={tweetId}
         onLoad={() => setFetching(false)}
       />
     </>
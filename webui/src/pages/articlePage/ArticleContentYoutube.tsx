@@ -1,21 +1,53 @@
 import React, { FC } from "react"
 import YouTube from "react-youtube"
 import Article from "../../models/Article"
+import MarkdownContent from "../../component/common/MarkdownContent"
 
 
 interface Props {
   article: Article
 }
 
-const ArticleContentYoutube: FC<Props> = ({ article }) => (
-  <YouTube
-    videoId={article.content}
-    opts={{
-      playerVars: {
-        autoplay: 1,
-      }
-    }}
-  />
-)
+const ArticleContentYoutube: FC<Props> = ({ article }) => {
+  const { videoId, playlistId } = parseYoutubeUrl(article.url)
+
+  // 예전에 저장된 article 은 content 에 video id 만 있다
+  const isLegacy = /^[A-Za-z0-9_-]{11}$/.test(article.content)
+
+  return (
+    <>
+      <YouTube
+        videoId={isLegacy ? article.content : videoId}
+        opts={{
+          playerVars: {
+            autoplay: 1,
+            ...(playlistId ? { listType: 'playlist', list: playlistId } : {}),
+          }
+        }}
+      />
+      { isLegacy ? null : <MarkdownContent content={article.content}/> }
+    </>
+  )
+}
+
+const parseYoutubeUrl = (url: string): { videoId?: string, playlistId?: string } => {
+  try {
+    const u = new URL(url)
+    const host = u.hostname.replace(/^www\./, '')
+    const segments = u.pathname.split('/').filter(s => s.length > 0)
+
+    if (host === 'youtu.be') {
+      return { videoId: segments[0] }
+    } else if (u.pathname === '/watch') {
+      return { videoId: u.searchParams.get('v') || undefined }
+    } else if (segments.length === 2 && ['shorts', 'embed', 'live', 'v'].includes(segments[0])) {
+      return { videoId: segments[1] }
+    } else if (u.pathname === '/playlist') {
+      return { playlistId: u.searchParams.get('list') || undefined }
+    }
+  } catch (e) {
+  }
+  return {}
+}
 
 export default ArticleContentYoutube
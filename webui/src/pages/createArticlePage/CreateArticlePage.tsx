@@ -4,7 +4,9 @@ import { useRecoilValue } from "recoil"
 import ArticleTagTreeLayout from "../../component/layout/ArticleTagTreeLayout"
 import { articleTagsState } from "../../states/ArticleTags"
 import { requestCreateArticleByURL } from "../../apis/ArticleApi"
+import { requestGetIngestionJob } from "../../apis/IngestionJobApi"
 import { toTagPickerItemTypes } from "../../common/Types"
+import IngestionJob, { IngestionJobState } from "../../models/IngestionJob"
 
 
 const CreateArticlePage: FC = () => {
@@ -62,8 +64,19 @@ const useSubmit = (): [boolean, (url: string, selectedTags: string[]) => void] =
   const submit = (url: string, selectedTags: string[]) => {
     setFetching(true)
     requestCreateArticleByURL(url, selectedTags)
-      .then(article => {
-        window.location.href = `/articles/${article.id}`
+      .then(waitForJob)
+      .then(job => {
+        if (job.state === IngestionJobState.Succeeded) {
+          window.location.href = `/articles/${job.articleId}`
+          return
+        }
+
+        if (job.state === IngestionJobState.Queued) {
+          Alert.warning(`failed to fetch, will retry later: ${job.lastError}`)
+        } else {
+          Alert.error(`${job.state}: ${job.lastError}`)
+        }
+        setFetching(false)
       })
       .catch(err => {
         Alert.error(err.toString())
@@ -74,4 +87,13 @@ const useSubmit = (): [boolean, (url: string, selectedTags: string[]) => void] =
   return [fetching, submit]
 }
 
+// job 이 끝나거나 한 번 실패할 때까지 기다린다
+const waitForJob = async (job: IngestionJob): Promise<IngestionJob> => {
+  while (job.state === IngestionJobState.Running || (job.state === IngestionJobState.Queued && job.attempts === 0)) {
+    await new Promise(resolve => setTimeout(resolve, 1000))
+    job = await requestGetIngestionJob(job.id)
+  }
+  return job
+}
+
 export default CreateArticlePage
\ No newline at end of file